@@ -0,0 +1,108 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"slices"
+
+	"github.com/arafato/ali-nuke/config"
+	"github.com/arafato/ali-nuke/types"
+	"github.com/arafato/ali-nuke/utils"
+)
+
+// target is an account ali-nuke runs against
+type target struct {
+	identity *utils.AccountIdentity
+	creds    *types.Credentials
+	cfg      *config.Config // Configuration with the account overrides applied
+	regions  []string       // Regions to scan; all active regions of cfg if empty
+}
+
+// resolveTargets returns the accounts to nuke. Without a multi-account section this is the
+// account of the credentials, otherwise the listed accounts and the members of the folder,
+// each accessed by assuming the configured role. Every account is checked against
+// account-blocklist before it is used, and folder members must also be listed in accounts
+// unless allow-all-folder-accounts is set.
+func resolveTargets(ctx context.Context, out io.Writer, cfg *config.Config, creds *types.Credentials) ([]target, error) {
+	if !cfg.MultiAccount.Enabled() {
+		identity, err := utils.GetCallerIdentity(ctx, creds, cfg.Settings.GlobalRegion)
+		if err != nil {
+			return nil, err
+		}
+		if err := cfg.CheckAccount(identity.AccountID, false); err != nil {
+			return nil, err
+		}
+		creds.AccountID = identity.AccountID
+		return []target{{identity: identity, creds: creds, cfg: cfg.ForAccount(identity.AccountID)}}, nil
+	}
+
+	accountIDs := slices.Clone(cfg.MultiAccount.Accounts)
+	if cfg.MultiAccount.FolderID != "" {
+		folderAccounts, err := utils.ListFolderAccounts(ctx, creds, cfg.MultiAccount.FolderID)
+		if err != nil {
+			return nil, err
+		}
+		fmt.Fprintf(out, "Found %d accounts in folder %s\n", len(folderAccounts), cfg.MultiAccount.FolderID)
+		accountIDs = append(accountIDs, folderAccounts...)
+	}
+	slices.Sort(accountIDs)
+	accountIDs = slices.Compact(accountIDs)
+
+	var targets []target
+	for _, accountID := range accountIDs {
+		// Check before assuming the role, so that blocked accounts are never accessed. An
+		// account moved into the folder must not be nuked without being listed somewhere.
+		selected := slices.Contains(cfg.MultiAccount.Accounts, accountID) || cfg.MultiAccount.AllowAllFolderAccounts
+		if err := cfg.CheckAccount(accountID, selected); err != nil {
+			if !selected {
+				return nil, fmt.Errorf("folder %s: %w; list it or set multi-account.allow-all-folder-accounts", cfg.MultiAccount.FolderID, err)
+			}
+			return nil, err
+		}
+
+		accountCreds, err := utils.AssumeAccountRole(creds, accountID,
+			cfg.MultiAccount.RoleArn(accountID), cfg.MultiAccount.RoleSessionName)
+		if err != nil {
+			return nil, fmt.Errorf("account %s: %w", accountID, err)
+		}
+		identity, err := utils.GetCallerIdentity(ctx, accountCreds, cfg.Settings.GlobalRegion)
+		if err != nil {
+			return nil, fmt.Errorf("account %s: %w", accountID, err)
+		}
+		if identity.AccountID != accountID {
+			return nil, fmt.Errorf("role %s belongs to account %s", cfg.MultiAccount.RoleArn(accountID), identity.AccountID)
+		}
+		targets = append(targets, target{identity: identity, creds: accountCreds, cfg: cfg.ForAccount(accountID)})
+	}
+	return targets, nil
+}
+
+// confirmTargets asks for the alias (or the ID if the alias is unknown) of every account
+// and reports whether all of them were typed correctly
+func confirmTargets(ctx context.Context, out io.Writer, targets []target) bool {
+	for _, t := range targets {
+		expected := t.identity.Alias
+		if expected == "" {
+			expected = t.identity.AccountID
+		}
+		fmt.Fprintf(out, "Type %q to confirm the deletion in account %s: ", expected, t.identity)
+		if readConfirmation(ctx) != expected {
+			return false
+		}
+	}
+	return true
+}
+
+// printAccountSummary prints the resource counts of every account
+func printAccountSummary(out io.Writer, targets []target, resources types.Resources) {
+	for _, t := range targets {
+		accountResources := resources.ByAccount(t.identity.AccountID)
+		fmt.Fprintf(out, "  Account %s: To be removed %d, Deleted %d, Failed %d, Filtered %d\n",
+			t.identity,
+			accountResources.NumOf(types.Ready)+accountResources.NumOf(types.PendingRetry),
+			accountResources.NumOf(types.Deleted),
+			accountResources.NumOf(types.Failed),
+			accountResources.NumOf(types.Filtered))
+	}
+}
@@ -0,0 +1,182 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+
+	"github.com/arafato/ali-nuke/config"
+	"github.com/arafato/ali-nuke/infrastructure"
+	"github.com/arafato/ali-nuke/types"
+	"github.com/arafato/ali-nuke/utils"
+	"github.com/arafato/ali-nuke/version"
+)
+
+var applyCmd = &cobra.Command{
+	Use:   "apply",
+	Short: "Delete exactly the resources of a saved plan",
+	Long: `Apply deletes the resources of a plan written by nuke --plan-out. The resources are
+looked up again first; resources that no longer exist are skipped and resources that are
+not part of the plan are never touched.`,
+
+	PreRunE: func(cmd *cobra.Command, args []string) error {
+		return validateCredentialFlags()
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		// Errors past this point are not caused by the command line
+		cmd.SilenceUsage = true
+		return executeApply(cmd)
+	},
+}
+
+func executeApply(cmd *cobra.Command) error {
+	startedAt := time.Now()
+
+	var out io.Writer = os.Stdout
+	if !isTerminal(out) {
+		color.NoColor = true
+	}
+
+	plan, err := utils.LoadPlan(planFile)
+	if err != nil {
+		return fmt.Errorf("loading plan: %w", err)
+	}
+	fmt.Fprintf(out, "Plan: %d resources, created %s with ali-nuke %s\n",
+		len(plan.Resources), plan.CreatedAt.Local().Format("2006-01-02 15:04:05"), plan.Version)
+	if current := version.GetVersion(); plan.Version != current {
+		fmt.Fprintf(out, "Warning: the plan was created with ali-nuke %s, this is %s.\n", plan.Version, current)
+	}
+	if len(plan.Resources) == 0 {
+		fmt.Fprintln(out, "Nothing to do.")
+		return nil
+	}
+
+	// Use the configuration the plan was created with, so that the same accounts, roles and
+	// settings apply
+	cfg, err := validateConfig([]byte(plan.Config), nil)
+	if err != nil {
+		return fmt.Errorf("loading configuration of the plan: %w", err)
+	}
+
+	targets, err := prepareRun(out, cmd, cfg)
+	if err != nil {
+		return err
+	}
+	if targets, err = planTargets(plan, targets); err != nil {
+		return err
+	}
+
+	ctx, stop := notifyContext()
+	defer stop()
+
+	var resources types.Resources
+	stopTelemetry, err := startTelemetry(ctx)
+	if err != nil {
+		return err
+	}
+	defer func() { stopTelemetry(resources) }()
+	defer func() { notifyRun(cfg, "apply", false, ctx.Err() != nil, targets, resources, startedAt) }()
+
+	// Look the planned resources up again: anything that is not in the plan is hidden and
+	// planned resources that were not found are reported as gone
+	fmt.Fprintln(out, "Verifying the planned resources...")
+	if resources, err = lookupPlannedResources(ctx, out, plan, targets); err != nil {
+		return err
+	}
+	if ctx.Err() != nil {
+		fmt.Fprintln(out, "Apply operation aborted.")
+		return nil
+	}
+
+	printLogSummary(out)
+
+	if resources.NumOf(types.Ready) == 0 {
+		fmt.Fprintln(out, "None of the planned resources exist any more, nothing to do.")
+		return nil
+	}
+
+	infrastructure.BuildDeletionPlan(resources).Print(out)
+
+	fmt.Fprintf(out, "Deleting %d planned resources... do you really want to continue?\n", resources.NumOf(types.Ready))
+	if !confirmTargets(ctx, out, targets) {
+		fmt.Fprintln(out, "Apply operation aborted.")
+		return nil
+	}
+	fmt.Fprintln(out, "Apply operation confirmed.")
+
+	return removeResources(ctx, out, targets, resources, cfg.Settings)
+}
+
+// planTargets restricts the targets to the accounts of the plan. It fails if an account of
+// the plan cannot be accessed any more.
+func planTargets(plan *utils.Plan, targets []target) ([]target, error) {
+	var result []target
+	for _, account := range plan.Accounts() {
+		i := slices.IndexFunc(targets, func(t target) bool { return t.identity.AccountID == account })
+		if i < 0 {
+			return nil, fmt.Errorf("refusing to run: account %s of the plan is not a target of its configuration", account)
+		}
+
+		t := targets[i]
+		// Only the regions and resource types of the plan need to be scanned
+		t.regions = scanRegionsOf(plan.Regions(account))
+		cfg := *t.cfg
+		cfg.ResourceTypes = config.IncludeExclude{Includes: plan.ResourceTypes(account)}
+		t.cfg = &cfg
+		result = append(result, t)
+	}
+	return result, nil
+}
+
+// scanRegionsOf returns the regions to scan for resources of the given regions. Global
+// resource types are collected regardless of the scanned regions.
+func scanRegionsOf(regions []string) []string {
+	var result []string
+	for _, region := range regions {
+		if region == infrastructure.GlobalRegion {
+			continue
+		}
+		if !slices.Contains(result, region) {
+			result = append(result, region)
+		}
+	}
+	return result
+}
+
+// lookupPlannedResources scans the targets and marks the resources of the plan as Ready and
+// everything else as Hidden
+func lookupPlannedResources(ctx context.Context, out io.Writer, plan *utils.Plan, targets []target) (types.Resources, error) {
+	var resources types.Resources
+	for _, t := range targets {
+		if ctx.Err() != nil {
+			break
+		}
+		accountResources, err := infrastructure.ProcessCollection(ctx, t.creds, t.regions, t.cfg)
+		if err != nil {
+			return nil, fmt.Errorf("scanning account %s: %w", t.identity, err)
+		}
+		resources = append(resources, accountResources...)
+	}
+
+	found := 0
+	for _, resource := range resources {
+		if plan.Contains(resource) {
+			resource.SetState(types.Ready)
+			found++
+		} else {
+			resource.SetState(types.Hidden)
+		}
+	}
+
+	if missing := len(plan.Resources) - found; missing > 0 {
+		fmt.Fprintf(out, "%d planned resources no longer exist and are skipped.\n", missing)
+	}
+	utils.PrettyPrintStatus(out, resources)
+	return resources, nil
+}
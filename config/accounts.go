@@ -0,0 +1,95 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+)
+
+// DefaultAccountRoleName is the role Resource Directory creates in every member account
+const DefaultAccountRoleName = "ResourceDirectoryAccountAccessRole"
+
+var accountIDPattern = regexp.MustCompile(`^\d+$`)
+
+// MultiAccount lists the accounts to nuke in addition to (or instead of) the account of the credentials
+type MultiAccount struct {
+	Accounts        []string `yaml:"accounts"`          // Target account IDs
+	FolderID        string   `yaml:"folder-id"`         // Resource Directory folder whose member accounts are targeted
+	RoleName        string   `yaml:"role-name"`         // Role assumed in every target account
+	RoleSessionName string   `yaml:"role-session-name"` // Session name used when assuming the role
+
+	// AllowAllFolderAccounts targets every member of the folder. Otherwise members must be
+	// listed in accounts or in the accounts of this section.
+	AllowAllFolderAccounts bool `yaml:"allow-all-folder-accounts"`
+}
+
+// Enabled reports whether target accounts are configured
+func (m MultiAccount) Enabled() bool {
+	return len(m.Accounts) > 0 || m.FolderID != ""
+}
+
+// RoleArn returns the ARN of the role to assume in an account
+func (m MultiAccount) RoleArn(accountID string) string {
+	roleName := m.RoleName
+	if roleName == "" {
+		roleName = DefaultAccountRoleName
+	}
+	return fmt.Sprintf("acs:ram::%s:role/%s", accountID, roleName)
+}
+
+func (m MultiAccount) validate() error {
+	var errs []error
+	for _, account := range m.Accounts {
+		if !accountIDPattern.MatchString(account) {
+			errs = append(errs, fmt.Errorf("multi-account: invalid account ID %q", account))
+		}
+	}
+	if m.AllowAllFolderAccounts && m.FolderID == "" {
+		errs = append(errs, fmt.Errorf("multi-account: allow-all-folder-accounts requires folder-id"))
+	}
+	return errors.Join(errs...)
+}
+
+// AccountOverride replaces filter sections of the configuration for a single account.
+// Sections that are not set keep the global value.
+type AccountOverride struct {
+	Regions       *IncludeExclude     `yaml:"regions"`
+	ResourceTypes *IncludeExclude     `yaml:"resource-types"`
+	ResourceIDs   *ResourceIDExcludes `yaml:"resource-ids"`
+	ResourceTags  *IncludeExclude     `yaml:"resource-tags"`
+}
+
+func (o AccountOverride) validate() error {
+	var ids ResourceIDExcludes
+	if o.ResourceIDs != nil {
+		ids = *o.ResourceIDs
+	}
+	var tags IncludeExclude
+	if o.ResourceTags != nil {
+		tags = *o.ResourceTags
+	}
+	return validateFilters(ids, tags)
+}
+
+// ForAccount returns the configuration with the overrides of the account applied
+func (c *Config) ForAccount(accountID string) *Config {
+	override, ok := c.AccountOverrides[accountID]
+	if !ok {
+		return c
+	}
+
+	cfg := *c
+	if override.Regions != nil {
+		cfg.Regions = *override.Regions
+	}
+	if override.ResourceTypes != nil {
+		cfg.ResourceTypes = *override.ResourceTypes
+	}
+	if override.ResourceIDs != nil {
+		cfg.ResourceIDs = *override.ResourceIDs
+	}
+	if override.ResourceTags != nil {
+		cfg.ResourceTags = *override.ResourceTags
+	}
+	return &cfg
+}
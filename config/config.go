@@ -1,29 +1,68 @@
 package config
 
 import (
+	"errors"
 	"fmt"
+	"maps"
 	"os"
-
-	"gopkg.in/yaml.v2"
+	"regexp"
+	"slices"
+	"time"
 )
 
 type Config struct {
-	Regions struct {
-		Excludes []string `yaml:"excludes"`
-	} `yaml:"regions"`
+	// Accounts lists the IDs of the accounts ali-nuke may run against. It must contain the
+	// account the credentials belong to.
+	Accounts []string `yaml:"accounts"`
+
+	// AccountBlocklist lists the IDs of accounts that must never be nuked, e.g. production
+	AccountBlocklist []string `yaml:"account-blocklist"`
+
+	// MultiAccount selects further accounts to nuke by assuming a role into each of them
+	MultiAccount MultiAccount `yaml:"multi-account"`
+
+	// AccountOverrides replaces filter sections for single accounts, keyed by account ID
+	AccountOverrides map[string]AccountOverride `yaml:"account-overrides"`
+
+	Regions       IncludeExclude     `yaml:"regions"`
+	ResourceTypes IncludeExclude     `yaml:"resource-types"`
+	ResourceIDs   ResourceIDExcludes `yaml:"resource-ids"`
+	ResourceTags  IncludeExclude     `yaml:"resource-tags"`
 
-	ResourceTypes struct {
-		Excludes []string `yaml:"excludes"`
-	} `yaml:"resource-types"`
+	Settings Settings `yaml:"settings"`
+
+	// RateLimits overrides the requests per second per product ("ecs") or product and region ("ecs/cn-hangzhou")
+	RateLimits map[string]float64 `yaml:"rate-limits"`
+
+	// Notifications lists the webhooks the summary of a run is posted to
+	Notifications []Notification `yaml:"notifications"`
+}
+
+// IncludeExclude selects values by an include and an exclude list
+type IncludeExclude struct {
+	Includes []string `yaml:"includes"`
+	Excludes []string `yaml:"excludes"`
+}
 
-	ResourceIDs struct {
-		Excludes []ResourceIDFilter `yaml:"excludes"`
-	} `yaml:"resource-ids"`
+// ResourceIDExcludes holds the resource-ids section
+type ResourceIDExcludes struct {
+	Excludes []ResourceIDFilter `yaml:"excludes"`
 }
 
+// ResourceIDFilter excludes resources of a type whose property matches a value.
+// The short form only sets ID, which is matched exactly against the resource ID or name.
 type ResourceIDFilter struct {
-	ResourceType string `yaml:"resourceType"`
-	ID           string `yaml:"id"`
+	ResourceType string `yaml:"resourceType"` // Resource type, or "*" for all types
+	ID           string `yaml:"id"`           // Shorthand for Value
+	Property     string `yaml:"property"`     // name, id, region, tag:<key> or any resource property; empty matches ID or name
+	Type         string `yaml:"type"`         // exact (default), glob, regex, contains, dateOlderThan, dateNewerThan, greaterThan, lessThan
+	Value        string `yaml:"value"`
+	Invert       bool   `yaml:"invert"` // Exclude resources that do NOT match
+
+	// Parsed from Value by compile, depending on Type
+	regex     *regexp.Regexp
+	threshold time.Time
+	limit     float64
 }
 
 func LoadConfig(path string) (*Config, error) {
@@ -31,32 +70,127 @@ func LoadConfig(path string) (*Config, error) {
 	if err != nil {
 		return nil, fmt.Errorf("error reading config: %w", err)
 	}
+	return ParseConfig(yamlFile)
+}
 
-	var config Config
-	err = yaml.Unmarshal(yamlFile, &config)
-	if err != nil {
-		return nil, fmt.Errorf("error unmarshaling YAML: %w", err)
+// ParseConfig strictly parses and validates the YAML content of a configuration file. Resource
+// types and regions are not checked, see Validate.
+func ParseConfig(data []byte) (*Config, error) {
+	return Validate(data, ValidateOptions{})
+}
+
+// validate checks the parts of the configuration that YAML unmarshaling cannot. All
+// problems are reported, joined into one error.
+func (c *Config) validate() error {
+	errs := []error{
+		c.Settings.Validate(),
+		validateFilters(c.ResourceIDs, c.ResourceTags),
+		c.MultiAccount.validate(),
+	}
+	for _, key := range slices.Sorted(maps.Keys(c.RateLimits)) {
+		if c.RateLimits[key] <= 0 {
+			errs = append(errs, fmt.Errorf("rate-limits: %s must be greater than 0", key))
+		}
+	}
+	for _, account := range c.Accounts {
+		if slices.Contains(c.AccountBlocklist, account) {
+			errs = append(errs, fmt.Errorf("account %s is listed in both accounts and account-blocklist", account))
+		}
+	}
+	for _, account := range slices.Sorted(maps.Keys(c.AccountOverrides)) {
+		if err := c.AccountOverrides[account].validate(); err != nil {
+			errs = append(errs, prefixErrors(fmt.Sprintf("account-overrides: %s", account), err))
+		}
+	}
+	for i, notification := range c.Notifications {
+		if err := notification.validate(); err != nil {
+			errs = append(errs, fmt.Errorf("notifications[%d]: %w", i, err))
+		}
 	}
+	return errors.Join(errs...)
+}
+
+// validateFilters checks the resource-ids and resource-tags rules and compiles the
+// resource-ids filters
+func validateFilters(ids ResourceIDExcludes, tags IncludeExclude) error {
+	var errs []error
+	for _, rule := range slices.Concat(tags.Includes, tags.Excludes) {
+		if _, err := ParseTagRule(rule); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	// Compile in place, the filters share the backing array of the caller
+	for i := range ids.Excludes {
+		if err := ids.Excludes[i].compile(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
 
-	return &config, nil
+// prefixErrors prefixes every error joined in err
+func prefixErrors(prefix string, err error) error {
+	var errs []error
+	for _, e := range splitErrors(err) {
+		errs = append(errs, fmt.Errorf("%s: %w", prefix, e))
+	}
+	return errors.Join(errs...)
+}
+
+// splitErrors returns the errors joined in err, or err itself if it is a single error
+func splitErrors(err error) []error {
+	if err == nil {
+		return nil
+	}
+	if joined, ok := err.(interface{ Unwrap() []error }); ok {
+		var errs []error
+		for _, e := range joined.Unwrap() {
+			errs = append(errs, splitErrors(e)...)
+		}
+		return errs
+	}
+	return []error{err}
+}
+
+// CheckAccount returns an error unless the account is listed in accounts and not in
+// account-blocklist. Accounts explicitly selected by the multi-account section, and folder
+// members if allow-all-folder-accounts is set, count as listed.
+func (c *Config) CheckAccount(accountID string, selected bool) error {
+	if slices.Contains(c.AccountBlocklist, accountID) {
+		return fmt.Errorf("account %s is listed in account-blocklist", accountID)
+	}
+	if selected {
+		return nil
+	}
+	if len(c.Accounts) == 0 {
+		return fmt.Errorf("no accounts configured, add account %s to the accounts section of the config file", accountID)
+	}
+	if !slices.Contains(c.Accounts, accountID) {
+		return fmt.Errorf("account %s is not listed in the accounts section of the config file", accountID)
+	}
+	return nil
+}
+
+// IncludesResourceType reports whether a resource type is selected by resource-types.includes.
+// Without includes every type is selected.
+func (c *Config) IncludesResourceType(resourceType string) bool {
+	if len(c.ResourceTypes.Includes) == 0 {
+		return true
+	}
+	return slices.Contains(c.ResourceTypes.Includes, resourceType)
 }
 
 func NewConfig() Config {
 	return Config{
-		Regions: struct {
-			Excludes []string `yaml:"excludes"`
-		}{
-			Excludes: []string{},
-		},
-		ResourceTypes: struct {
-			Excludes []string `yaml:"excludes"`
-		}{
-			Excludes: []string{},
-		},
-		ResourceIDs: struct {
-			Excludes []ResourceIDFilter `yaml:"excludes"`
-		}{
-			Excludes: []ResourceIDFilter{},
-		},
+		Accounts:         []string{},
+		AccountBlocklist: []string{},
+		AccountOverrides: map[string]AccountOverride{},
+		Regions:          IncludeExclude{Includes: []string{}, Excludes: []string{}},
+		ResourceTypes:    IncludeExclude{Includes: []string{}, Excludes: []string{}},
+		ResourceIDs:      ResourceIDExcludes{Excludes: []ResourceIDFilter{}},
+		ResourceTags:     IncludeExclude{Includes: []string{}, Excludes: []string{}},
+		Settings:         DefaultSettings(),
+		RateLimits:       map[string]float64{},
+		Notifications:    []Notification{},
 	}
 }
@@ -0,0 +1,158 @@
+package config
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Filter types supported by ResourceIDFilter
+const (
+	FilterExact         = "exact"
+	FilterGlob          = "glob"
+	FilterRegex         = "regex"
+	FilterContains      = "contains"
+	FilterDateOlderThan = "dateOlderThan"
+	FilterDateNewerThan = "dateNewerThan"
+	FilterGreaterThan   = "greaterThan"
+	FilterLessThan      = "lessThan"
+)
+
+// timeLayouts lists the timestamp formats returned by the various Alibaba Cloud APIs
+var timeLayouts = []string{
+	time.RFC3339,
+	"2006-01-02T15:04Z07:00",
+	"2006-01-02T15:04:05Z0700",
+	"2006-01-02 15:04:05",
+	"2006-01-02",
+}
+
+// FilterType returns the filter type, defaulting to exact
+func (f ResourceIDFilter) FilterType() string {
+	if f.Type == "" {
+		return FilterExact
+	}
+	return f.Type
+}
+
+// MatchValue returns the value the filter compares against, falling back to ID
+func (f ResourceIDFilter) MatchValue() string {
+	if f.Value != "" {
+		return f.Value
+	}
+	return f.ID
+}
+
+// Matches reports whether a property value satisfies the filter. Invert is not applied.
+// Regular expressions, dates and numbers are parsed once by compile when the configuration
+// is loaded.
+func (f ResourceIDFilter) Matches(value string) bool {
+	expected := f.MatchValue()
+
+	switch f.FilterType() {
+	case FilterExact:
+		return value == expected
+	case FilterGlob:
+		matched, _ := path.Match(expected, value)
+		return matched
+	case FilterRegex:
+		return f.regex != nil && f.regex.MatchString(value)
+	case FilterContains:
+		return strings.Contains(value, expected)
+	case FilterDateOlderThan, FilterDateNewerThan:
+		t, err := parseTime(value)
+		if err != nil {
+			return false
+		}
+		if f.FilterType() == FilterDateOlderThan {
+			return t.Before(f.threshold)
+		}
+		return t.After(f.threshold)
+	case FilterGreaterThan, FilterLessThan:
+		actual, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return false
+		}
+		if f.FilterType() == FilterGreaterThan {
+			return actual > f.limit
+		}
+		return actual < f.limit
+	}
+	return false
+}
+
+// String describes the filter for display, e.g. `Name glob "test-*"`
+func (f ResourceIDFilter) String() string {
+	property := f.Property
+	if property == "" {
+		property = "id/name"
+	}
+	s := fmt.Sprintf("%s %s %q", property, f.FilterType(), f.MatchValue())
+	if f.Invert {
+		s = "not " + s
+	}
+	return s
+}
+
+// compile checks the filter type and parses the value for it. Ages are resolved relative to
+// the time of the call.
+func (f *ResourceIDFilter) compile() error {
+	if f.ResourceType == "" {
+		return fmt.Errorf("resource-ids filter %s: missing resourceType", f)
+	}
+	if f.MatchValue() == "" {
+		return fmt.Errorf("resource-ids filter for %s: missing value", f.ResourceType)
+	}
+
+	var err error
+	switch f.FilterType() {
+	case FilterExact, FilterContains:
+	case FilterGlob:
+		_, err = path.Match(f.MatchValue(), "")
+	case FilterRegex:
+		f.regex, err = regexp.Compile(f.MatchValue())
+	case FilterDateOlderThan, FilterDateNewerThan:
+		f.threshold, err = parseDateThreshold(f.MatchValue(), time.Now())
+	case FilterGreaterThan, FilterLessThan:
+		f.limit, err = strconv.ParseFloat(f.MatchValue(), 64)
+	default:
+		err = fmt.Errorf("unknown filter type %q", f.Type)
+	}
+	if err != nil {
+		return fmt.Errorf("resource-ids filter for %s: %w", f.ResourceType, err)
+	}
+	return nil
+}
+
+// parseTime parses a timestamp in any of the formats used by Alibaba Cloud APIs
+func parseTime(value string) (time.Time, error) {
+	for _, layout := range timeLayouts {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("unrecognized time format %q", value)
+}
+
+// parseDateThreshold parses either an absolute date or an age relative to now.
+// Ages are Go durations ("36h") or whole days ("30d").
+func parseDateThreshold(value string, now time.Time) (time.Time, error) {
+	if t, err := parseTime(value); err == nil {
+		return t, nil
+	}
+	if days, ok := strings.CutSuffix(value, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("invalid age %q", value)
+		}
+		return now.AddDate(0, 0, -n), nil
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid date or age %q", value)
+	}
+	return now.Add(-d), nil
+}
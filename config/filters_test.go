@@ -0,0 +1,102 @@
+package config
+
+import (
+	"testing"
+	"time"
+)
+
+func TestResourceIDFilterMatches(t *testing.T) {
+	now := time.Now().UTC()
+	daysAgo := func(days int) string { return now.AddDate(0, 0, -days).Format(time.RFC3339) }
+
+	tests := []struct {
+		name   string
+		filter ResourceIDFilter
+		value  string
+		want   bool
+	}{
+		{"exact", ResourceIDFilter{ID: "vpc-1"}, "vpc-1", true},
+		{"exact other", ResourceIDFilter{ID: "vpc-1"}, "vpc-12", false},
+
+		{"glob", ResourceIDFilter{Type: FilterGlob, Value: "test-*"}, "test-vpc", true},
+		{"glob single character", ResourceIDFilter{Type: FilterGlob, Value: "vpc-?"}, "vpc-12", false},
+		{"glob no match", ResourceIDFilter{Type: FilterGlob, Value: "test-*"}, "prod-vpc", false},
+
+		{"regex", ResourceIDFilter{Type: FilterRegex, Value: `^i-[0-9a-z]{4}$`}, "i-ab12", true},
+		{"regex unanchored", ResourceIDFilter{Type: FilterRegex, Value: `prod`}, "vpc-prod-1", true},
+		{"regex no match", ResourceIDFilter{Type: FilterRegex, Value: `^i-[0-9a-z]{4}$`}, "i-ab123", false},
+
+		{"contains", ResourceIDFilter{Type: FilterContains, Value: "prod"}, "vpc-prod-1", true},
+
+		{"older than age", ResourceIDFilter{Type: FilterDateOlderThan, Value: "30d"}, daysAgo(31), true},
+		{"older than age, newer", ResourceIDFilter{Type: FilterDateOlderThan, Value: "30d"}, daysAgo(29), false},
+		{"older than duration", ResourceIDFilter{Type: FilterDateOlderThan, Value: "36h"}, daysAgo(2), true},
+		{"older than date", ResourceIDFilter{Type: FilterDateOlderThan, Value: "2025-01-01"}, "2024-12-31T23:00Z", true},
+		{"older than date, other layout", ResourceIDFilter{Type: FilterDateOlderThan, Value: "2025-01-01"}, "2024-12-31 08:00:00", true},
+		{"newer than age", ResourceIDFilter{Type: FilterDateNewerThan, Value: "7d"}, daysAgo(1), true},
+		{"newer than age, older", ResourceIDFilter{Type: FilterDateNewerThan, Value: "7d"}, daysAgo(8), false},
+		{"date unparsable", ResourceIDFilter{Type: FilterDateNewerThan, Value: "7d"}, "yesterday", false},
+
+		{"greater than", ResourceIDFilter{Type: FilterGreaterThan, Value: "100"}, "100.5", true},
+		{"greater than, equal", ResourceIDFilter{Type: FilterGreaterThan, Value: "100"}, "100", false},
+		{"less than", ResourceIDFilter{Type: FilterLessThan, Value: "1.5"}, "1", true},
+		{"less than, greater", ResourceIDFilter{Type: FilterLessThan, Value: "1.5"}, "2", false},
+		{"number unparsable", ResourceIDFilter{Type: FilterLessThan, Value: "1.5"}, "small", false},
+
+		// Invert is applied by the caller, Matches ignores it
+		{"invert", ResourceIDFilter{ID: "vpc-1", Invert: true}, "vpc-1", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.filter.ResourceType = "VPC"
+			if err := tt.filter.compile(); err != nil {
+				t.Fatalf("compile: %v", err)
+			}
+			if got := tt.filter.Matches(tt.value); got != tt.want {
+				t.Errorf("%s matches %q = %v, want %v", tt.filter, tt.value, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestResourceIDFilterUncompiledRegex(t *testing.T) {
+	// Filters are compiled when the configuration is loaded. One that was not never matches,
+	// rather than matching everything.
+	filter := ResourceIDFilter{ResourceType: "VPC", Type: FilterRegex, Value: ".*"}
+	if filter.Matches("vpc-1") {
+		t.Error("uncompiled regex filter matched")
+	}
+}
+
+func TestResourceIDFilterCompileErrors(t *testing.T) {
+	for _, filter := range []ResourceIDFilter{
+		{ID: "vpc-1"},
+		{ResourceType: "VPC"},
+		{ResourceType: "VPC", Type: FilterGlob, Value: "[a-"},
+		{ResourceType: "VPC", Type: FilterRegex, Value: "(unclosed"},
+		{ResourceType: "VPC", Type: FilterDateOlderThan, Value: "a month"},
+		{ResourceType: "VPC", Type: FilterDateOlderThan, Value: "xd"},
+		{ResourceType: "VPC", Type: FilterGreaterThan, Value: "ten"},
+		{ResourceType: "VPC", Type: "startsWith", Value: "vpc"},
+	} {
+		if err := filter.compile(); err == nil {
+			t.Errorf("%s: compiled, want an error", filter)
+		}
+	}
+}
+
+func TestResourceIDFilterString(t *testing.T) {
+	tests := []struct {
+		filter ResourceIDFilter
+		want   string
+	}{
+		{ResourceIDFilter{ID: "vpc-1"}, `id/name exact "vpc-1"`},
+		{ResourceIDFilter{Property: "Name", Type: FilterGlob, Value: "test-*"}, `Name glob "test-*"`},
+		{ResourceIDFilter{Property: "CreationTime", Type: FilterDateOlderThan, Value: "30d", Invert: true}, `not CreationTime dateOlderThan "30d"`},
+	}
+	for _, tt := range tests {
+		if got := tt.filter.String(); got != tt.want {
+			t.Errorf("String() = %s, want %s", got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,74 @@
+package config
+
+import (
+	"fmt"
+	"net/url"
+	"slices"
+	"time"
+)
+
+// Webhook formats of the notifications section
+const (
+	NotificationJSON     = "json"
+	NotificationSlack    = "slack"
+	NotificationDingTalk = "dingtalk"
+)
+
+const (
+	DefaultNotificationTimeout  = 10 * time.Second
+	DefaultNotificationAttempts = 3
+)
+
+// Notification is a webhook the summary of a run is posted to
+type Notification struct {
+	Type     string            `yaml:"type"`     // json (default), slack or dingtalk
+	URL      string            `yaml:"url"`      // Webhook URL
+	Headers  map[string]string `yaml:"headers"`  // Additional HTTP headers, e.g. Authorization
+	Secret   string            `yaml:"secret"`   // DingTalk signing secret, if the robot requires signed requests
+	Timeout  time.Duration     `yaml:"timeout"`  // Timeout of a single attempt (default 10s)
+	Attempts int               `yaml:"attempts"` // Number of attempts before giving up (default 3)
+}
+
+// Format returns the webhook format, json if none is set
+func (n Notification) Format() string {
+	if n.Type == "" {
+		return NotificationJSON
+	}
+	return n.Type
+}
+
+// AttemptTimeout returns the timeout of a single attempt
+func (n Notification) AttemptTimeout() time.Duration {
+	if n.Timeout == 0 {
+		return DefaultNotificationTimeout
+	}
+	return n.Timeout
+}
+
+// MaxAttempts returns the number of attempts before giving up
+func (n Notification) MaxAttempts() int {
+	if n.Attempts == 0 {
+		return DefaultNotificationAttempts
+	}
+	return n.Attempts
+}
+
+func (n Notification) validate() error {
+	if !slices.Contains([]string{NotificationJSON, NotificationSlack, NotificationDingTalk}, n.Format()) {
+		return fmt.Errorf("unknown type %q, use json, slack or dingtalk", n.Type)
+	}
+	u, err := url.Parse(n.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("url must be an http or https URL")
+	}
+	if n.Secret != "" && n.Format() != NotificationDingTalk {
+		return fmt.Errorf("secret is only supported by the dingtalk type")
+	}
+	if n.Timeout < 0 {
+		return fmt.Errorf("timeout must not be negative")
+	}
+	if n.Attempts < 0 {
+		return fmt.Errorf("attempts must not be negative")
+	}
+	return nil
+}
@@ -0,0 +1,58 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Settings tunes the scan and deletion behaviour
+type Settings struct {
+	MaxWaves          int           `yaml:"max-waves"`          // Max number of waves across all dependency layers
+	WaveInterval      time.Duration `yaml:"wave-interval"`      // Time between waves
+	VerifyInterval    time.Duration `yaml:"verify-interval"`    // Time between checks whether deleted resources are gone
+	MaxTotalTime      time.Duration `yaml:"max-total-time"`     // Total time budget for the deletion
+	ResourceTimeout   time.Duration `yaml:"resource-timeout"`   // Timeout for a single resource deletion, including retries and calls in flight
+	ScanConcurrency   int           `yaml:"scan-concurrency"`   // Max number of collectors running in parallel
+	DeleteConcurrency int           `yaml:"delete-concurrency"` // Max number of deletions running in parallel
+	GlobalRegion      string        `yaml:"global-region"`      // Region whose endpoints are used to collect global resource types
+}
+
+// DefaultSettings returns the settings used when neither the config file nor flags set a value
+func DefaultSettings() Settings {
+	return Settings{
+		MaxWaves:          60,
+		WaveInterval:      10 * time.Second,
+		VerifyInterval:    10 * time.Second,
+		MaxTotalTime:      10 * time.Minute,
+		ResourceTimeout:   2 * time.Minute,
+		ScanConcurrency:   20,
+		DeleteConcurrency: 20,
+		GlobalRegion:      "cn-hangzhou",
+	}
+}
+
+// Validate checks that all settings are within sensible bounds. Every setting out of bounds
+// is reported, joined into one error.
+func (s Settings) Validate() error {
+	var errs []error
+	check := func(ok bool, msg string) {
+		if !ok {
+			errs = append(errs, errors.New("settings: "+msg))
+		}
+	}
+	check(s.MaxWaves >= 1, "max-waves must be at least 1")
+	check(s.WaveInterval > 0, "wave-interval must be greater than 0")
+	check(s.VerifyInterval > 0, "verify-interval must be greater than 0")
+	check(s.MaxTotalTime > 0, "max-total-time must be greater than 0")
+	check(s.ResourceTimeout > 0, "resource-timeout must be greater than 0")
+	check(s.ScanConcurrency >= 1, "scan-concurrency must be at least 1")
+	check(s.DeleteConcurrency >= 1, "delete-concurrency must be at least 1")
+	check(s.GlobalRegion != "", "global-region must not be empty")
+	return errors.Join(errs...)
+}
+
+func (s Settings) String() string {
+	return fmt.Sprintf("max-waves=%d wave-interval=%s verify-interval=%s max-total-time=%s resource-timeout=%s scan-concurrency=%d delete-concurrency=%d global-region=%s",
+		s.MaxWaves, s.WaveInterval, s.VerifyInterval, s.MaxTotalTime, s.ResourceTimeout, s.ScanConcurrency, s.DeleteConcurrency, s.GlobalRegion)
+}
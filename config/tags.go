@@ -0,0 +1,51 @@
+package config
+
+import (
+	"fmt"
+	"path"
+	"strings"
+)
+
+// TagRule matches resource tags. It is written as "key" (tag is present) or
+// "key=value" (tag has the given value). The value may contain glob patterns
+// such as "dev-*".
+type TagRule struct {
+	Key      string
+	Value    string
+	HasValue bool
+}
+
+// ParseTagRule parses a rule of the form "key" or "key=value"
+func ParseTagRule(rule string) (TagRule, error) {
+	key, value, hasValue := strings.Cut(rule, "=")
+	key = strings.TrimSpace(key)
+	value = strings.TrimSpace(value)
+	if key == "" {
+		return TagRule{}, fmt.Errorf("invalid tag rule %q: missing tag key", rule)
+	}
+	if _, err := path.Match(value, ""); err != nil {
+		return TagRule{}, fmt.Errorf("invalid tag rule %q: %w", rule, err)
+	}
+	return TagRule{Key: key, Value: value, HasValue: hasValue}, nil
+}
+
+// Matches reports whether the tags satisfy the rule
+func (r TagRule) Matches(tags map[string]string) bool {
+	value, ok := tags[r.Key]
+	if !ok {
+		return false
+	}
+	if !r.HasValue {
+		return true
+	}
+	matched, _ := path.Match(r.Value, value)
+	return matched
+}
+
+// String returns the rule in its configuration form
+func (r TagRule) String() string {
+	if !r.HasValue {
+		return r.Key
+	}
+	return r.Key + "=" + r.Value
+}
@@ -0,0 +1,253 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	yamlv2 "gopkg.in/yaml.v2"
+	"gopkg.in/yaml.v3"
+)
+
+// GlobalRegion is the region of resources that do not belong to any region. Listing it in
+// regions.excludes filters them out, and if regions.includes is set it must list it to keep
+// them in scope.
+const GlobalRegion = "global"
+
+// Problem is an error found in a configuration file
+type Problem struct {
+	Line    int // Line of the offending key or value, 0 if unknown
+	Message string
+}
+
+func (p Problem) String() string {
+	if p.Line == 0 {
+		return p.Message
+	}
+	return fmt.Sprintf("line %d: %s", p.Line, p.Message)
+}
+
+// ValidationError lists the problems found in a configuration file
+type ValidationError struct {
+	Problems []Problem
+}
+
+func (e *ValidationError) Error() string {
+	lines := make([]string, len(e.Problems))
+	for i, p := range e.Problems {
+		lines[i] = p.String()
+	}
+	return "invalid configuration:\n  " + strings.Join(lines, "\n  ")
+}
+
+// ValidateOptions holds the names a configuration is checked against
+type ValidateOptions struct {
+	ResourceTypes []string // Canonical resource type names; not checked if nil
+	Regions       []string // Regions available to every account; not checked if nil
+
+	// AccountRegions holds the regions available to single accounts. The account-overrides
+	// of an account listed here are checked against its regions instead of Regions.
+	AccountRegions map[string][]string
+}
+
+// Validate strictly decodes a configuration file and checks it. Unknown keys, values of the
+// wrong type, invalid filters and settings, and resource types and regions missing from opts
+// are all reported in a *ValidationError, with their line where it is known.
+func Validate(data []byte, opts ValidateOptions) (*Config, error) {
+	var problems []Problem
+	config := NewConfig()
+	if err := yamlv2.UnmarshalStrict(data, &config); err != nil {
+		var typeErr *yamlv2.TypeError
+		if !errors.As(err, &typeErr) {
+			// Syntax errors leave nothing to check
+			return nil, &ValidationError{Problems: []Problem{decodeProblem(err.Error())}}
+		}
+		// The rest of the file is still decoded, so that its problems are reported as well
+		for _, msg := range typeErr.Errors {
+			problems = append(problems, decodeProblem(msg))
+		}
+	}
+
+	var root yaml.Node
+	parsed := yaml.Unmarshal(data, &root) == nil
+	for _, err := range splitErrors(config.validate()) {
+		p := Problem{Message: err.Error()}
+		if parsed {
+			p.Line = valueLine(&root, p.Message)
+		}
+		problems = append(problems, p)
+	}
+	if parsed {
+		problems = append(problems, checkNames(&root, opts)...)
+	}
+
+	if len(problems) > 0 {
+		slices.SortStableFunc(problems, func(a, b Problem) int { return a.Line - b.Line })
+		return nil, &ValidationError{Problems: problems}
+	}
+	return &config, nil
+}
+
+var (
+	lineRegexp         = regexp.MustCompile(`^(?:yaml: )?line (\d+): (.*)$`)
+	unknownFieldRegexp = regexp.MustCompile(`^field (\S+) not found in type \S+$`)
+	valueErrorRegexp   = regexp.MustCompile(`^(settings|rate-limits): (\S+) `)
+)
+
+// decodeProblem turns a yaml.v2 error message like "line 3: field foo not found in type
+// config.Config" into a problem
+func decodeProblem(msg string) Problem {
+	var p Problem
+	p.Message = strings.TrimPrefix(msg, "yaml: ")
+	if m := lineRegexp.FindStringSubmatch(msg); m != nil {
+		p.Line, _ = strconv.Atoi(m[1])
+		p.Message = m[2]
+	}
+	if m := unknownFieldRegexp.FindStringSubmatch(p.Message); m != nil {
+		p.Message = fmt.Sprintf("unknown key %q", m[1])
+	}
+	return p
+}
+
+// valueLine returns the line of the value an error of Config.validate like "settings: max-waves
+// must be at least 1" is about, 0 if the error is not about a single value
+func valueLine(root *yaml.Node, msg string) int {
+	m := valueErrorRegexp.FindStringSubmatch(msg)
+	if m == nil {
+		return 0
+	}
+	if nodes := scalarsAt(root, m[1], m[2]); len(nodes) > 0 {
+		return nodes[0].Line
+	}
+	return 0
+}
+
+// checkNames checks the resource types and regions named in the filter sections, including
+// those of account-overrides
+func checkNames(root *yaml.Node, opts ValidateOptions) []Problem {
+	var problems []Problem
+	check := func(known []string, kind string, nodes []*yaml.Node) {
+		if known == nil {
+			return
+		}
+		for _, node := range nodes {
+			if msg := unknownName(known, kind, node.Value); msg != "" {
+				problems = append(problems, Problem{Line: node.Line, Message: msg})
+			}
+		}
+	}
+
+	checkRegions := func(prefix []string, regions []string) {
+		if regions != nil {
+			regions = append(slices.Clone(regions), GlobalRegion)
+		}
+		for _, list := range []string{"includes", "excludes"} {
+			check(regions, "region", scalarsAt(root, slices.Concat(prefix, []string{"regions", list, "*"})...))
+		}
+	}
+	checkRegions(nil, opts.Regions)
+	for _, account := range mappingKeys(root, "account-overrides") {
+		regions, ok := opts.AccountRegions[account]
+		if !ok {
+			regions = opts.Regions
+		}
+		checkRegions([]string{"account-overrides", account}, regions)
+	}
+
+	for _, prefix := range [][]string{nil, {"account-overrides", "*"}} {
+		for _, list := range []string{"includes", "excludes"} {
+			check(opts.ResourceTypes, "resource type", scalarsAt(root, slices.Concat(prefix, []string{"resource-types", list, "*"})...))
+		}
+		var ids []*yaml.Node
+		for _, node := range scalarsAt(root, slices.Concat(prefix, []string{"resource-ids", "excludes", "*", "resourceType"})...) {
+			if node.Value != "*" {
+				ids = append(ids, node)
+			}
+		}
+		check(opts.ResourceTypes, "resource type", ids)
+	}
+	check(opts.Regions, "region", scalarsAt(root, "settings", "global-region"))
+	return problems
+}
+
+// unknownName returns a message if name is not in known, suggesting the correct spelling of
+// names that only differ in case
+func unknownName(known []string, kind, name string) string {
+	if slices.Contains(known, name) {
+		return ""
+	}
+	if i := slices.IndexFunc(known, func(k string) bool { return strings.EqualFold(k, name) }); i >= 0 {
+		return fmt.Sprintf("unknown %s %q, did you mean %q?", kind, name, known[i])
+	}
+	if kind == "resource type" {
+		return fmt.Sprintf("unknown resource type %q, run \"ali-nuke list\" for the supported types", name)
+	}
+	return fmt.Sprintf("unknown %s %q", kind, name)
+}
+
+// mappingKeys returns the keys of the mapping at a path of mapping keys
+func mappingKeys(node *yaml.Node, path ...string) []string {
+	switch node.Kind {
+	case yaml.DocumentNode:
+		if len(node.Content) == 0 {
+			return nil
+		}
+		return mappingKeys(node.Content[0], path...)
+	case yaml.AliasNode:
+		return mappingKeys(node.Alias, path...)
+	}
+	if node.Kind != yaml.MappingNode {
+		return nil
+	}
+
+	var keys []string
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if len(path) == 0 {
+			keys = append(keys, node.Content[i].Value)
+		} else if node.Content[i].Value == path[0] {
+			keys = append(keys, mappingKeys(node.Content[i+1], path[1:]...)...)
+		}
+	}
+	return keys
+}
+
+// scalarsAt returns the scalar nodes at a path of mapping keys, where "*" matches every
+// mapping value or sequence item
+func scalarsAt(node *yaml.Node, path ...string) []*yaml.Node {
+	switch node.Kind {
+	case yaml.DocumentNode:
+		if len(node.Content) == 0 {
+			return nil
+		}
+		return scalarsAt(node.Content[0], path...)
+	case yaml.AliasNode:
+		return scalarsAt(node.Alias, path...)
+	}
+
+	if len(path) == 0 {
+		if node.Kind == yaml.ScalarNode {
+			return []*yaml.Node{node}
+		}
+		return nil
+	}
+
+	var result []*yaml.Node
+	switch node.Kind {
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			if path[0] == "*" || node.Content[i].Value == path[0] {
+				result = append(result, scalarsAt(node.Content[i+1], path[1:]...)...)
+			}
+		}
+	case yaml.SequenceNode:
+		if path[0] == "*" {
+			for _, item := range node.Content {
+				result = append(result, scalarsAt(item, path[1:]...)...)
+			}
+		}
+	}
+	return result
+}
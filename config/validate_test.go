@@ -0,0 +1,33 @@
+package config
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestValidateValueLines(t *testing.T) {
+	data := []byte(`settings:
+  wave-interval: 1s
+  max-waves: 0
+rate-limits:
+  ecs: 5
+  vpc: 0
+`)
+	_, err := Validate(data, ValidateOptions{})
+	var validationErr *ValidationError
+	if !errors.As(err, &validationErr) {
+		t.Fatalf("Validate() error = %v, want a ValidationError", err)
+	}
+	want := []Problem{
+		{Line: 3, Message: "settings: max-waves must be at least 1"},
+		{Line: 6, Message: "rate-limits: vpc must be greater than 0"},
+	}
+	if len(validationErr.Problems) != len(want) {
+		t.Fatalf("problems = %v, want %v", validationErr.Problems, want)
+	}
+	for i, p := range validationErr.Problems {
+		if p != want[i] {
+			t.Errorf("problem %d = %v, want %v", i, p, want[i])
+		}
+	}
+}
@@ -0,0 +1,265 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v2"
+
+	"github.com/arafato/ali-nuke/config"
+	"github.com/arafato/ali-nuke/infrastructure"
+	"github.com/arafato/ali-nuke/utils"
+)
+
+var (
+	configOffline bool
+	initOutput    string
+	initForce     bool
+)
+
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "Validate or create configuration files",
+}
+
+var configValidateCmd = &cobra.Command{
+	Use:   "validate",
+	Short: "Check a configuration file without scanning anything",
+	Long: `Validate reports unknown keys, values of the wrong type, invalid filters and settings,
+and resource types and regions that do not exist, with the line they are on. Regions are
+looked up with the credentials unless --offline is set. nuke runs the same checks before
+scanning.`,
+	PreRunE: func(cmd *cobra.Command, args []string) error {
+		return validateCredentialFlags()
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		// Errors past this point are not caused by the command line
+		cmd.SilenceUsage = true
+		return executeConfigValidate(cmd.Context())
+	},
+}
+
+var configInitCmd = &cobra.Command{
+	Use:   "init",
+	Short: "Write a commented starter configuration file",
+	Long: `Init writes a configuration file for the account of the credentials. It lists every
+supported resource type and the regions of the account as commented entries to pick from.
+With --offline no credentials are needed and the accounts and regions are left empty.`,
+	PreRunE: func(cmd *cobra.Command, args []string) error {
+		return validateCredentialFlags()
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cmd.SilenceUsage = true
+		return executeConfigInit(cmd.Context())
+	},
+}
+
+func init() {
+	configCmd.AddCommand(configValidateCmd)
+	configCmd.AddCommand(configInitCmd)
+
+	configValidateCmd.Flags().StringVarP(&configFile, "config", "c", "", "Path to the configuration file to check (required)")
+	configValidateCmd.MarkFlagRequired("config")
+	configValidateCmd.Flags().BoolVar(&configOffline, "offline", false, "Do not contact Alibaba Cloud, regions are not checked")
+	addCredentialFlags(configValidateCmd)
+
+	configInitCmd.Flags().StringVarP(&initOutput, "output", "o", "config.yaml", "Path of the configuration file to write")
+	configInitCmd.Flags().BoolVar(&initForce, "force", false, "Overwrite an existing file")
+	configInitCmd.Flags().BoolVar(&configOffline, "offline", false, "Do not contact Alibaba Cloud, accounts and regions are left empty")
+	configInitCmd.Flags().StringVar(&settings.GlobalRegion, "global-region", settings.GlobalRegion, "Region whose endpoints are used to identify the account and list the regions")
+	addCredentialFlags(configInitCmd)
+}
+
+// validateConfig strictly parses a configuration file and checks its resource type names
+// against the supported types. Given the regions available to the accounts, keyed by account
+// ID, its regions are checked as well.
+func validateConfig(data []byte, accountRegions map[string][]string) (*config.Config, error) {
+	opts := config.ValidateOptions{ResourceTypes: infrastructure.ResourceTypes()}
+	if accountRegions != nil {
+		opts.AccountRegions = accountRegions
+		// The top-level regions apply to every account, so they must be available in all of them
+		for _, regions := range accountRegions {
+			if opts.Regions == nil {
+				opts.Regions = slices.Clone(regions)
+				continue
+			}
+			opts.Regions = slices.DeleteFunc(opts.Regions, func(r string) bool { return !slices.Contains(regions, r) })
+		}
+	}
+	return config.Validate(data, opts)
+}
+
+// globalRegionOf returns the global region set in a configuration file, or the default if
+// it sets none. Errors are ignored, Validate reports them.
+func globalRegionOf(data []byte) string {
+	cfg := config.NewConfig()
+	yaml.Unmarshal(data, &cfg)
+	if cfg.Settings.GlobalRegion == "" {
+		return config.DefaultSettings().GlobalRegion
+	}
+	return cfg.Settings.GlobalRegion
+}
+
+// executeConfigValidate prints the problems of the configuration file like a compiler, one
+// per line, and fails if there are any
+func executeConfigValidate(ctx context.Context) error {
+	data, err := os.ReadFile(configFile)
+	if err != nil {
+		return fmt.Errorf("loading configuration: %w", err)
+	}
+
+	var accountRegions map[string][]string
+	if !configOffline {
+		creds, err := resolveCredentials()
+		if err != nil {
+			return fmt.Errorf("resolving credentials, use --offline to skip the region check: %w", err)
+		}
+		// The credentials only know their account once it is looked up
+		globalRegion := globalRegionOf(data)
+		identity, err := utils.GetCallerIdentity(ctx, creds, globalRegion)
+		if err != nil {
+			return fmt.Errorf("identifying account, use --offline to skip the region check: %w", err)
+		}
+		regions, err := utils.FetchAllRegions(ctx, creds, globalRegion)
+		if err != nil {
+			return fmt.Errorf("fetching regions, use --offline to skip the region check: %w", err)
+		}
+		accountRegions = map[string][]string{identity.AccountID: regions}
+	}
+
+	_, err = validateConfig(data, accountRegions)
+	var validationErr *config.ValidationError
+	switch {
+	case errors.As(err, &validationErr):
+		// file:line: message, so that editors can jump to the problems
+		for _, p := range validationErr.Problems {
+			if p.Line > 0 {
+				fmt.Printf("%s:%d: %s\n", configFile, p.Line, p.Message)
+			} else {
+				fmt.Printf("%s: %s\n", configFile, p.Message)
+			}
+		}
+		return fmt.Errorf("%s has %d problems", configFile, len(validationErr.Problems))
+	case err != nil:
+		return fmt.Errorf("validating configuration: %w", err)
+	}
+
+	if accountRegions == nil {
+		fmt.Printf("%s is valid (regions not checked)\n", configFile)
+	} else {
+		fmt.Printf("%s is valid\n", configFile)
+	}
+	return nil
+}
+
+// executeConfigInit writes a starter configuration for the account of the credentials
+func executeConfigInit(ctx context.Context) error {
+	if _, err := os.Stat(initOutput); err == nil && !initForce {
+		return fmt.Errorf("%s already exists, use --force to overwrite it", initOutput)
+	}
+
+	var accountID string
+	var regions []string
+	if !configOffline {
+		creds, err := resolveCredentials()
+		if err != nil {
+			return fmt.Errorf("resolving credentials, use --offline to write a config without them: %w", err)
+		}
+		identity, err := utils.GetCallerIdentity(ctx, creds, settings.GlobalRegion)
+		if err != nil {
+			return fmt.Errorf("identifying account: %w", err)
+		}
+		accountID = identity.AccountID
+		if regions, err = utils.FetchAllRegions(ctx, creds, settings.GlobalRegion); err != nil {
+			return fmt.Errorf("fetching regions: %w", err)
+		}
+	}
+
+	data := starterConfig(accountID, settings.GlobalRegion, regions, infrastructure.Descriptors())
+	if err := os.WriteFile(initOutput, data, 0o600); err != nil {
+		return fmt.Errorf("writing configuration: %w", err)
+	}
+	fmt.Printf("Configuration written to %s, check it with: ali-nuke config validate -c %s\n", initOutput, initOutput)
+	return nil
+}
+
+// starterConfig renders a configuration that selects nothing beyond the account, with the
+// resource types and regions to choose from as comments
+func starterConfig(accountID, globalRegion string, regions []string, descriptors []infrastructure.Descriptor) []byte {
+	var b strings.Builder
+	line := func(format string, args ...any) {
+		fmt.Fprintf(&b, format+"\n", args...)
+	}
+
+	line("# Generated by ali-nuke config init. Check changes with: ali-nuke config validate -c <file>")
+	line("")
+	line("# Accounts ali-nuke may run against. The account of the credentials must be listed here.")
+	line("accounts:")
+	if accountID != "" {
+		line("  - %q", accountID)
+	} else {
+		line(`  # - "1234567890123456"`)
+	}
+	line("")
+	line("# Accounts that must never be nuked, e.g. production")
+	line("account-blocklist:")
+	line(`  # - "9876543210987654"`)
+	line("")
+
+	line("# Alibaba Cloud regions to scan (all if empty) and to exclude from scanning.")
+	line("# %q stands for global resources like CEN instances; with includes, list it to remove them.", config.GlobalRegion)
+	line("regions:")
+	line("  includes:")
+	for _, region := range regions {
+		line("    # - %s", region)
+	}
+	line("    # - %s", config.GlobalRegion)
+	line("  excludes:")
+	line("    # - %s", config.GlobalRegion)
+	line("")
+
+	width := 0
+	for _, d := range descriptors {
+		width = max(width, len(d.Name))
+	}
+	line("# Resource types to scan (all if empty) and to exclude from deletion, see ali-nuke list")
+	line("resource-types:")
+	line("  includes:")
+	line("  excludes:")
+	for _, d := range descriptors {
+		line("    # - %-*s  # %s", width, d.Name, d.Description)
+	}
+	line("")
+
+	line("# Specific resources to exclude from deletion")
+	line("resource-ids:")
+	line("  excludes:")
+	line("    # - resourceType: ECSInstance")
+	line("    #   id: i-bp1234567890abcdef")
+	line("")
+	line(`# Resources to include or exclude by tag ("key" or "key=value", values may use globs)`)
+	line("resource-tags:")
+	line("  includes:")
+	line("  excludes:")
+	line("    # - keep=true")
+	line("")
+
+	s := config.DefaultSettings()
+	s.GlobalRegion = globalRegion
+	line("# Wave, timeout and concurrency settings (command line flags take precedence)")
+	line("settings:")
+	line("  max-waves: %d", s.MaxWaves)
+	line("  wave-interval: %s", s.WaveInterval)
+	line("  verify-interval: %s", s.VerifyInterval)
+	line("  max-total-time: %s", s.MaxTotalTime)
+	line("  resource-timeout: %s", s.ResourceTimeout)
+	line("  scan-concurrency: %d", s.ScanConcurrency)
+	line("  delete-concurrency: %d", s.DeleteConcurrency)
+	line("  global-region: %s", s.GlobalRegion)
+	return []byte(b.String())
+}
@@ -0,0 +1,358 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+
+	"github.com/arafato/ali-nuke/infrastructure"
+	"github.com/arafato/ali-nuke/mockcloud"
+	"github.com/arafato/ali-nuke/types"
+	"github.com/arafato/ali-nuke/utils"
+)
+
+const (
+	mockAccountID = "1234567890123456"
+	mockAlias     = "sandbox"
+)
+
+// seedAccount fills the mock with a resource of every type, some of them in pages of several
+// items or linked to each other the way the collectors expect. It returns the number of
+// resources ali-nuke should find.
+func seedAccount(srv *mockcloud.Server) int {
+	const hz, sh = "cn-hangzhou", "cn-shanghai"
+	total := 0
+	add := func(region, kind string, fields map[string]any) {
+		srv.Add(region, kind, fields)
+		// Container Registry instances are only listed to find their repositories
+		if kind != "CRInstance" {
+			total++
+		}
+	}
+	item := func(region, kind, idField, id string, fields ...string) {
+		m := map[string]any{idField: id}
+		for i := 0; i+1 < len(fields); i += 2 {
+			m[fields[i]] = fields[i+1]
+		}
+		add(region, kind, m)
+	}
+
+	// More instances than fit on a page
+	for _, id := range []string{"i-1", "i-2", "i-3"} {
+		item(hz, "ECSInstance", "InstanceId", id, "InstanceName", id)
+	}
+	add(hz, "ECSInstance", map[string]any{
+		"InstanceId":   "i-keep",
+		"InstanceName": "keep",
+		"Tags":         map[string]any{"Tag": []any{map[string]any{"TagKey": "keep", "TagValue": "true"}}},
+	})
+	item(sh, "ECSInstance", "InstanceId", "i-sh")
+
+	item(hz, "Disk", "DiskId", "d-1", "Type", "data")
+	item(hz, "Snapshot", "SnapshotId", "s-1")
+	item(hz, "Image", "ImageId", "m-1")
+	item(hz, "SecurityGroup", "SecurityGroupId", "sg-referenced")
+	item(hz, "SecurityGroup", "SecurityGroupId", "sg-referencing")
+	item(hz, "NetworkInterface", "NetworkInterfaceId", "eni-1", "Type", "Secondary")
+	item(hz, "KeyPair", "KeyPairName", "kp-1")
+	item(hz, "LaunchTemplate", "LaunchTemplateId", "lt-1")
+	item(hz, "AutoSnapshotPolicy", "AutoSnapshotPolicyId", "sp-1")
+	item(hz, "Command", "CommandId", "c-1", "Provider", "User")
+	item(hz, "DeploymentSet", "DeploymentSetId", "ds-1")
+
+	for _, id := range []string{"vpc-1", "vpc-2", "vpc-3"} {
+		item(hz, "VPC", "VpcId", id)
+	}
+	item(sh, "VPC", "VpcId", "vpc-sh")
+	item(hz, "VSwitch", "VSwitchId", "vsw-1")
+	item(hz, "RouteTable", "RouteTableId", "vtb-1", "RouteTableType", "Custom")
+	item(hz, "RouterInterface", "RouterInterfaceId", "ri-1")
+	add(hz, "NatGateway", map[string]any{
+		"NatGatewayId":    "ngw-1",
+		"ForwardTableIds": map[string]any{"ForwardTableId": []any{"ftb-1"}},
+		"SnatTableIds":    map[string]any{"SnatTableId": []any{"stb-1"}},
+	})
+	item(hz, "ForwardEntry", "ForwardEntryId", "fwd-1", "ForwardTableId", "ftb-1")
+	item(hz, "SnatEntry", "SnatEntryId", "snat-1", "SnatTableId", "stb-1")
+	item(hz, "EIP", "AllocationId", "eip-1")
+	item(hz, "CommonBandwidthPackage", "BandwidthPackageId", "cbwp-1")
+	item(hz, "HaVip", "HaVipId", "havip-1")
+	item(hz, "VpnGateway", "VpnGatewayId", "vpn-1")
+	item(hz, "VpnConnection", "VpnConnectionId", "vco-1")
+	item(hz, "CustomerGateway", "CustomerGatewayId", "cgw-1")
+	item(hz, "SslVpnServer", "SslVpnServerId", "vss-1")
+	item(hz, "SslVpnClientCert", "SslVpnClientCertId", "vsc-1")
+
+	item(hz, "NASFileSystem", "FileSystemId", "fs-1")
+	item(hz, "NASMountTarget", "MountTargetDomain", "mt-1.cn-hangzhou.nas.aliyuncs.com", "FileSystemId", "fs-1")
+	item(hz, "ScalingGroup", "ScalingGroupId", "asg-1")
+	item(hz, "ScalingConfiguration", "ScalingConfigurationId", "asc-1")
+	item(hz, "CRInstance", "InstanceId", "cri-1")
+	item(hz, "ContainerRegistryRepo", "RepoId", "crr-1", "InstanceId", "cri-1")
+	item(hz, "ACKCluster", "cluster_id", "c-ack", "name", "ack", "state", "running")
+	item(hz, "CENInstance", "CenId", "cen-1")
+	item(hz, "TransitRouter", "TransitRouterId", "tr-1", "CenId", "cen-1")
+	item(hz, "SLB", "LoadBalancerId", "lb-1")
+	for _, id := range []string{"alb-1", "alb-2", "alb-3"} {
+		item(hz, "ALB", "LoadBalancerId", id)
+	}
+	item(hz, "NLB", "LoadBalancerId", "nlb-1")
+	item(hz, "RDSInstance", "DBInstanceId", "rm-1")
+	item(hz, "RedisInstance", "InstanceId", "r-1")
+	item(hz, "MongoDBInstance", "DBInstanceId", "dds-1")
+	item(hz, "PolarDBCluster", "DBClusterId", "pc-1")
+
+	item(sh, "OSSBucket", "Name", "bucket-1")
+	srv.AddObjects("bucket-1", "a.txt", "b/c.txt")
+	return total
+}
+
+// TestNukeOffline runs a dry run and a real run of the nuke command against the mock API
+func TestNukeOffline(t *testing.T) {
+	srv := mockcloud.New(mockcloud.Options{
+		AccountID:       mockAccountID,
+		AccountAlias:    mockAlias,
+		AccessKeyID:     "mock-access-key-id",
+		AccessKeySecret: "mock-access-key-secret",
+		Regions:         []string{"cn-hangzhou", "cn-shanghai"},
+		MaxPageSize:     2,
+	})
+	defer srv.Close()
+	utils.RedirectEndpoints(srv.Addr())
+	t.Cleanup(resetClients)
+	total := seedAccount(srv)
+
+	// The first deletion of a security group fails as if it were still referenced
+	srv.Block("sg-referenced", "DependencyViolation")
+	srv.Throttle("DescribeVpcs", 1)
+	srv.Throttle("DeleteVSwitch", 1)
+
+	// A webhook that always fails must not fail the run
+	var notified atomic.Int32
+	webhook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		notified.Add(1)
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer webhook.Close()
+
+	dir := t.TempDir()
+	configPath := filepath.Join(dir, "config.yaml")
+	reportPath := filepath.Join(dir, "report.json")
+	config := `accounts:
+  - "` + mockAccountID + `"
+resource-tags:
+  excludes:
+    - keep=true
+notifications:
+  - url: "` + webhook.URL + `"
+    attempts: 1
+`
+	if err := os.WriteFile(configPath, []byte(config), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	args := []string{"nuke", "-c", configPath,
+		"--access-key-id", "mock-access-key-id", "--access-key-secret", "mock-access-key-secret",
+		"--wave-interval", "10ms", "--verify-interval", "10ms",
+		"-o", "json", "--output-file", reportPath, "--log-level", "error"}
+
+	// The dry run reports every resource but deletes none
+	runNuke(t, args, "")
+	report := readReport(t, reportPath)
+	if report.Summary.Total != total || report.Summary.Ready != total-1 || report.Summary.Filtered != 1 {
+		t.Fatalf("dry run summary = %+v, want %d resources with 1 filtered", report.Summary, total)
+	}
+	if !srv.Exists("VPC", "vpc-1") || !srv.Exists("OSSBucket", "bucket-1") {
+		t.Fatal("dry run deleted resources")
+	}
+
+	// The real run waits for the confirmation with the account alias
+	runNuke(t, append(args, "--no-dry-run", "--run-dir", filepath.Join(dir, "run")), mockAlias+"\n")
+	report = readReport(t, reportPath)
+	if report.Summary.Deleted != total-1 || report.Summary.Failed != 0 || report.Summary.Filtered != 1 {
+		t.Errorf("summary = %+v, want %d deleted and 1 filtered", report.Summary, total-1)
+	}
+	for _, r := range report.Resources {
+		want := types.Deleted.String()
+		if r.ID == "i-keep" {
+			want = types.Filtered.String()
+		}
+		if r.State != want {
+			t.Errorf("%s %s: state %s, want %s (error %+v)", r.Product, r.ID, r.State, want, r.Error)
+		}
+	}
+
+	if ids := srv.IDs("ECSInstance"); !slices.Equal(ids, []string{"i-keep"}) {
+		t.Errorf("instances left = %v, want only i-keep", ids)
+	}
+	for _, kind := range []string{"VPC", "SecurityGroup", "OSSBucket", "CENInstance", "ACKCluster", "ALB", "NASMountTarget"} {
+		if ids := srv.IDs(kind); len(ids) > 0 {
+			t.Errorf("%s left: %v", kind, ids)
+		}
+	}
+	if n := srv.Calls("DeleteSecurityGroup"); n != 3 {
+		t.Errorf("DeleteSecurityGroup called %d times, want 3 with one dependency violation", n)
+	}
+	if n := srv.Calls("DeleteVSwitch"); n != 2 {
+		t.Errorf("DeleteVSwitch called %d times, want 2 with one throttled call", n)
+	}
+	if n := notified.Load(); n != 2 {
+		t.Errorf("webhook called %d times, want once per run", n)
+	}
+	if problems := srv.Problems(); len(problems) > 0 {
+		t.Errorf("requests rejected by the mock:\n%v", problems)
+	}
+}
+
+// TestNukeResourceTimeout checks that a delete call the API never answers is given up after
+// resource-timeout instead of the timeout of the HTTP client
+func TestNukeResourceTimeout(t *testing.T) {
+	srv := mockcloud.New(mockcloud.Options{
+		AccountID:       mockAccountID,
+		AccessKeyID:     "mock-access-key-id",
+		AccessKeySecret: "mock-access-key-secret",
+		Regions:         []string{"cn-hangzhou"},
+	})
+	defer srv.Close()
+	utils.RedirectEndpoints(srv.Addr())
+	t.Cleanup(resetClients)
+	srv.Add("cn-hangzhou", "KeyPair", map[string]any{"KeyPairName": "kp-1"})
+	srv.Stall("DeleteKeyPairs")
+
+	dir := t.TempDir()
+	configPath := filepath.Join(dir, "config.yaml")
+	reportPath := filepath.Join(dir, "report.json")
+	if err := os.WriteFile(configPath, []byte("accounts:\n  - \""+mockAccountID+"\"\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	start := time.Now()
+	err := runNukeErr(t, []string{"nuke", "-c", configPath,
+		"--access-key-id", "mock-access-key-id", "--access-key-secret", "mock-access-key-secret",
+		"--resource-timeout", "200ms", "--max-waves", "1", "--no-dry-run", "--run-dir", filepath.Join(dir, "run"),
+		"-o", "json", "--output-file", reportPath, "--log-level", "error"}, mockAccountID+"\n")
+
+	// A failed resource fails the run, so that scripts notice
+	if err == nil || err.Error() != "failed to delete 1 resources" {
+		t.Errorf("run error = %v, want failed to delete 1 resources", err)
+	}
+	if elapsed := time.Since(start); elapsed > 30*time.Second {
+		t.Errorf("run took %s, want the stalled deletion given up after 200ms", elapsed)
+	}
+	report := readReport(t, reportPath)
+	if len(report.Resources) != 1 || report.Resources[0].State != types.Failed.String() {
+		t.Errorf("resources = %+v, want kp-1 failed", report.Resources)
+	}
+	if !srv.Exists("KeyPair", "kp-1") {
+		t.Error("stalled key pair was deleted")
+	}
+}
+
+// TestConfigValidateOnline checks the regions of a configuration with the regions of the
+// account the credentials belong to
+func TestConfigValidateOnline(t *testing.T) {
+	srv := mockcloud.New(mockcloud.Options{
+		AccountID:       mockAccountID,
+		AccessKeyID:     "mock-access-key-id",
+		AccessKeySecret: "mock-access-key-secret",
+		Regions:         []string{"cn-hangzhou"},
+	})
+	defer srv.Close()
+	utils.RedirectEndpoints(srv.Addr())
+	t.Cleanup(resetClients)
+
+	configPath := filepath.Join(t.TempDir(), "config.yaml")
+	config := `accounts:
+  - "` + mockAccountID + `"
+account-overrides:
+  "` + mockAccountID + `":
+    regions:
+      includes:
+        - cn-beijing
+`
+	if err := os.WriteFile(configPath, []byte(config), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	err := runNukeErr(t, []string{"config", "validate", "-c", configPath,
+		"--access-key-id", "mock-access-key-id", "--access-key-secret", "mock-access-key-secret"}, "")
+	if want := configPath + " has 1 problems"; err == nil || err.Error() != want {
+		t.Errorf("validate error = %v, want %s", err, want)
+	}
+	if n := srv.Calls("GetCallerIdentity"); n != 1 {
+		t.Errorf("GetCallerIdentity called %d times, want the account looked up once", n)
+	}
+	if problems := srv.Problems(); len(problems) > 0 {
+		t.Errorf("requests rejected by the mock:\n%v", problems)
+	}
+}
+
+// runNuke runs the root command with args, feeding input to the confirmation prompt, and
+// expects it to succeed
+func runNuke(t *testing.T, args []string, input string) {
+	t.Helper()
+	if err := runNukeErr(t, args, input); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// runNukeErr runs the root command like runNuke and returns its error
+func runNukeErr(t *testing.T, args []string, input string) error {
+	t.Helper()
+	stdin, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	w.WriteString(input)
+	w.Close()
+	defer func(old *os.File) { os.Stdin = old }(os.Stdin)
+	os.Stdin = stdin
+
+	resetFlags()
+	rootCmd.SetArgs(args)
+	return rootCmd.Execute()
+}
+
+// resetFlags sets all flags back to their defaults, cobra keeps the values of earlier runs
+func resetFlags() {
+	reset := func(f *pflag.Flag) {
+		f.Value.Set(f.DefValue)
+		f.Changed = false
+	}
+	var visit func(cmd *cobra.Command)
+	visit = func(cmd *cobra.Command) {
+		cmd.Flags().VisitAll(reset)
+		for _, sub := range cmd.Commands() {
+			visit(sub)
+		}
+	}
+	rootCmd.PersistentFlags().VisitAll(reset)
+	visit(rootCmd)
+}
+
+// resetClients drops the state the clients keep between runs: pooled connections to the
+// closed mock would fail the next run, and the rate limiters would keep their throttled rates
+func resetClients() {
+	utils.CloseIdleConnections()
+	infrastructure.ConfigureRateLimits(nil)
+}
+
+// readReport decodes the JSON report of a run
+func readReport(t *testing.T, path string) utils.Report {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var report utils.Report
+	if err := json.Unmarshal(data, &report); err != nil {
+		t.Fatal(err)
+	}
+	return report
+}
@@ -1,128 +1,151 @@
 package infrastructure
 
 import (
+	"context"
 	"fmt"
-	"io"
-	"os"
+	"log/slog"
 	"slices"
-	"strings"
 	"time"
 
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
 	"golang.org/x/sync/errgroup"
 
+	"github.com/arafato/ali-nuke/config"
 	"github.com/arafato/ali-nuke/types"
 	"github.com/arafato/ali-nuke/utils"
 )
 
-var collectors = make(map[string]types.ResourceCollector)
-
-func RegisterCollector(name string, collector types.ResourceCollector) {
-	if _, exists := collectors[name]; exists {
-		panic(fmt.Errorf("handler %s already registered", name))
-	}
-	collectors[name] = collector
-}
-
-// isServiceUnavailableError returns true if the error indicates the service
-// is not available in the region (not a real error, just unsupported)
-func isServiceUnavailableError(err error) bool {
-	if err == nil {
-		return false
-	}
-	errStr := err.Error()
-	// "no such host" = DNS lookup failed, service endpoint doesn't exist in region
-	// "InvalidRegionId" = region not supported by the service
-	// "InvalidApi.NotFound" = API not available
-	// "UnauthorizedRegion" = region not authorized for the service
-	// "Forbidden." = permission denied for specific service features (e.g., Forbidden.HaVip)
-	// "network is unreachable" = region not accessible from current network (e.g., geo-blocked)
-	return strings.Contains(errStr, "no such host") ||
-		strings.Contains(errStr, "InvalidRegionId") ||
-		strings.Contains(errStr, "InvalidApi.NotFound") ||
-		strings.Contains(errStr, "UnauthorizedRegion") ||
-		strings.Contains(errStr, "Forbidden.") ||
-		strings.Contains(errStr, "network is unreachable")
-}
-
-// isTransientError returns true if the error is a transient network error that can be retried
-func isTransientError(err error) bool {
-	if err == nil {
-		return false
-	}
-	errStr := err.Error()
-	// EOF = connection dropped
-	// connection reset = server closed connection
-	// timeout = request timed out
-	// Note: Throttling errors are NOT retried here as they require longer backoffs
-	return err == io.EOF ||
-		strings.Contains(errStr, "EOF") ||
-		strings.Contains(errStr, "connection reset") ||
-		strings.Contains(errStr, "timeout")
-}
-
-// isThrottlingError returns true if the error is a rate limiting error
-func isThrottlingError(err error) bool {
-	if err == nil {
-		return false
-	}
-	return strings.Contains(err.Error(), "Throttling")
-}
-
-// collectWithRetry attempts to collect resources with retries for transient errors
-func collectWithRetry(collector types.ResourceCollector, creds *types.Credentials, region string, maxRetries int) (types.Resources, error) {
+// collectWithRetry attempts to collect resources with retries for transient and throttling errors.
+// The API requests of the collector are paced by the rate limiter of their product and region.
+func collectWithRetry(ctx context.Context, name string, collector types.ResourceCollector, creds *types.Credentials, region string, maxRetries int) (types.Resources, error) {
 	var lastErr error
 	for attempt := 0; attempt <= maxRetries; attempt++ {
-		resources, err := collector(creds, region)
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		resources, err := collector(ctx, creds, region)
+		category := types.ClassifyError(name, err)
 		if err == nil {
 			return resources, nil
 		}
 		lastErr = err
 
-		// Don't retry non-transient errors (including throttling, service unavailable, etc.)
-		if !isTransientError(err) {
+		// Don't retry other errors (service unavailable, permissions, etc.)
+		if (category != types.ErrorTransient && category != types.ErrorThrottled) || ctx.Err() != nil {
 			return nil, err
 		}
 
 		// Wait before retry (exponential backoff: 1s, 2s, 4s)
 		if attempt < maxRetries {
 			backoff := time.Duration(1<<attempt) * time.Second
-			time.Sleep(backoff)
+			select {
+			case <-time.After(backoff):
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			}
 		}
 	}
 	return nil, lastErr
 }
 
-// ProcessCollection collects resources from all registered collectors across all specified regions
-func ProcessCollection(creds *types.Credentials, regions []string, logger *utils.ScanLogger) types.Resources {
+// scannedRegionsKey is the context key of the regions scanned by ProcessCollection
+type scannedRegionsKey struct{}
+
+// RegionScanned reports whether resources of a global type with the given home region are
+// kept. Global collectors use it to skip further calls for resources that would be dropped.
+func RegionScanned(ctx context.Context, region string) bool {
+	regions, ok := ctx.Value(scannedRegionsKey{}).([]string)
+	return !ok || slices.Contains(regions, region)
+}
+
+// collect calls a collector in a region within a span and records its duration
+func collect(ctx context.Context, name string, collector types.ResourceCollector, creds *types.Credentials, region string) (types.Resources, error) {
+	ctx, span := utils.Tracer().Start(ctx, "collect "+name, trace.WithAttributes(
+		attribute.String(utils.LogKeyAccount, creds.AccountID),
+		attribute.String(utils.LogKeyRegion, region),
+		attribute.String(utils.LogKeyProduct, name),
+	))
+	defer span.End()
+
+	start := time.Now()
+	resources, err := collectWithRetry(ctx, name, collector, creds, region, 3)
+	utils.ObserveCollector(name, region, time.Since(start))
+
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+	span.SetAttributes(attribute.Int("count", len(resources)))
+	return resources, nil
+}
+
+// ProcessCollection collects resources from the registered collectors selected by the
+// configuration across all specified regions. Once ctx is cancelled no further collectors
+// are started and the resources collected so far are returned. A collector failing with an
+// error that is not skipped aborts the collection.
+func ProcessCollection(ctx context.Context, creds *types.Credentials, regions []string, cfg *config.Config) (types.Resources, error) {
 	var resourceCollectionChan = make(chan *types.Resource, 100)
 	var allResources types.Resources
 	g := new(errgroup.Group)
-	// Limit concurrent API calls to avoid rate limiting
-	g.SetLimit(20)
+	// Limit concurrent collectors, the rate limiters pace the API calls themselves
+	g.SetLimit(cfg.Settings.ScanConcurrency)
 
-	for collectorName, collector := range collectors {
-		for _, region := range regions {
+	for collectorName, d := range registry {
+		// Collectors outside resource-types.includes are not called at all
+		if !cfg.IncludesResourceType(collectorName) {
+			continue
+		}
+		collector := d.Collect
+		global := d.Scope == ScopeGlobal
+		// Global types are collected once, through the endpoints of the global region
+		collectorRegions := regions
+		if global {
+			collectorRegions = []string{cfg.Settings.GlobalRegion}
+		}
+		collectCtx := ctx
+		if global {
+			collectCtx = context.WithValue(ctx, scannedRegionsKey{}, regions)
+		}
+		for _, region := range collectorRegions {
 			c := collector
 			r := region
 			cn := collectorName
 			g.Go(func() error {
-				resources, err := collectWithRetry(c, creds, r, 3)
+				if ctx.Err() != nil {
+					return nil
+				}
+				resources, err := collect(collectCtx, cn, c, creds, r)
+				attrs := []any{utils.LogKeyAccount, creds.AccountID, utils.LogKeyRegion, r, utils.LogKeyProduct, cn}
 				if err != nil {
-					// Log but continue for "service not available in region" errors
-					if isServiceUnavailableError(err) {
-						logger.LogWarning("Service unavailable for %s in region %s: %v", cn, r, err)
+					if ctx.Err() != nil {
 						return nil
 					}
-					// Log but continue for throttling errors (we've already retried)
-					if isThrottlingError(err) {
-						logger.LogWarning("Throttling error for %s in region %s: %v", cn, r, err)
-						return nil
+					utils.RecordAPIError(cn, err)
+					attrs = append(attrs, utils.ErrorLogAttrs(err)...)
+					switch types.ClassifyError(cn, err) {
+					case types.ErrorUnavailable:
+						// Log but continue for "service not available in region" errors
+						slog.Warn("service unavailable", attrs...)
+					case types.ErrorThrottled:
+						// Log but continue for throttling errors (we've already retried at a reduced rate)
+						slog.Warn("throttled while collecting", attrs...)
+					default:
+						// Log other errors but continue with other regions/collectors
+						slog.Warn("collection failed", attrs...)
 					}
-					// Log other errors to file but continue with other regions/collectors
-					logger.LogWarning("Error collecting %s from region %s: %v", cn, r, err)
 					return nil
 				}
+				slog.Debug("collected resources", append(attrs, "count", len(resources))...)
 				for _, resource := range resources {
+					// Global resources with a home region are only kept if that region is scanned
+					if global && resource.Region != GlobalRegion && !slices.Contains(regions, resource.Region) {
+						continue
+					}
+					resource.AccountID = creds.AccountID
 					resourceCollectionChan <- resource
 				}
 				return nil
@@ -141,20 +164,8 @@ func ProcessCollection(creds *types.Credentials, regions []string, logger *utils
 	}
 
 	if collectedErr != nil {
-		logger.LogError("Fatal error during collection: %v", collectedErr)
-		fmt.Println("Error during collection, aborting:\n", collectedErr)
-		os.Exit(1)
+		return nil, fmt.Errorf("collection aborted: %w", collectedErr)
 	}
 
-	return allResources
-}
-
-// ListCollector returns an alphabetically sorted list of registered collector names.
-func ListCollectors() []string {
-	var collectorNames []string
-	for name, _ := range collectors {
-		collectorNames = append(collectorNames, name)
-	}
-	slices.Sort(collectorNames)
-	return collectorNames
+	return allResources, nil
 }
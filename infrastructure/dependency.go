@@ -0,0 +1,213 @@
+package infrastructure
+
+import (
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+
+	"github.com/arafato/ali-nuke/types"
+)
+
+// DeletionPlan describes the order in which resource types are deleted.
+type DeletionPlan struct {
+	Layers       [][]string // Resource types grouped into layers, deleted one layer after another
+	Cycles       [][]string // Dependency cycles; their members are deleted in the last layer
+	UnknownEdges []string   // Dependencies on resource types that are not registered ("VSwitch -> FlowLog")
+}
+
+// BuildDeletionPlan builds the dependency graph of all registered resource types and
+// returns the topological layers for the types that have resources in Ready state.
+// Types that are part of (or depend on) a cycle fall back to wave retries in a final layer.
+func BuildDeletionPlan(resources types.Resources) *DeletionPlan {
+	plan := &DeletionPlan{}
+
+	// Only keep edges between known types, report the rest
+	graph := make(map[string][]string)
+	for resourceType, d := range registry {
+		graph[resourceType] = nil
+		for _, dep := range d.DependsOn {
+			if _, ok := registry[dep]; !ok {
+				plan.UnknownEdges = append(plan.UnknownEdges, resourceType+" -> "+dep)
+				continue
+			}
+			graph[resourceType] = append(graph[resourceType], dep)
+		}
+	}
+	slices.Sort(plan.UnknownEdges)
+
+	// Resource types without registered dependencies have no prerequisites
+	for _, resource := range resources {
+		if _, ok := graph[resource.ProductName]; !ok {
+			graph[resource.ProductName] = nil
+		}
+	}
+
+	depth, unresolved := computeDepths(graph)
+	plan.Cycles = findCycles(graph, unresolved)
+
+	// Group present types by depth, dropping layers without resources
+	present := make(map[string]struct{})
+	for _, resource := range resources {
+		if resource.State() == types.Ready {
+			present[resource.ProductName] = struct{}{}
+		}
+	}
+
+	maxDepth := -1
+	for _, d := range depth {
+		maxDepth = max(maxDepth, d)
+	}
+	layers := make([][]string, maxDepth+2)
+	for resourceType := range present {
+		if d, ok := depth[resourceType]; ok {
+			layers[d] = append(layers[d], resourceType)
+		} else {
+			layers[len(layers)-1] = append(layers[len(layers)-1], resourceType)
+		}
+	}
+	for _, layer := range layers {
+		if len(layer) == 0 {
+			continue
+		}
+		slices.Sort(layer)
+		plan.Layers = append(plan.Layers, layer)
+	}
+
+	return plan
+}
+
+// computeDepths assigns every resource type the length of its longest dependency chain
+// using Kahn's algorithm. Types that cannot be ordered (cycles) are returned separately.
+func computeDepths(graph map[string][]string) (map[string]int, map[string]struct{}) {
+	inDegree := make(map[string]int)
+	dependents := make(map[string][]string)
+	for resourceType, deps := range graph {
+		inDegree[resourceType] = len(deps)
+		for _, dep := range deps {
+			dependents[dep] = append(dependents[dep], resourceType)
+		}
+	}
+
+	var queue []string
+	for resourceType, degree := range inDegree {
+		if degree == 0 {
+			queue = append(queue, resourceType)
+		}
+	}
+
+	depth := make(map[string]int)
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+		for _, dependent := range dependents[current] {
+			depth[dependent] = max(depth[dependent], depth[current]+1)
+			inDegree[dependent]--
+			if inDegree[dependent] == 0 {
+				queue = append(queue, dependent)
+			}
+		}
+		if _, ok := depth[current]; !ok {
+			depth[current] = 0
+		}
+	}
+
+	unresolved := make(map[string]struct{})
+	for resourceType, degree := range inDegree {
+		if degree > 0 {
+			unresolved[resourceType] = struct{}{}
+			delete(depth, resourceType)
+		}
+	}
+	return depth, unresolved
+}
+
+// findCycles returns the strongly connected components among the unresolved types
+// that actually form a cycle (Tarjan's algorithm).
+func findCycles(graph map[string][]string, unresolved map[string]struct{}) [][]string {
+	index := 0
+	indices := make(map[string]int)
+	lowLink := make(map[string]int)
+	onStack := make(map[string]bool)
+	var stack []string
+	var cycles [][]string
+
+	var strongConnect func(node string)
+	strongConnect = func(node string) {
+		indices[node] = index
+		lowLink[node] = index
+		index++
+		stack = append(stack, node)
+		onStack[node] = true
+
+		for _, dep := range graph[node] {
+			if _, ok := unresolved[dep]; !ok {
+				continue
+			}
+			if _, visited := indices[dep]; !visited {
+				strongConnect(dep)
+				lowLink[node] = min(lowLink[node], lowLink[dep])
+			} else if onStack[dep] {
+				lowLink[node] = min(lowLink[node], indices[dep])
+			}
+		}
+
+		if lowLink[node] != indices[node] {
+			return
+		}
+		var component []string
+		for {
+			top := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			onStack[top] = false
+			component = append(component, top)
+			if top == node {
+				break
+			}
+		}
+		if len(component) > 1 || slices.Contains(graph[node], node) {
+			slices.Sort(component)
+			cycles = append(cycles, component)
+		}
+	}
+
+	nodes := make([]string, 0, len(unresolved))
+	for node := range unresolved {
+		nodes = append(nodes, node)
+	}
+	slices.Sort(nodes)
+	for _, node := range nodes {
+		if _, visited := indices[node]; !visited {
+			strongConnect(node)
+		}
+	}
+	return cycles
+}
+
+// layerIndex returns a lookup from resource type to its layer in the plan.
+func (p *DeletionPlan) layerIndex() map[string]int {
+	index := make(map[string]int)
+	for i, layer := range p.Layers {
+		for _, resourceType := range layer {
+			index[resourceType] = i
+		}
+	}
+	return index
+}
+
+// Print writes the deletion order together with any cycles or unknown edges.
+func (p *DeletionPlan) Print(w io.Writer) {
+	if len(p.Layers) > 0 {
+		fmt.Fprintln(w, "\nDeletion order:")
+		for i, layer := range p.Layers {
+			fmt.Fprintf(w, "  %d. %s\n", i+1, strings.Join(layer, ", "))
+		}
+	}
+
+	for _, cycle := range p.Cycles {
+		fmt.Fprintf(w, "Warning: dependency cycle between %s, falling back to wave retries\n", strings.Join(cycle, ", "))
+	}
+	for _, edge := range p.UnknownEdges {
+		fmt.Fprintf(w, "Warning: unknown dependency %s is ignored\n", edge)
+	}
+}
@@ -0,0 +1,118 @@
+package infrastructure
+
+import (
+	"bytes"
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/arafato/ali-nuke/types"
+)
+
+// withRegistry replaces the registered resource types for the duration of the test. Each
+// type is given with the types it depends on.
+func withRegistry(t *testing.T, dependsOn map[string][]string) {
+	t.Helper()
+	old := registry
+	registry = make(map[string]*Descriptor)
+	t.Cleanup(func() { registry = old })
+
+	collect := func(context.Context, *types.Credentials, string) (types.Resources, error) { return nil, nil }
+	for name, deps := range dependsOn {
+		Register(Descriptor{Name: name, Product: "test", DependsOn: deps, Collect: collect})
+	}
+}
+
+// readyResources returns a Ready resource of every type
+func readyResources(resourceTypes ...string) types.Resources {
+	var resources types.Resources
+	for _, resourceType := range resourceTypes {
+		resources = append(resources, &types.Resource{ProductName: resourceType, ResourceID: strings.ToLower(resourceType) + "-1"})
+	}
+	return resources
+}
+
+func TestBuildDeletionPlanLayers(t *testing.T) {
+	withRegistry(t, map[string][]string{
+		"ECSInstance":      {},
+		"NetworkInterface": {"ECSInstance"},
+		"SecurityGroup":    {"ECSInstance", "NetworkInterface"},
+		"VSwitch":          {"ECSInstance", "NetworkInterface"},
+		"VPC":              {"VSwitch", "SecurityGroup"},
+		"Disk":             {},
+	})
+
+	tests := []struct {
+		name      string
+		resources types.Resources
+		want      [][]string
+	}{
+		{"all types", readyResources("VPC", "VSwitch", "SecurityGroup", "NetworkInterface", "ECSInstance", "Disk"),
+			[][]string{{"Disk", "ECSInstance"}, {"NetworkInterface"}, {"SecurityGroup", "VSwitch"}, {"VPC"}}},
+		// Layers without resources are dropped, the order of the rest is kept
+		{"gaps", readyResources("VPC", "ECSInstance"), [][]string{{"ECSInstance"}, {"VPC"}}},
+		// Unregistered types have no prerequisites
+		{"unregistered type", readyResources("VPC", "FlowLog"), [][]string{{"FlowLog"}, {"VPC"}}},
+		{"no resources", nil, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plan := BuildDeletionPlan(tt.resources)
+			if !reflect.DeepEqual(plan.Layers, tt.want) {
+				t.Errorf("layers = %v, want %v", plan.Layers, tt.want)
+			}
+			if len(plan.Cycles) > 0 || len(plan.UnknownEdges) > 0 {
+				t.Errorf("cycles %v, unknown edges %v; want none", plan.Cycles, plan.UnknownEdges)
+			}
+		})
+	}
+}
+
+func TestBuildDeletionPlanOnlyReadyResources(t *testing.T) {
+	withRegistry(t, map[string][]string{"VSwitch": {}, "VPC": {"VSwitch"}})
+	resources := readyResources("VSwitch", "VPC")
+	resources[0].SetState(types.Filtered)
+
+	if plan := BuildDeletionPlan(resources); !reflect.DeepEqual(plan.Layers, [][]string{{"VPC"}}) {
+		t.Errorf("layers = %v, want only VPC", plan.Layers)
+	}
+}
+
+func TestBuildDeletionPlanCycles(t *testing.T) {
+	withRegistry(t, map[string][]string{
+		"ECSInstance":   {},
+		"SecurityGroup": {"NetworkInterface"},
+		// NetworkInterface and SecurityGroup wait for each other
+		"NetworkInterface": {"ECSInstance", "SecurityGroup"},
+		"Image":            {"Image"},
+		// Depends on a cycle without being part of it
+		"VPC":     {"SecurityGroup"},
+		"VSwitch": {"ECSInstance", "FlowLog"},
+	})
+
+	plan := BuildDeletionPlan(readyResources("ECSInstance", "SecurityGroup", "NetworkInterface", "Image", "VPC", "VSwitch"))
+	wantLayers := [][]string{{"ECSInstance"}, {"VSwitch"}, {"Image", "NetworkInterface", "SecurityGroup", "VPC"}}
+	if !reflect.DeepEqual(plan.Layers, wantLayers) {
+		t.Errorf("layers = %v, want %v with the unordered types last", plan.Layers, wantLayers)
+	}
+	wantCycles := [][]string{{"Image"}, {"NetworkInterface", "SecurityGroup"}}
+	if !reflect.DeepEqual(plan.Cycles, wantCycles) {
+		t.Errorf("cycles = %v, want %v", plan.Cycles, wantCycles)
+	}
+	if want := []string{"VSwitch -> FlowLog"}; !reflect.DeepEqual(plan.UnknownEdges, want) {
+		t.Errorf("unknown edges = %v, want %v", plan.UnknownEdges, want)
+	}
+
+	var out bytes.Buffer
+	plan.Print(&out)
+	for _, want := range []string{
+		"  3. Image, NetworkInterface, SecurityGroup, VPC\n",
+		"Warning: dependency cycle between NetworkInterface, SecurityGroup, falling back to wave retries\n",
+		"Warning: unknown dependency VSwitch -> FlowLog is ignored\n",
+	} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("printed plan misses %q:\n%s", want, out.String())
+		}
+	}
+}
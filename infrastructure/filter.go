@@ -1,57 +1,87 @@
 package infrastructure
 
 import (
+	"fmt"
+	"log/slog"
+	"slices"
+
 	"github.com/arafato/ali-nuke/config"
 	"github.com/arafato/ali-nuke/types"
+	"github.com/arafato/ali-nuke/utils"
 )
 
-func FilterCollection(resources types.Resources, config *config.Config) {
+// FilterCollection marks resources excluded by the configuration as Filtered and
+// records the matching rule in FilterReason. All other visible resources become Ready.
+func FilterCollection(resources types.Resources, cfg *config.Config) {
 	// Resource type filter
 	resourceTypeFilterSet := make(map[string]struct{})
-	for _, filter := range config.ResourceTypes.Excludes {
+	for _, filter := range cfg.ResourceTypes.Excludes {
 		resourceTypeFilterSet[filter] = struct{}{}
 	}
 
 	// Region filter
 	regionFilterSet := make(map[string]struct{})
-	for _, region := range config.Regions.Excludes {
+	for _, region := range cfg.Regions.Excludes {
 		regionFilterSet[region] = struct{}{}
 	}
 
-	// Resource ID filter
-	resourceIDLookup := make(map[string]map[string]struct{})
-	for _, resourceIDFilter := range config.ResourceIDs.Excludes {
-		if _, ok := resourceIDLookup[resourceIDFilter.ResourceType]; !ok {
-			resourceIDLookup[resourceIDFilter.ResourceType] = make(map[string]struct{})
-		}
-		resourceIDLookup[resourceIDFilter.ResourceType][resourceIDFilter.ID] = struct{}{}
+	// Resource ID filters, grouped by resource type ("*" applies to all types)
+	resourceIDFilters := make(map[string][]config.ResourceIDFilter)
+	for _, filter := range cfg.ResourceIDs.Excludes {
+		resourceIDFilters[filter.ResourceType] = append(resourceIDFilters[filter.ResourceType], filter)
 	}
 
+	// Resource tag filters (validated when the config is loaded)
+	tagIncludes := parseTagRules(cfg.ResourceTags.Includes)
+	tagExcludes := parseTagRules(cfg.ResourceTags.Excludes)
+
 	for _, resource := range resources {
 		if resource.State() == types.Hidden {
 			continue
 		}
+		resource.FilterReason = ""
 
 		// Filter by resource type
+		if !cfg.IncludesResourceType(resource.ProductName) {
+			filterResource(resource, "resource type not included")
+			continue
+		}
 		if _, ok := resourceTypeFilterSet[resource.ProductName]; ok {
-			resource.SetState(types.Filtered)
+			filterResource(resource, "resource type excluded")
 			continue
 		}
 
-		// Filter by region
+		// Filter by region. Resources of global types are in scope of includes only if it
+		// lists the global region.
+		if len(cfg.Regions.Includes) > 0 && !slices.Contains(cfg.Regions.Includes, resource.Region) {
+			filterResource(resource, "region not included")
+			continue
+		}
 		if _, ok := regionFilterSet[resource.Region]; ok {
-			resource.SetState(types.Filtered)
+			filterResource(resource, "region excluded")
 			continue
 		}
 
-		// Filter by resource ID or name
-		if idSet, ok := resourceIDLookup[resource.ProductName]; ok {
-			if _, ok := idSet[resource.ResourceID]; ok {
-				resource.SetState(types.Filtered)
-				continue
-			}
-			if _, ok := idSet[resource.ResourceName]; ok {
-				resource.SetState(types.Filtered)
+		// Filter by resource ID, name or property
+		if filter, ok := matchResourceIDFilter(resource, resourceIDFilters); ok {
+			filterResource(resource, "resource-ids: "+filter.String())
+			continue
+		}
+
+		// Filter by resource tags: excludes always win, includes restrict to matching resources.
+		// Resource types without tag support never match a tag rule. A resource whose tags
+		// could not be read is filtered if there are tag rules, an exclude might protect it.
+		if resource.TagsUnknown && len(tagIncludes)+len(tagExcludes) > 0 {
+			filterResource(resource, "resource-tags: tags unreadable")
+			continue
+		}
+		if rule, ok := matchTagRule(resource.Tags, tagExcludes); ok {
+			filterResource(resource, fmt.Sprintf("resource-tags: %s excluded", rule))
+			continue
+		}
+		if len(tagIncludes) > 0 {
+			if _, ok := matchTagRule(resource.Tags, tagIncludes); !ok {
+				filterResource(resource, "resource-tags: no include matched")
 				continue
 			}
 		}
@@ -59,3 +89,50 @@ func FilterCollection(resources types.Resources, config *config.Config) {
 		resource.SetState(types.Ready)
 	}
 }
+
+// filterResource marks the resource as Filtered for the given reason
+func filterResource(resource *types.Resource, reason string) {
+	resource.FilterReason = reason
+	resource.SetState(types.Filtered)
+	slog.Debug("resource filtered", append(utils.ResourceLogAttrs(resource), "reason", reason)...)
+}
+
+// matchResourceIDFilter returns the first resource ID filter that excludes the resource
+func matchResourceIDFilter(resource *types.Resource, filters map[string][]config.ResourceIDFilter) (config.ResourceIDFilter, bool) {
+	candidates := slices.Concat(filters[resource.ProductName], filters["*"])
+	for _, filter := range candidates {
+		var matched bool
+		if filter.Property == "" {
+			// Without a property the filter applies to either ID or name
+			matched = filter.Matches(resource.ResourceID) || filter.Matches(resource.ResourceName)
+		} else if value, ok := resource.Property(filter.Property); ok {
+			matched = filter.Matches(value)
+		}
+
+		if matched != filter.Invert {
+			return filter, true
+		}
+	}
+	return config.ResourceIDFilter{}, false
+}
+
+// parseTagRules parses tag rules, skipping invalid ones
+func parseTagRules(rules []string) []config.TagRule {
+	var parsed []config.TagRule
+	for _, rule := range rules {
+		if tagRule, err := config.ParseTagRule(rule); err == nil {
+			parsed = append(parsed, tagRule)
+		}
+	}
+	return parsed
+}
+
+// matchTagRule returns the first rule satisfied by the tags
+func matchTagRule(tags types.Tags, rules []config.TagRule) (config.TagRule, bool) {
+	for _, rule := range rules {
+		if rule.Matches(tags) {
+			return rule, true
+		}
+	}
+	return config.TagRule{}, false
+}
@@ -0,0 +1,106 @@
+package infrastructure
+
+import (
+	"testing"
+
+	"github.com/arafato/ali-nuke/config"
+	"github.com/arafato/ali-nuke/types"
+)
+
+func TestFilterCollectionUnreadableTags(t *testing.T) {
+	tests := []struct {
+		name       string
+		tags       config.IncludeExclude
+		wantState  types.ResourceState
+		wantReason string
+	}{
+		{"no tag rules", config.IncludeExclude{}, types.Ready, ""},
+		{"exclude rule", config.IncludeExclude{Excludes: []string{"keep=true"}}, types.Filtered, "resource-tags: tags unreadable"},
+		{"include rule", config.IncludeExclude{Includes: []string{"env=dev"}}, types.Filtered, "resource-tags: tags unreadable"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := config.NewConfig()
+			cfg.ResourceTags = tt.tags
+			bucket := &types.Resource{ProductName: "OSSBucket", Region: "cn-hangzhou", ResourceID: "bucket-1", TagsUnknown: true}
+			FilterCollection(types.Resources{bucket}, &cfg)
+			if bucket.State() != tt.wantState || bucket.FilterReason != tt.wantReason {
+				t.Errorf("state %s (%q), want %s (%q)", bucket.State(), bucket.FilterReason, tt.wantState, tt.wantReason)
+			}
+		})
+	}
+}
+
+func TestFilterCollectionRegions(t *testing.T) {
+	tests := []struct {
+		name       string
+		regions    config.IncludeExclude
+		region     string
+		wantReason string
+	}{
+		{"all regions", config.IncludeExclude{}, GlobalRegion, ""},
+		{"global not included", config.IncludeExclude{Includes: []string{"cn-shanghai"}}, GlobalRegion, "region not included"},
+		{"global included", config.IncludeExclude{Includes: []string{"cn-shanghai", GlobalRegion}}, GlobalRegion, ""},
+		{"global excluded", config.IncludeExclude{Excludes: []string{GlobalRegion}}, GlobalRegion, "region excluded"},
+		{"region included", config.IncludeExclude{Includes: []string{"cn-shanghai"}}, "cn-shanghai", ""},
+		{"region not included", config.IncludeExclude{Includes: []string{"cn-shanghai"}}, "cn-hangzhou", "region not included"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := config.NewConfig()
+			cfg.Regions = tt.regions
+			resource := &types.Resource{ProductName: "CENInstance", Region: tt.region, ResourceID: "cen-1"}
+			FilterCollection(types.Resources{resource}, &cfg)
+			wantState := types.Ready
+			if tt.wantReason != "" {
+				wantState = types.Filtered
+			}
+			if resource.State() != wantState || resource.FilterReason != tt.wantReason {
+				t.Errorf("state %s (%q), want %s (%q)", resource.State(), resource.FilterReason, wantState, tt.wantReason)
+			}
+		})
+	}
+}
+
+func TestFilterCollectionResourceIDs(t *testing.T) {
+	cfg, err := config.Validate([]byte(`resource-ids:
+  excludes:
+    - resourceType: VPC
+      type: glob
+      value: keep-*
+    # Only instances named test-* are deleted
+    - resourceType: ECSInstance
+      property: name
+      type: glob
+      value: test-*
+      invert: true
+    - resourceType: "*"
+      type: regex
+      value: ^protected-
+`), config.ValidateOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		product, id, name string
+		wantReason        string
+	}{
+		{"VPC", "vpc-1", "keep-network", `resource-ids: id/name glob "keep-*"`},
+		{"VPC", "vpc-2", "network", ""},
+		{"ECSInstance", "i-1", "test-web", ""},
+		{"ECSInstance", "i-2", "web", `resource-ids: not name glob "test-*"`},
+		{"Disk", "protected-disk", "", `resource-ids: id/name regex "^protected-"`},
+	}
+	for _, tt := range tests {
+		resource := &types.Resource{ProductName: tt.product, Region: "cn-hangzhou", ResourceID: tt.id, ResourceName: tt.name}
+		FilterCollection(types.Resources{resource}, cfg)
+		wantState := types.Ready
+		if tt.wantReason != "" {
+			wantState = types.Filtered
+		}
+		if resource.State() != wantState || resource.FilterReason != tt.wantReason {
+			t.Errorf("%s %s: state %s (%q), want %s (%q)", tt.product, tt.id, resource.State(), resource.FilterReason, wantState, tt.wantReason)
+		}
+	}
+}
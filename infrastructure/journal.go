@@ -0,0 +1,171 @@
+package infrastructure
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/arafato/ali-nuke/types"
+	"github.com/arafato/ali-nuke/utils"
+)
+
+const (
+	journalFileName = "journal.jsonl"
+	// RunConfigFileName is the copy of the configuration kept in the run directory
+	RunConfigFileName = "config.yaml"
+)
+
+// JournalEntry records the state of a resource at a point in time. From is empty for the
+// entries written when the journal is started.
+type JournalEntry struct {
+	Time    time.Time `json:"time"`
+	Account string    `json:"account,omitempty"`
+	Region  string    `json:"region"`
+	Product string    `json:"product"`
+	ID      string    `json:"id"`
+	Name    string    `json:"name"`
+	From    string    `json:"from,omitempty"`
+	State   string    `json:"state"`
+	Error   string    `json:"error,omitempty"` // Last error of failed and retried resources
+}
+
+// key identifies the resource of an entry across runs
+func (e JournalEntry) key() string {
+	return e.Account + "/" + e.Region + "/" + e.Product + "/" + e.ID
+}
+
+func journalEntryOf(r *types.Resource) JournalEntry {
+	return JournalEntry{
+		Time:    time.Now().UTC(),
+		Account: r.AccountID,
+		Region:  r.Region,
+		Product: r.ProductName,
+		ID:      r.ResourceID,
+		Name:    r.ResourceName,
+		State:   r.State().String(),
+	}
+}
+
+// Journal is an append-only log of resource state transitions, one JSON object per line
+type Journal struct {
+	mu      sync.Mutex
+	file    *os.File
+	encoder *json.Encoder
+}
+
+// OpenJournal creates the run directory if needed and opens its journal for appending
+func OpenJournal(runDir string) (*Journal, error) {
+	if err := os.MkdirAll(runDir, 0o700); err != nil {
+		return nil, fmt.Errorf("failed to create run directory: %w", err)
+	}
+	file, err := os.OpenFile(filepath.Join(runDir, journalFileName), os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open journal: %w", err)
+	}
+	return &Journal{file: file, encoder: json.NewEncoder(file)}, nil
+}
+
+// Start records the current state of the visible resources and then every state transition
+// until the journal is closed
+func (j *Journal) Start(resources types.Resources) error {
+	for _, resource := range resources {
+		if resource.State() == types.Hidden {
+			continue
+		}
+		if err := j.write(journalEntryOf(resource)); err != nil {
+			return err
+		}
+	}
+	types.SetStateObserver(j.record)
+	return nil
+}
+
+// record writes a state transition. Write errors cannot be returned from the observer, the
+// deletion goes on without a journal rather than stopping halfway.
+func (j *Journal) record(r *types.Resource, from, to types.ResourceState) {
+	entry := journalEntryOf(r)
+	entry.From = from.String()
+	entry.State = to.String()
+	if lastErr := r.LastError(); lastErr != nil && (to == types.Failed || to == types.PendingRetry) {
+		entry.Error = lastErr.String()
+	}
+	if err := j.write(entry); err != nil {
+		slog.Warn("failed to write journal", append(utils.ResourceLogAttrs(r), utils.ErrorLogAttrs(err)...)...)
+	}
+}
+
+func (j *Journal) write(entry JournalEntry) error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return j.encoder.Encode(entry)
+}
+
+// Close stops recording and closes the journal file
+func (j *Journal) Close() error {
+	types.SetStateObserver(nil)
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return j.file.Close()
+}
+
+// LoadJournal returns the last recorded state of every resource in the journal of a run directory
+func LoadJournal(runDir string) (map[string]types.ResourceState, error) {
+	file, err := os.Open(filepath.Join(runDir, journalFileName))
+	if err != nil {
+		return nil, fmt.Errorf("failed to open journal: %w", err)
+	}
+	defer file.Close()
+
+	states := make(map[string]types.ResourceState)
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	line := 0
+	for scanner.Scan() {
+		line++
+		var entry JournalEntry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			// A crash can leave a truncated last line behind
+			slog.Warn("skipping invalid journal line", "line", line, utils.LogKeyError, err.Error())
+			continue
+		}
+		state, ok := types.ParseResourceState(entry.State)
+		if !ok {
+			return nil, fmt.Errorf("journal line %d: unknown state %q", line, entry.State)
+		}
+		states[entry.key()] = state
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read journal: %w", err)
+	}
+	return states, nil
+}
+
+// ResumeCollection restricts freshly scanned resources to an interrupted run. Resources the
+// journal records as Deleted are marked Deleted and not touched again, resources that were
+// not part of the run are filtered. All other resources of the run, whether they were
+// pending, in progress or failed, stay Ready and are deleted again. It returns the number
+// of resources to delete.
+func ResumeCollection(resources types.Resources, states map[string]types.ResourceState) int {
+	resumed := 0
+	for _, resource := range resources {
+		if resource.State() != types.Ready {
+			continue
+		}
+		state, ok := states[journalEntryOf(resource).key()]
+		switch {
+		case !ok, state == types.Filtered, state == types.Hidden:
+			resource.FilterReason = "not part of the resumed run"
+			resource.SetState(types.Filtered)
+		case state == types.Deleted:
+			resource.SetState(types.Deleted)
+		default:
+			resumed++
+		}
+	}
+	return resumed
+}
@@ -0,0 +1,102 @@
+package infrastructure
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/arafato/ali-nuke/types"
+)
+
+// journalResource returns a Ready resource of the test account
+func journalResource(product, id string) *types.Resource {
+	return &types.Resource{AccountID: "1234567890123456", Region: "cn-hangzhou", ProductName: product, ResourceID: id}
+}
+
+func TestJournalResume(t *testing.T) {
+	dir := t.TempDir()
+	deleted := journalResource("VPC", "vpc-deleted")
+	failed := journalResource("VPC", "vpc-failed")
+	pending := journalResource("VSwitch", "vsw-pending")
+	untouched := journalResource("VSwitch", "vsw-untouched")
+	filtered := journalResource("ECSInstance", "i-filtered")
+	filtered.SetState(types.Filtered)
+	hidden := journalResource("RouteTable", "vtb-system")
+	hidden.SetState(types.Hidden)
+
+	journal, err := OpenJournal(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := journal.Start(types.Resources{deleted, failed, pending, untouched, filtered, hidden}); err != nil {
+		t.Fatal(err)
+	}
+	deleted.SetState(types.Removing)
+	deleted.SetState(types.Deleted)
+	failed.SetState(types.Removing)
+	failed.SetState(types.Failed)
+	pending.SetState(types.Removing)
+	pending.SetState(types.PendingRetry)
+	if err := journal.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	// A crash can leave a truncated line behind
+	f, err := os.OpenFile(filepath.Join(dir, journalFileName), os.O_APPEND|os.O_WRONLY, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.WriteString(`{"time":"2025-01-02T03:04:05Z","region":"cn-hang`)
+	f.Close()
+
+	states, err := LoadJournal(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The resources found by the scan of the resumed run
+	rescanned := map[string]*types.Resource{}
+	var resources types.Resources
+	for _, r := range []*types.Resource{deleted, failed, pending, untouched, filtered, hidden} {
+		resource := journalResource(r.ProductName, r.ResourceID)
+		rescanned[r.ResourceID] = resource
+		resources = append(resources, resource)
+	}
+	created := journalResource("VPC", "vpc-created-since")
+	resources = append(resources, created)
+
+	if n := ResumeCollection(resources, states); n != 3 {
+		t.Errorf("ResumeCollection() = %d, want the failed, pending and untouched resources", n)
+	}
+	tests := []struct {
+		resource *types.Resource
+		want     types.ResourceState
+	}{
+		{rescanned["vpc-deleted"], types.Deleted},
+		{rescanned["vpc-failed"], types.Ready},
+		{rescanned["vsw-pending"], types.Ready},
+		{rescanned["vsw-untouched"], types.Ready},
+		{rescanned["i-filtered"], types.Filtered},
+		{rescanned["vtb-system"], types.Filtered},
+		{created, types.Filtered},
+	}
+	for _, tt := range tests {
+		if got := tt.resource.State(); got != tt.want {
+			t.Errorf("%s: state %s, want %s", tt.resource.ResourceID, got, tt.want)
+		}
+	}
+	if created.FilterReason != "not part of the resumed run" {
+		t.Errorf("filter reason %q, want not part of the resumed run", created.FilterReason)
+	}
+}
+
+func TestLoadJournalUnknownState(t *testing.T) {
+	dir := t.TempDir()
+	line := `{"time":"2025-01-02T03:04:05Z","region":"cn-hangzhou","product":"VPC","id":"vpc-1","name":"","state":"Exploded"}` + "\n"
+	if err := os.WriteFile(filepath.Join(dir, journalFileName), []byte(line), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadJournal(dir); err == nil {
+		t.Error("LoadJournal() accepted an unknown state")
+	}
+}
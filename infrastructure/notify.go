@@ -0,0 +1,245 @@
+package infrastructure
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/arafato/ali-nuke/config"
+	"github.com/arafato/ali-nuke/types"
+	"github.com/arafato/ali-nuke/utils"
+)
+
+// maxNotifiedFailures bounds the failed resources listed in chat messages
+const maxNotifiedFailures = 20
+
+// notifyBackoff is the delay before the second attempt of a webhook, doubled for every
+// further attempt
+var notifyBackoff = time.Second
+
+// RunSummary is the summary of a run posted to the notification webhooks
+type RunSummary struct {
+	Command     string                 `json:"command"`
+	DryRun      bool                   `json:"dryRun"`
+	Interrupted bool                   `json:"interrupted"`
+	Accounts    []string               `json:"accounts"`
+	Regions     []string               `json:"regions"`
+	StartedAt   time.Time              `json:"startedAt"`
+	Duration    string                 `json:"duration"`
+	Summary     utils.ReportSummary    `json:"summary"`
+	Failed      []utils.ResourceRecord `json:"failed"`
+}
+
+// NewRunSummary summarizes the final state of the resources of a run
+func NewRunSummary(command string, dryRun, interrupted bool, accounts, regions []string, startedAt time.Time, resources types.Resources) RunSummary {
+	report := utils.NewReport(resources)
+	summary := RunSummary{
+		Command:     command,
+		DryRun:      dryRun,
+		Interrupted: interrupted,
+		Accounts:    accounts,
+		Regions:     regions,
+		StartedAt:   startedAt.UTC(),
+		Duration:    time.Since(startedAt).Round(time.Second).String(),
+		Summary:     report.Summary,
+		Failed:      []utils.ResourceRecord{},
+	}
+	for _, record := range report.Resources {
+		if record.State == types.Failed.String() {
+			summary.Failed = append(summary.Failed, record)
+		}
+	}
+	return summary
+}
+
+// Notify posts the summary to every webhook. Failures are logged, they do not affect the run.
+func Notify(ctx context.Context, notifications []config.Notification, summary RunSummary) {
+	for _, n := range notifications {
+		if err := notify(ctx, n, summary); err != nil {
+			slog.Error("notification failed", "type", n.Format(), utils.LogKeyError, err.Error())
+		}
+	}
+}
+
+// notify posts the summary to a webhook, retrying failed attempts with backoff
+func notify(ctx context.Context, n config.Notification, summary RunSummary) error {
+	body, err := notificationPayload(n.Format(), summary)
+	if err != nil {
+		return err
+	}
+
+	var lastErr error
+	for attempt := 0; attempt < n.MaxAttempts(); attempt++ {
+		if attempt > 0 {
+			// Exponential backoff: 1s, 2s, 4s, ...
+			select {
+			case <-time.After(notifyBackoff << (attempt - 1)):
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+
+		var retriable bool
+		retriable, lastErr = postNotification(ctx, n, body)
+		if lastErr == nil || !retriable {
+			return lastErr
+		}
+		slog.Warn("notification attempt failed", "type", n.Format(), "attempt", attempt+1, utils.LogKeyError, lastErr.Error())
+	}
+	return lastErr
+}
+
+// postNotification sends one request and reports whether a failure is worth retrying
+func postNotification(ctx context.Context, n config.Notification, body []byte) (bool, error) {
+	ctx, cancel := context.WithTimeout(ctx, n.AttemptTimeout())
+	defer cancel()
+
+	target := n.URL
+	if n.Format() == config.NotificationDingTalk && n.Secret != "" {
+		target = signDingTalkURL(target, n.Secret, time.Now())
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, target, bytes.NewReader(body))
+	if err != nil {
+		// The error quotes the URL, which may contain the token of the webhook
+		return false, errors.New("invalid webhook URL")
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for key, value := range n.Headers {
+		req.Header.Set(key, value)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return true, redactURL(err)
+	}
+	defer resp.Body.Close()
+	respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+
+	if resp.StatusCode >= 300 {
+		err := fmt.Errorf("webhook returned %s: %s", resp.Status, strings.TrimSpace(string(respBody)))
+		return resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500, err
+	}
+
+	// DingTalk reports errors, including its own rate limit, in the body of a 200 response
+	if n.Format() == config.NotificationDingTalk {
+		var result struct {
+			ErrCode int    `json:"errcode"`
+			ErrMsg  string `json:"errmsg"`
+		}
+		if json.Unmarshal(respBody, &result) == nil && result.ErrCode != 0 {
+			return result.ErrCode == 130101, fmt.Errorf("dingtalk error %d: %s", result.ErrCode, result.ErrMsg)
+		}
+	}
+	return false, nil
+}
+
+// redactURL replaces the URL in a request error by its host. Webhook URLs carry their
+// credentials, e.g. the secret path of Slack or the access token and signature of DingTalk.
+func redactURL(err error) error {
+	var urlErr *url.Error
+	if !errors.As(err, &urlErr) {
+		return err
+	}
+	host := "webhook"
+	if u, parseErr := url.Parse(urlErr.URL); parseErr == nil {
+		host = u.Host
+	}
+	return fmt.Errorf("%s %s: %w", urlErr.Op, host, urlErr.Err)
+}
+
+// signDingTalkURL adds the timestamp and signature required by robots with a signing secret
+func signDingTalkURL(webhook, secret string, now time.Time) string {
+	timestamp := strconv.FormatInt(now.UnixMilli(), 10)
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp + "\n" + secret))
+	sign := base64.StdEncoding.EncodeToString(mac.Sum(nil))
+
+	separator := "?"
+	if strings.Contains(webhook, "?") {
+		separator = "&"
+	}
+	return webhook + separator + "timestamp=" + timestamp + "&sign=" + url.QueryEscape(sign)
+}
+
+// notificationPayload renders the summary in the format of a webhook
+func notificationPayload(format string, summary RunSummary) ([]byte, error) {
+	switch format {
+	case config.NotificationSlack:
+		return json.Marshal(map[string]any{
+			"text": strings.Join(summaryLines(summary, "*", "`"), "\n"),
+		})
+	case config.NotificationDingTalk:
+		return json.Marshal(map[string]any{
+			"msgtype": "markdown",
+			"markdown": map[string]string{
+				"title": summaryTitle(summary),
+				// DingTalk markdown needs an empty line to break a line
+				"text": strings.Join(summaryLines(summary, "**", "`"), "\n\n"),
+			},
+		})
+	case config.NotificationJSON:
+		return json.Marshal(summary)
+	}
+	return nil, errors.New("unknown notification type " + format)
+}
+
+// summaryTitle returns the headline of a chat message
+func summaryTitle(summary RunSummary) string {
+	mode := "deletion"
+	if summary.DryRun {
+		mode = "dry run"
+	}
+	outcome := "finished"
+	switch {
+	case summary.Interrupted:
+		outcome = "interrupted"
+	case summary.Summary.Failed > 0:
+		outcome = "finished with failures"
+	}
+	return fmt.Sprintf("ali-nuke %s (%s) %s", summary.Command, mode, outcome)
+}
+
+// summaryLines renders the summary as chat message lines using the given markup for bold
+// text and code
+func summaryLines(summary RunSummary, bold, code string) []string {
+	s := summary.Summary
+	lines := []string{
+		bold + summaryTitle(summary) + bold,
+		fmt.Sprintf("Accounts: %s", strings.Join(summary.Accounts, ", ")),
+		fmt.Sprintf("Regions: %s", strings.Join(summary.Regions, ", ")),
+		fmt.Sprintf("Duration: %s", summary.Duration),
+		fmt.Sprintf("Resources: %d found, %d to be removed, %d filtered, %d deleted, %d failed, %d pending",
+			s.Total, s.Ready, s.Filtered, s.Deleted, s.Failed, s.PendingRetry+s.Verifying+s.Removing),
+	}
+	if len(summary.Failed) == 0 {
+		return lines
+	}
+
+	lines = append(lines, bold+"Failed resources:"+bold)
+	for i, record := range summary.Failed {
+		if i == maxNotifiedFailures {
+			lines = append(lines, fmt.Sprintf("... and %d more", len(summary.Failed)-maxNotifiedFailures))
+			break
+		}
+		reason := ""
+		if record.Error != nil {
+			reason = ": " + record.Error.Summary()
+		}
+		lines = append(lines, fmt.Sprintf("- [%s %s] %s %s%s%s%s",
+			record.Account, record.Region, record.Product, code, record.ID, code, reason))
+	}
+	return lines
+}
@@ -0,0 +1,302 @@
+package infrastructure
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/arafato/ali-nuke/config"
+	"github.com/arafato/ali-nuke/types"
+	"github.com/arafato/ali-nuke/utils"
+)
+
+// webhookRequest is a request received by a webhook
+type webhookRequest struct {
+	Query  url.Values
+	Header http.Header
+	Body   []byte
+}
+
+// webhook is a test server answering with the given status codes and bodies in turn,
+// repeating the last one
+type webhook struct {
+	*httptest.Server
+	mu       sync.Mutex
+	requests []webhookRequest
+}
+
+type webhookResponse struct {
+	status int
+	body   string
+}
+
+func newWebhook(t *testing.T, responses ...webhookResponse) *webhook {
+	t.Helper()
+	if len(responses) == 0 {
+		responses = []webhookResponse{{status: http.StatusOK}}
+	}
+	w := &webhook{}
+	w.Server = httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		w.mu.Lock()
+		w.requests = append(w.requests, webhookRequest{Query: r.URL.Query(), Header: r.Header.Clone(), Body: body})
+		response := responses[min(len(w.requests), len(responses))-1]
+		w.mu.Unlock()
+		rw.WriteHeader(response.status)
+		io.WriteString(rw, response.body)
+	}))
+	t.Cleanup(w.Close)
+
+	// Keep retries fast
+	old := notifyBackoff
+	notifyBackoff = time.Millisecond
+	t.Cleanup(func() { notifyBackoff = old })
+	return w
+}
+
+// received returns the requests received so far
+func (w *webhook) received() []webhookRequest {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return append([]webhookRequest(nil), w.requests...)
+}
+
+// testSummary returns the summary of a deletion with one failed resource
+func testSummary() RunSummary {
+	return RunSummary{
+		Command:   "nuke",
+		Accounts:  []string{"sandbox (1234567890123456)"},
+		Regions:   []string{"cn-hangzhou", "cn-shanghai"},
+		StartedAt: time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC),
+		Duration:  "1m30s",
+		Summary:   utils.ReportSummary{Total: 3, Deleted: 1, Filtered: 1, Failed: 1},
+		Failed: []utils.ResourceRecord{{
+			Account: "1234567890123456", Region: "cn-hangzhou", Product: "VPC", ID: "vpc-1",
+			State: types.Failed.String(),
+			Error: &types.ResourceError{Code: "DependencyViolation", Message: "vpc-1 is in use"},
+		}},
+	}
+}
+
+func TestNotifyPayloads(t *testing.T) {
+	const (
+		title  = "ali-nuke nuke (deletion) finished with failures"
+		failed = "[1234567890123456 cn-hangzhou] VPC `vpc-1`: DependencyViolation: vpc-1 is in use"
+	)
+	tests := []struct {
+		format string
+		check  func(t *testing.T, body []byte)
+	}{
+		{config.NotificationJSON, func(t *testing.T, body []byte) {
+			var got RunSummary
+			if err := json.Unmarshal(body, &got); err != nil {
+				t.Fatalf("payload is not a run summary: %v", err)
+			}
+			want := testSummary()
+			if got.Command != want.Command || got.DryRun || got.Summary != want.Summary || !got.StartedAt.Equal(want.StartedAt) {
+				t.Errorf("summary = %+v, want %+v", got, want)
+			}
+			if len(got.Failed) != 1 || got.Failed[0].ID != "vpc-1" || got.Failed[0].Error.Code != "DependencyViolation" {
+				t.Errorf("failed = %+v, want vpc-1 with DependencyViolation", got.Failed)
+			}
+		}},
+		{config.NotificationSlack, func(t *testing.T, body []byte) {
+			var got struct {
+				Text string `json:"text"`
+			}
+			if err := json.Unmarshal(body, &got); err != nil {
+				t.Fatalf("payload is not a Slack message: %v", err)
+			}
+			lines := strings.Split(got.Text, "\n")
+			if lines[0] != "*"+title+"*" {
+				t.Errorf("headline = %q, want %q", lines[0], "*"+title+"*")
+			}
+			if !strings.Contains(got.Text, "Resources: 3 found, 0 to be removed, 1 filtered, 1 deleted, 1 failed, 0 pending") {
+				t.Errorf("counts missing from %q", got.Text)
+			}
+			if lines[len(lines)-1] != "- "+failed {
+				t.Errorf("last line = %q, want %q", lines[len(lines)-1], "- "+failed)
+			}
+		}},
+		{config.NotificationDingTalk, func(t *testing.T, body []byte) {
+			var got struct {
+				MsgType  string `json:"msgtype"`
+				Markdown struct {
+					Title string `json:"title"`
+					Text  string `json:"text"`
+				} `json:"markdown"`
+			}
+			if err := json.Unmarshal(body, &got); err != nil {
+				t.Fatalf("payload is not a DingTalk message: %v", err)
+			}
+			if got.MsgType != "markdown" || got.Markdown.Title != title {
+				t.Errorf("msgtype %q, title %q, want markdown and %q", got.MsgType, got.Markdown.Title, title)
+			}
+			if !strings.HasPrefix(got.Markdown.Text, "**"+title+"**\n\nAccounts: ") {
+				t.Errorf("text = %q, want bold headline and lines separated by empty lines", got.Markdown.Text)
+			}
+			if !strings.HasSuffix(got.Markdown.Text, "\n\n- "+failed) {
+				t.Errorf("text = %q, want failed resource at the end", got.Markdown.Text)
+			}
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			hook := newWebhook(t)
+			n := config.Notification{Type: tt.format, URL: hook.URL, Headers: map[string]string{"Authorization": "Bearer token"}}
+			Notify(t.Context(), []config.Notification{n}, testSummary())
+
+			requests := hook.received()
+			if len(requests) != 1 {
+				t.Fatalf("webhook called %d times, want 1", len(requests))
+			}
+			if ct := requests[0].Header.Get("Content-Type"); ct != "application/json" {
+				t.Errorf("Content-Type = %q, want application/json", ct)
+			}
+			if auth := requests[0].Header.Get("Authorization"); auth != "Bearer token" {
+				t.Errorf("Authorization = %q, want the configured header", auth)
+			}
+			tt.check(t, requests[0].Body)
+		})
+	}
+}
+
+// dingTalkSign computes the signature of a DingTalk request as documented for robots
+func dingTalkSign(timestamp, secret string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp + "\n" + secret))
+	return base64.StdEncoding.EncodeToString(mac.Sum(nil))
+}
+
+func TestSignDingTalkURL(t *testing.T) {
+	now := time.UnixMilli(1700000000123)
+	for _, webhookURL := range []string{
+		"https://oapi.dingtalk.com/robot/send?access_token=abc",
+		"https://example.com/robot",
+	} {
+		signed, err := url.Parse(signDingTalkURL(webhookURL, "SEC-secret", now))
+		if err != nil {
+			t.Fatalf("%s: signed URL does not parse: %v", webhookURL, err)
+		}
+		query := signed.Query()
+		if got := query.Get("timestamp"); got != "1700000000123" {
+			t.Errorf("%s: timestamp = %q, want milliseconds 1700000000123", webhookURL, got)
+		}
+		if got, want := query.Get("sign"), dingTalkSign("1700000000123", "SEC-secret"); got != want {
+			t.Errorf("%s: sign = %q, want %q", webhookURL, got, want)
+		}
+		if strings.Contains(webhookURL, "access_token") && query.Get("access_token") != "abc" {
+			t.Errorf("%s: access_token lost in %s", webhookURL, signed)
+		}
+	}
+}
+
+func TestNotifyDingTalkSigned(t *testing.T) {
+	hook := newWebhook(t)
+	n := config.Notification{Type: config.NotificationDingTalk, URL: hook.URL + "?access_token=abc", Secret: "SEC-secret"}
+	before := time.Now().UnixMilli()
+	Notify(t.Context(), []config.Notification{n}, testSummary())
+
+	requests := hook.received()
+	if len(requests) != 1 {
+		t.Fatalf("webhook called %d times, want 1", len(requests))
+	}
+	query := requests[0].Query
+	timestamp, err := strconv.ParseInt(query.Get("timestamp"), 10, 64)
+	if err != nil || timestamp < before || timestamp > time.Now().UnixMilli() {
+		t.Errorf("timestamp = %q, want the time of the request in milliseconds", query.Get("timestamp"))
+	}
+	if got, want := query.Get("sign"), dingTalkSign(query.Get("timestamp"), "SEC-secret"); got != want {
+		t.Errorf("sign = %q, want %q", got, want)
+	}
+	if query.Get("access_token") != "abc" {
+		t.Errorf("access_token lost: %v", query)
+	}
+}
+
+func TestNotifyRetries(t *testing.T) {
+	tests := []struct {
+		name      string
+		format    string
+		responses []webhookResponse
+		calls     int
+		wantErr   bool
+	}{
+		{"server error", config.NotificationJSON, []webhookResponse{{status: http.StatusInternalServerError}}, 3, true},
+		{"rate limited", config.NotificationJSON, []webhookResponse{{status: http.StatusTooManyRequests}}, 3, true},
+		{"recovers", config.NotificationJSON, []webhookResponse{{status: http.StatusBadGateway}, {status: http.StatusOK}}, 2, false},
+		{"bad request", config.NotificationJSON, []webhookResponse{{status: http.StatusBadRequest}}, 1, true},
+		{"not found", config.NotificationSlack, []webhookResponse{{status: http.StatusNotFound}}, 1, true},
+		{"dingtalk rate limited", config.NotificationDingTalk, []webhookResponse{
+			{status: http.StatusOK, body: `{"errcode":130101,"errmsg":"send too fast"}`},
+			{status: http.StatusOK, body: `{"errcode":0,"errmsg":"ok"}`}}, 2, false},
+		{"dingtalk invalid token", config.NotificationDingTalk, []webhookResponse{
+			{status: http.StatusOK, body: `{"errcode":300001,"errmsg":"token is not exist"}`}}, 1, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hook := newWebhook(t, tt.responses...)
+			n := config.Notification{Type: tt.format, URL: hook.URL}
+			err := notify(t.Context(), n, testSummary())
+			if (err != nil) != tt.wantErr {
+				t.Errorf("notify() error = %v, want error %v", err, tt.wantErr)
+			}
+			if calls := len(hook.received()); calls != tt.calls {
+				t.Errorf("webhook called %d times, want %d", calls, tt.calls)
+			}
+		})
+	}
+}
+
+func TestNotifyContinuesAfterFailure(t *testing.T) {
+	failing := newWebhook(t, webhookResponse{status: http.StatusInternalServerError})
+	working := newWebhook(t)
+	Notify(t.Context(), []config.Notification{
+		{URL: failing.URL, Attempts: 2},
+		{URL: working.URL},
+	}, testSummary())
+
+	if calls := len(failing.received()); calls != 2 {
+		t.Errorf("failing webhook called %d times, want 2", calls)
+	}
+	if calls := len(working.received()); calls != 1 {
+		t.Errorf("webhook after the failing one called %d times, want 1", calls)
+	}
+}
+
+func TestNotifyLogsNoWebhookToken(t *testing.T) {
+	var logs bytes.Buffer
+	old := slog.Default()
+	slog.SetDefault(slog.New(slog.NewTextHandler(&logs, nil)))
+	t.Cleanup(func() { slog.SetDefault(old) })
+
+	// A closed server makes every attempt fail with a connection error
+	hook := newWebhook(t)
+	hook.Close()
+	Notify(t.Context(), []config.Notification{
+		{Type: config.NotificationSlack, URL: hook.URL + "/services/T000/B000/slack-secret", Attempts: 2},
+		{Type: config.NotificationDingTalk, URL: hook.URL + "/robot/send?access_token=dingtalk-token", Secret: "SEC-secret", Attempts: 2},
+	}, testSummary())
+
+	output := logs.String()
+	if !strings.Contains(output, "notification failed") || !strings.Contains(output, strings.TrimPrefix(hook.URL, "http://")) {
+		t.Fatalf("logs = %q, want failed notifications naming the host", output)
+	}
+	for _, secret := range []string{"slack-secret", "dingtalk-token", "sign=", "timestamp="} {
+		if strings.Contains(output, secret) {
+			t.Errorf("logs contain %q: %s", secret, output)
+		}
+	}
+}
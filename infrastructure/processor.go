@@ -3,54 +3,99 @@ package infrastructure
 import (
 	"context"
 	"fmt"
+	"io"
+	"log/slog"
 	"sync"
 	"time"
 
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/arafato/ali-nuke/config"
 	"github.com/arafato/ali-nuke/types"
+	"github.com/arafato/ali-nuke/utils"
 )
 
-const (
-	maxWaves     = 60               // Max number of retry waves
-	waveInterval = 10 * time.Second // Time between waves
-	maxTotalTime = 10 * time.Minute // Total timeout
-)
+// verifyTimeout is the timeout for a single probe of a resource in Verifying state. It bounds
+// the API calls of the probe as well, their requests are bound to its context.
+const verifyTimeout = 30 * time.Second
 
-// RemoveCollection attempts to remove all resources using a wave-based approach.
-// Resources that fail with retriable errors (e.g., DependencyViolation) are retried
-// in subsequent waves until they succeed, permanently fail, or timeout is reached.
-func RemoveCollection(ctx context.Context, resources types.Resources) error {
+// RemoveCollection removes all Ready resources layer by layer, following the dependency
+// graph built by BuildDeletionPlan. Within a layer, resources that still fail with retriable
+// errors (e.g., an unexpected DependencyViolation) are retried in subsequent waves until they
+// succeed, permanently fail, or the wave/time budget shared by all layers is exhausted.
+// Wave count, intervals, timeouts and concurrency are taken from settings. Progress
+// messages between waves are written to w.
+//
+// Cancelling ctx stops scheduling further deletions. Deletions already in flight are allowed
+// to finish or time out, and RemoveCollection returns ctx.Err() leaving the resources that
+// were not attempted in Ready or PendingRetry state.
+func RemoveCollection(ctx context.Context, w io.Writer, resources types.Resources, settings config.Settings) error {
 	startTime := time.Now()
+	plan := BuildDeletionPlan(resources)
+	layerOf := plan.layerIndex()
+
+	wave := 0
+	for i := range plan.Layers {
+		var layer types.Resources
+		for _, resource := range resources {
+			if l, ok := layerOf[resource.ProductName]; ok && l == i && resource.State() == types.Ready {
+				layer = append(layer, resource)
+			}
+		}
+
+		if err := removeLayer(ctx, w, layer, settings, &wave, startTime); err != nil {
+			return err
+		}
+	}
+
+	// Anything not deleted by now ran out of wave or time budget
+	markUnfinishedAsFailed(resources)
+	return nil
+}
+
+// removeLayer deletes the resources of one dependency layer, retrying in waves
+func removeLayer(ctx context.Context, w io.Writer, layer types.Resources, settings config.Settings, wave *int, startTime time.Time) error {
+	for *wave < settings.MaxWaves {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
 
-	for wave := 1; wave <= maxWaves; wave++ {
 		// Check timeout
-		if time.Since(startTime) > maxTotalTime {
-			markPendingAsFailed(resources)
-			break
+		if time.Since(startTime) > settings.MaxTotalTime {
+			markPendingAsFailed(layer)
+			return nil
 		}
 
 		// Count resources to process this wave (Ready or PendingRetry)
-		toProcess := countProcessable(resources)
-		if toProcess == 0 {
-			break
+		if countProcessable(layer) == 0 {
+			return nil
 		}
+		*wave++
 
 		// Reset PendingRetry → Ready just before processing
-		resetPendingToReady(resources)
+		resetPendingToReady(layer)
+		slog.Info("starting deletion wave", "wave", *wave, "count", layer.NumOf(types.Ready))
+		utils.RecordWave()
 
-		// Run parallel deletion for this wave
-		runDeletionWave(ctx, resources)
+		// Run parallel deletion for this wave, then wait until asynchronous deletions are done
+		runDeletionWave(ctx, layer, settings, *wave)
+		if err := waitUntilGone(ctx, w, layer, settings, *wave, startTime); err != nil {
+			return err
+		}
 
 		// Check if any resources are pending retry
-		pendingCount := resources.NumOf(types.PendingRetry)
+		pendingCount := layer.NumOf(types.PendingRetry)
 		if pendingCount == 0 {
-			break
+			return nil
 		}
 
 		// Wait before next wave (resources stay in PendingRetry state during wait)
-		if wave < maxWaves && time.Since(startTime) < maxTotalTime {
-			fmt.Printf("\nWave %d: %d resources need retry, waiting %v...\n", wave, pendingCount, waveInterval)
+		if *wave < settings.MaxWaves && time.Since(startTime) < settings.MaxTotalTime {
+			fmt.Fprintf(w, "\nWave %d: %d resources need retry, waiting %v...\n", *wave, pendingCount, settings.WaveInterval)
 			select {
-			case <-time.After(waveInterval):
+			case <-time.After(settings.WaveInterval):
 			case <-ctx.Done():
 				return ctx.Err()
 			}
@@ -60,24 +105,133 @@ func RemoveCollection(ctx context.Context, resources types.Resources) error {
 	return nil
 }
 
-// runDeletionWave processes all Ready resources in parallel
-func runDeletionWave(ctx context.Context, resources types.Resources) {
+// runDeletionWave processes all Ready resources in parallel, at most DeleteConcurrency at a
+// time. Each deletion runs detached from ctx with its own timeout, so an interruption does not
+// abort calls already in flight.
+func runDeletionWave(ctx context.Context, resources types.Resources, settings config.Settings, wave int) {
 	var wg sync.WaitGroup
+	slots := make(chan struct{}, settings.DeleteConcurrency)
 
 	for _, resource := range resources {
 		if resource.State() != types.Ready {
 			continue
 		}
+		select {
+		case slots <- struct{}{}:
+		case <-ctx.Done():
+		}
+		if ctx.Err() != nil {
+			break
+		}
 		wg.Add(1)
 		go func(r *types.Resource) {
 			defer wg.Done()
-			r.Remove(ctx)
+			defer func() { <-slots }()
+			removeCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), settings.ResourceTimeout)
+			defer cancel()
+			remove(removeCtx, r, wave)
+			logOutcome(r)
 		}(resource)
 	}
 
 	wg.Wait()
 }
 
+// remove deletes a resource within a span and counts the error code of a failed attempt
+func remove(ctx context.Context, r *types.Resource, wave int) {
+	ctx, span := utils.Tracer().Start(ctx, "remove "+r.ProductName, trace.WithAttributes(
+		attribute.String(utils.LogKeyAccount, r.AccountID),
+		attribute.String(utils.LogKeyRegion, r.Region),
+		attribute.String(utils.LogKeyProduct, r.ProductName),
+		attribute.String(utils.LogKeyResourceID, r.ResourceID),
+		attribute.Int("wave", wave),
+	))
+	defer span.End()
+
+	r.Remove(ctx, wave)
+
+	span.SetAttributes(attribute.String("state", r.State().String()))
+	if lastErr := r.LastError(); lastErr != nil {
+		code := lastErr.Code
+		if code == "" {
+			code = lastErr.Category.String()
+		}
+		utils.RecordErrorCode(r.ProductName, code)
+		span.SetAttributes(
+			attribute.String(utils.LogKeyErrorCode, lastErr.Code),
+			attribute.String(utils.LogKeyRequestID, lastErr.RequestID),
+		)
+		span.SetStatus(codes.Error, lastErr.Message)
+	}
+}
+
+// waitUntilGone polls the resources in Verifying state until their deletion has finished,
+// so that dependent layers do not start while resources are still being torn down.
+// Resources still not gone when the total time budget is exhausted are marked as Failed.
+func waitUntilGone(ctx context.Context, w io.Writer, layer types.Resources, settings config.Settings, wave int, startTime time.Time) error {
+	announced := false
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		verifying := layer.NumOf(types.Verifying)
+		if verifying == 0 {
+			return nil
+		}
+		if time.Since(startTime) > settings.MaxTotalTime {
+			markVerifyingAsFailed(layer)
+			return nil
+		}
+		if !announced {
+			fmt.Fprintf(w, "\nWave %d: waiting for %d resources to be gone...\n", wave, verifying)
+			announced = true
+		}
+
+		select {
+		case <-time.After(settings.VerifyInterval):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+
+		var wg sync.WaitGroup
+		for _, resource := range layer {
+			if resource.State() != types.Verifying {
+				continue
+			}
+			wg.Add(1)
+			go func(r *types.Resource) {
+				defer wg.Done()
+				verifyCtx, cancel := context.WithTimeout(ctx, verifyTimeout)
+				defer cancel()
+				r.Verify(verifyCtx)
+				if r.State() != types.Verifying {
+					logOutcome(r)
+				}
+			}(resource)
+		}
+		wg.Wait()
+	}
+}
+
+// logOutcome logs the state a resource reached after a delete call or probe
+func logOutcome(r *types.Resource) {
+	attrs := utils.ResourceLogAttrs(r)
+	if lastErr := r.LastError(); lastErr != nil {
+		attrs = append(attrs, utils.ResourceErrorLogAttrs(lastErr)...)
+	}
+	switch r.State() {
+	case types.Deleted:
+		slog.Info("resource deleted", attrs...)
+	case types.Verifying:
+		slog.Info("resource deletion started", attrs...)
+	case types.PendingRetry:
+		slog.Info("resource deletion will be retried", attrs...)
+	case types.Failed:
+		slog.Error("resource deletion failed", attrs...)
+	}
+}
+
 // countProcessable returns the number of resources that can be processed (Ready or PendingRetry)
 func countProcessable(resources types.Resources) int {
 	count := 0
@@ -98,11 +252,29 @@ func resetPendingToReady(resources types.Resources) {
 	}
 }
 
+// markUnfinishedAsFailed marks all Ready and PendingRetry resources as Failed (used when the budget is exhausted)
+func markUnfinishedAsFailed(resources types.Resources) {
+	for _, r := range resources {
+		if r.State() == types.Ready || r.State() == types.PendingRetry {
+			r.Fail("not deleted, the wave or time budget was exhausted")
+		}
+	}
+}
+
+// markVerifyingAsFailed marks all Verifying resources as Failed (used on timeout)
+func markVerifyingAsFailed(resources types.Resources) {
+	for _, r := range resources {
+		if r.State() == types.Verifying {
+			r.Fail("deletion not confirmed within max-total-time")
+		}
+	}
+}
+
 // markPendingAsFailed marks all PendingRetry resources as Failed (used on timeout)
 func markPendingAsFailed(resources types.Resources) {
 	for _, r := range resources {
 		if r.State() == types.PendingRetry {
-			r.SetState(types.Failed)
+			r.Fail("not deleted within max-total-time")
 		}
 	}
 }
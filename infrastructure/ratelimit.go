@@ -0,0 +1,144 @@
+package infrastructure
+
+import (
+	"context"
+	"math"
+	"strings"
+	"sync"
+
+	"golang.org/x/time/rate"
+
+	"github.com/arafato/ali-nuke/types"
+	"github.com/arafato/ali-nuke/utils"
+)
+
+// defaultRateLimits holds the requests per second allowed per product and region. The values
+// stay below the per-account QPS limits Alibaba Cloud publishes for the Describe/Delete APIs.
+var defaultRateLimits = map[string]float64{
+	"ecs":       20,
+	"vpc":       20,
+	"oss":       50,
+	"cbn":       5,
+	"cs":        5,
+	"cr":        5,
+	"ess":       10,
+	"nas":       10,
+	"slb":       10,
+	"alb":       10,
+	"nlb":       10,
+	"rds":       10,
+	"r-kvstore": 10,
+	"dds":       10,
+	"polardb":   10,
+}
+
+// fallbackRateLimit applies to products without a default
+const fallbackRateLimit = 10
+
+// minRateLimitFraction bounds how far throttling can reduce a limit
+const minRateLimitFraction = 0.05
+
+var (
+	rateLimitersMu     sync.Mutex
+	rateLimiters       = make(map[string]*adaptiveLimiter)
+	rateLimitOverrides = make(map[string]float64)
+)
+
+// ConfigureRateLimits overrides the default requests per second. Keys are a product
+// ("ecs") or a product and region ("ecs/cn-hangzhou").
+func ConfigureRateLimits(overrides map[string]float64) {
+	rateLimitersMu.Lock()
+	defer rateLimitersMu.Unlock()
+
+	rateLimitOverrides = make(map[string]float64, len(overrides))
+	for key, limit := range overrides {
+		rateLimitOverrides[strings.ToLower(key)] = limit
+	}
+	rateLimiters = make(map[string]*adaptiveLimiter)
+}
+
+func init() {
+	utils.SetRequestLimiter(func(accountID, product, region string) types.CallLimiter {
+		return rateLimiterFor(accountID, product, region)
+	})
+}
+
+// rateLimiterFor returns the limiter shared by all requests to a product in a region of an
+// account. API rate limits apply per account, so every account gets its own limiter.
+func rateLimiterFor(accountID, product, region string) *adaptiveLimiter {
+	key := product + "/" + region
+
+	rateLimitersMu.Lock()
+	defer rateLimitersMu.Unlock()
+
+	if limiter, ok := rateLimiters[accountID+"/"+key]; ok {
+		return limiter
+	}
+
+	limit, ok := rateLimitOverrides[key]
+	if !ok {
+		limit, ok = rateLimitOverrides[product]
+	}
+	if !ok {
+		limit, ok = defaultRateLimits[product]
+	}
+	if !ok {
+		limit = fallbackRateLimit
+	}
+
+	limiter := newAdaptiveLimiter(limit)
+	rateLimiters[accountID+"/"+key] = limiter
+	return limiter
+}
+
+// adaptiveLimiter is a token bucket whose rate follows AIMD: it is halved whenever the API
+// reports throttling and grows back by a fixed step with every successful call.
+type adaptiveLimiter struct {
+	mu      sync.Mutex
+	limiter *rate.Limiter
+	max     float64
+	current float64
+}
+
+func newAdaptiveLimiter(limit float64) *adaptiveLimiter {
+	return &adaptiveLimiter{
+		limiter: rate.NewLimiter(rate.Limit(limit), int(math.Max(1, math.Ceil(limit)))),
+		max:     limit,
+		current: limit,
+	}
+}
+
+// Wait blocks until a call is allowed or ctx is cancelled
+func (l *adaptiveLimiter) Wait(ctx context.Context) error {
+	return l.limiter.Wait(ctx)
+}
+
+// Success increases the rate additively, up to the configured limit
+func (l *adaptiveLimiter) Success() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.current >= l.max {
+		return
+	}
+	l.current = math.Min(l.max, l.current+l.max*minRateLimitFraction)
+	l.limiter.SetLimit(rate.Limit(l.current))
+}
+
+// Throttled halves the rate, down to a small fraction of the configured limit
+func (l *adaptiveLimiter) Throttled() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.current = math.Max(l.max*minRateLimitFraction, l.current/2)
+	l.limiter.SetLimit(rate.Limit(l.current))
+}
+
+// Observe adjusts the rate according to the outcome of a call
+func (l *adaptiveLimiter) Observe(throttled bool) {
+	if throttled {
+		l.Throttled()
+	} else {
+		l.Success()
+	}
+}
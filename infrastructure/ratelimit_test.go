@@ -0,0 +1,78 @@
+package infrastructure
+
+import (
+	"context"
+	"testing"
+
+	"golang.org/x/time/rate"
+)
+
+func TestAdaptiveLimiterAIMD(t *testing.T) {
+	l := newAdaptiveLimiter(20)
+	check := func(step string, want float64) {
+		t.Helper()
+		if l.current != want || l.limiter.Limit() != rate.Limit(want) {
+			t.Errorf("%s: rate %v (limiter %v), want %v", step, l.current, l.limiter.Limit(), want)
+		}
+	}
+
+	l.Observe(false)
+	check("success at the limit", 20)
+
+	// Multiplicative decrease down to the floor of 5% of the limit
+	for _, want := range []float64{10, 5, 2.5, 1.25, 1, 1} {
+		l.Observe(true)
+		check("throttled", want)
+	}
+
+	// Additive increase by 5% of the limit up to the limit
+	for _, want := range []float64{2, 3, 4} {
+		l.Observe(false)
+		check("success", want)
+	}
+	for range 20 {
+		l.Observe(false)
+	}
+	check("recovered", 20)
+}
+
+func TestAdaptiveLimiterWaitCancelled(t *testing.T) {
+	l := newAdaptiveLimiter(1)
+	ctx, cancel := context.WithCancel(t.Context())
+	if err := l.Wait(ctx); err != nil {
+		t.Fatalf("first wait: %v, want the burst of 1 to pass", err)
+	}
+	cancel()
+	if err := l.Wait(ctx); err == nil {
+		t.Error("wait with a cancelled context succeeded")
+	}
+}
+
+func TestRateLimiterFor(t *testing.T) {
+	ConfigureRateLimits(map[string]float64{"ECS": 4, "ecs/cn-shanghai": 2})
+	t.Cleanup(func() { ConfigureRateLimits(nil) })
+
+	tests := []struct {
+		product, region string
+		want            float64
+	}{
+		{"ecs", "cn-shanghai", 2},
+		{"ecs", "cn-hangzhou", 4},
+		{"vpc", "cn-hangzhou", defaultRateLimits["vpc"]},
+		{"unknown", "cn-hangzhou", fallbackRateLimit},
+	}
+	for _, tt := range tests {
+		if got := rateLimiterFor("1", tt.product, tt.region).max; got != tt.want {
+			t.Errorf("%s/%s: limit %v, want %v", tt.product, tt.region, got, tt.want)
+		}
+	}
+
+	// Throttling in one account must not slow down another
+	first := rateLimiterFor("1", "ecs", "cn-hangzhou")
+	if rateLimiterFor("1", "ecs", "cn-hangzhou") != first {
+		t.Error("requests of an account to a product in a region do not share a limiter")
+	}
+	if rateLimiterFor("2", "ecs", "cn-hangzhou") == first {
+		t.Error("accounts share a limiter")
+	}
+}
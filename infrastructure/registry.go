@@ -0,0 +1,91 @@
+package infrastructure
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/arafato/ali-nuke/config"
+	"github.com/arafato/ali-nuke/types"
+)
+
+// Scope tells whether the resources of a type belong to a region or to the whole account
+type Scope string
+
+const (
+	// ScopeRegional types are collected in every scanned region
+	ScopeRegional Scope = "regional"
+	// ScopeGlobal types are collected once per account, through the endpoints of the global
+	// region of the settings. Their resources have the region GlobalRegion or, if they have one,
+	// their home region, in which case they are only kept if the home region is scanned.
+	ScopeGlobal Scope = "global"
+)
+
+// GlobalRegion is the region of resources that do not belong to any region
+const GlobalRegion = config.GlobalRegion
+
+// Descriptor describes a supported resource type
+type Descriptor struct {
+	Name        string                  `json:"name"`                  // Canonical type name, the ProductName of its resources
+	Product     string                  `json:"product"`               // API product; the types of a product share its rate limit
+	Scope       Scope                   `json:"scope"`                 // Regional unless set, see ScopeGlobal
+	Description string                  `json:"description"`           // What the type is
+	DependsOn   []string                `json:"dependsOn"`             // Types that must be gone before resources of this type can be deleted
+	SideEffects string                  `json:"sideEffects,omitempty"` // What else a deletion removes or changes
+	Collect     types.ResourceCollector `json:"-"`
+}
+
+// registry holds the descriptors of all supported resource types by canonical name
+var registry = make(map[string]*Descriptor)
+
+// Register adds a resource type. Every resource type registers itself, even when it has no
+// dependencies, so that dependencies on unknown types can be detected.
+func Register(d Descriptor) {
+	switch {
+	case d.Name == "" || d.Product == "" || d.Collect == nil:
+		panic(fmt.Errorf("resource type %q: name, product and collector are required", d.Name))
+	case registry[d.Name] != nil:
+		panic(fmt.Errorf("resource type %s already registered", d.Name))
+	}
+	if d.Scope == "" {
+		d.Scope = ScopeRegional
+	}
+	if d.DependsOn == nil {
+		d.DependsOn = []string{}
+	}
+	registry[d.Name] = &d
+}
+
+// Descriptors returns the descriptors of all resource types sorted by name
+func Descriptors() []Descriptor {
+	descriptors := make([]Descriptor, 0, len(registry))
+	for _, d := range registry {
+		descriptors = append(descriptors, *d)
+	}
+	slices.SortFunc(descriptors, func(a, b Descriptor) int { return strings.Compare(a.Name, b.Name) })
+	return descriptors
+}
+
+// ResourceTypes returns the canonical names of all resource types sorted alphabetically
+func ResourceTypes() []string {
+	names := make([]string, 0, len(registry))
+	for name := range registry {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+// LookupResourceType returns the descriptor of a resource type. Names are compared
+// case-insensitively, so that callers can suggest the canonical spelling.
+func LookupResourceType(name string) (*Descriptor, bool) {
+	if d, ok := registry[name]; ok {
+		return d, true
+	}
+	for canonical, d := range registry {
+		if strings.EqualFold(canonical, name) {
+			return d, true
+		}
+	}
+	return nil, false
+}
@@ -2,14 +2,24 @@ package main
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
-	"log"
+	"io"
+	"log/slog"
 	"os"
+	"os/signal"
+	"path/filepath"
+	"slices"
 	"strings"
 	"sync"
+	"syscall"
 	"time"
 
 	"github.com/briandowns/spinner"
+	"github.com/fatih/color"
+	"github.com/olekukonko/tablewriter"
+	"github.com/olekukonko/tablewriter/tw"
 
 	"github.com/arafato/ali-nuke/config"
 	"github.com/arafato/ali-nuke/infrastructure"
@@ -25,14 +35,41 @@ var (
 	configFile      string
 	accessKeyID     string
 	accessKeySecret string
+	securityToken   string
+	profile         string
+	roleArn         string
+	roleSessionName string
+	ecsRAMRole      string
 	noDryRun        bool
+	outputFormat    string
+	outputFile      string
+	planOut         string
+	planFile        string
+	runDir          string
+	resumeDir       string
 	shortVersion    bool
+	listOutput      string
+	logOptions      = utils.LogOptions{Level: "warn", Format: utils.LogFormatText}
+	logFile         string
+	closeLog        = func() error { return nil }
+	telemetryOpts   utils.TelemetryOptions
+	settings        = config.DefaultSettings()
 )
 
 var rootCmd = &cobra.Command{
 	Use:   "ali-nuke",
 	Short: "ali-nuke removes every resource from your Alibaba Cloud account",
 	Long:  `A tool which removes every resource from an Alibaba Cloud account. Use it with caution, since it cannot distinguish between production and non-production.`,
+
+	// main prints the error
+	SilenceErrors: true,
+
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		logOptions.File = logFile
+		var err error
+		closeLog, err = utils.SetupLogging(logOptions)
+		return err
+	},
 }
 
 var versionCmd = &cobra.Command{
@@ -54,127 +91,474 @@ var nukeCmd = &cobra.Command{
 Use with caution and review the dry-run output before executing.`,
 
 	PreRunE: func(cmd *cobra.Command, args []string) error {
-		if accessKeyID == "" {
-			return fmt.Errorf("--access-key-id is required")
+		if err := validateCredentialFlags(); err != nil {
+			return err
+		}
+		if resumeDir != "" && runDir != "" {
+			return fmt.Errorf("--resume and --run-dir cannot be combined, a resumed run continues its journal")
 		}
-		if accessKeySecret == "" {
-			return fmt.Errorf("--access-key-secret is required")
+		if !utils.ValidOutputFormat(outputFormat) {
+			return fmt.Errorf("--output must be one of %s", strings.Join(utils.OutputFormats, ", "))
 		}
 		return nil
 	},
-	Run: func(cmd *cobra.Command, args []string) {
-		executeNuke()
+	RunE: func(cmd *cobra.Command, args []string) error {
+		// Errors past this point are not caused by the command line
+		cmd.SilenceUsage = true
+		return executeNuke(cmd)
 	},
 }
 
+// listColumnWidth is the width at which the columns of the list table are wrapped
+const listColumnWidth = 40
+
 var listCmd = &cobra.Command{
 	Use:   "list",
 	Short: "List all supported resource types",
-	Long:  "List all resources types that are currently supported by this version of ali-nuke.",
-	Run: func(cmd *cobra.Command, args []string) {
-
-		collectors := infrastructure.ListCollectors()
-		for _, name := range collectors {
-			fmt.Println(name)
+	Long: `List all resources types that are currently supported by this version of ali-nuke.
+The names in the Type column are the ones to use in the config file.`,
+	PreRunE: func(cmd *cobra.Command, args []string) error {
+		if listOutput != utils.OutputTable && listOutput != utils.OutputJSON {
+			return fmt.Errorf("--output must be table or json")
+		}
+		return nil
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		descriptors := infrastructure.Descriptors()
+		if listOutput == utils.OutputJSON {
+			encoder := json.NewEncoder(os.Stdout)
+			encoder.SetIndent("", "  ")
+			return encoder.Encode(descriptors)
 		}
 
-		fmt.Println("Total:", len(collectors))
+		// Wrap the long columns, a single line per type does not fit the terminal
+		table := tablewriter.NewTable(os.Stdout, tablewriter.WithRowMaxWidth(listColumnWidth), tablewriter.WithRowAutoWrap(tw.WrapNormal))
+		table.Header([]string{"Type", "Product", "Scope", "Depends On", "Description", "Side Effects"})
+		for _, d := range descriptors {
+			table.Append([]string{d.Name, d.Product, string(d.Scope), strings.Join(d.DependsOn, ", "), d.Description, d.SideEffects})
+		}
+		table.Render()
+		fmt.Println("Total:", len(descriptors))
+		return nil
 	},
 }
 
 func init() {
 	rootCmd.AddCommand(nukeCmd)
+	rootCmd.AddCommand(applyCmd)
 	rootCmd.AddCommand(versionCmd)
 	rootCmd.AddCommand(listCmd)
+	rootCmd.AddCommand(configCmd)
+
+	rootCmd.PersistentFlags().StringVar(&logOptions.Level, "log-level", logOptions.Level, "Log level: debug, info, warn or error")
+	rootCmd.PersistentFlags().StringVar(&logOptions.Format, "log-format", logOptions.Format, "Log format: text or json")
+	rootCmd.PersistentFlags().StringVar(&logFile, "log-file", "", "Append the log to a file instead of stderr")
 
 	versionCmd.Flags().BoolVar(&shortVersion, "short", false, "Print short version string")
+	listCmd.Flags().StringVarP(&listOutput, "output", "o", utils.OutputTable, "Output format: table or json")
 
-	nukeCmd.Flags().StringVarP(&configFile, "config", "c", "", "Path to configuration file. If not provided no exclude filters are set.")
-	nukeCmd.Flags().StringVar(&accessKeyID, "access-key-id", "", "Alibaba Cloud Access Key ID (required)")
-	nukeCmd.Flags().StringVar(&accessKeySecret, "access-key-secret", "", "Alibaba Cloud Access Key Secret (required)")
+	nukeCmd.Flags().StringVarP(&configFile, "config", "c", "", "Path to configuration file. It must list the account in the accounts section.")
 	nukeCmd.Flags().BoolVar(&noDryRun, "no-dry-run", false, "Execute without dry run (actually delete resources)")
+	nukeCmd.Flags().StringVarP(&outputFormat, "output", "o", utils.OutputTable, "Format of the resource report: table, json, yaml or csv")
+	nukeCmd.Flags().StringVar(&outputFile, "output-file", "", "Write the resource report to a file instead of stdout")
+	nukeCmd.Flags().StringVar(&planOut, "plan-out", "", "Write the resources to be removed to a plan file for the apply command")
+	nukeCmd.Flags().StringVar(&runDir, "run-dir", "", "Directory for the journal of the deletion (default ali-nuke-run-<timestamp>)")
+	nukeCmd.Flags().StringVar(&resumeDir, "resume", "", "Resume the interrupted run whose journal is in this directory")
+	addCredentialFlags(nukeCmd)
+	addSettingsFlags(nukeCmd)
+	addTelemetryFlags(nukeCmd)
+
+	applyCmd.Flags().StringVar(&planFile, "plan", "", "Path to a plan file written by nuke --plan-out (required)")
+	applyCmd.MarkFlagRequired("plan")
+	addCredentialFlags(applyCmd)
+	addSettingsFlags(applyCmd)
+	addTelemetryFlags(applyCmd)
+}
+
+// addCredentialFlags adds the flags selecting the credentials to a command
+func addCredentialFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&accessKeyID, "access-key-id", "", "Alibaba Cloud Access Key ID. Prefer ALIBABA_CLOUD_ACCESS_KEY_ID or a profile, flags are visible in the process list.")
+	cmd.Flags().StringVar(&accessKeySecret, "access-key-secret", "", "Alibaba Cloud Access Key Secret. Prefer ALIBABA_CLOUD_ACCESS_KEY_SECRET or a profile.")
+	cmd.Flags().StringVar(&securityToken, "security-token", "", "STS security token for temporary access keys")
+	cmd.Flags().StringVar(&profile, "profile", "", "Profile from ~/.aliyun/config.json")
+	cmd.Flags().StringVar(&ecsRAMRole, "ecs-ram-role", "", "Use the RAM role attached to the ECS instance ali-nuke runs on")
+	cmd.Flags().StringVar(&roleArn, "role-arn", "", "ARN of a RAM role to assume with the resolved credentials")
+	cmd.Flags().StringVar(&roleSessionName, "role-session-name", "ali-nuke", "Session name used when assuming --role-arn")
+}
+
+// addSettingsFlags adds the flags overriding the settings section to a command
+func addSettingsFlags(cmd *cobra.Command) {
+	cmd.Flags().IntVar(&settings.MaxWaves, "max-waves", settings.MaxWaves, "Max number of deletion waves across all dependency layers")
+	cmd.Flags().DurationVar(&settings.WaveInterval, "wave-interval", settings.WaveInterval, "Time between deletion waves")
+	cmd.Flags().DurationVar(&settings.VerifyInterval, "verify-interval", settings.VerifyInterval, "Time between checks whether deleted resources are gone")
+	cmd.Flags().DurationVar(&settings.MaxTotalTime, "max-total-time", settings.MaxTotalTime, "Total time budget for the deletion")
+	cmd.Flags().DurationVar(&settings.ResourceTimeout, "resource-timeout", settings.ResourceTimeout, "Timeout for deleting a single resource, including retries")
+	cmd.Flags().IntVar(&settings.ScanConcurrency, "scan-concurrency", settings.ScanConcurrency, "Max number of collectors running in parallel")
+	cmd.Flags().IntVar(&settings.DeleteConcurrency, "delete-concurrency", settings.DeleteConcurrency, "Max number of deletions running in parallel")
+	cmd.Flags().StringVar(&settings.GlobalRegion, "global-region", settings.GlobalRegion, "Region whose endpoints are used to collect global resource types")
+}
+
+// addTelemetryFlags adds the flags selecting the metrics and tracing exports to a command
+func addTelemetryFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&telemetryOpts.MetricsListen, "metrics-listen", "", "Serve Prometheus metrics on this address while the run lasts, e.g. :9090")
+	cmd.Flags().StringVar(&telemetryOpts.MetricsPushURL, "metrics-pushgateway", "", "Push the metrics to this Prometheus pushgateway at the end of the run")
+	cmd.Flags().StringVar(&telemetryOpts.MetricsTextfile, "metrics-textfile", "", "Write the metrics to this file at the end of the run")
+	cmd.Flags().StringVar(&telemetryOpts.TraceEndpoint, "trace-endpoint", "", "Export OpenTelemetry spans to this OTLP/HTTP endpoint, e.g. http://localhost:4318")
+}
 
-	nukeCmd.MarkFlagRequired("access-key-id")
-	nukeCmd.MarkFlagRequired("access-key-secret")
+// validateCredentialFlags checks that the credential flags select a single source
+func validateCredentialFlags() error {
+	if (accessKeyID == "") != (accessKeySecret == "") {
+		return fmt.Errorf("--access-key-id and --access-key-secret must be set together")
+	}
+	if securityToken != "" && accessKeyID == "" {
+		return fmt.Errorf("--security-token requires --access-key-id and --access-key-secret")
+	}
+	explicit := 0
+	for _, set := range []bool{accessKeyID != "", profile != "", ecsRAMRole != ""} {
+		if set {
+			explicit++
+		}
+	}
+	if explicit > 1 {
+		return fmt.Errorf("only one of --access-key-id, --profile and --ecs-ram-role can be set")
+	}
+	return nil
 }
 
-func executeNuke() {
-	var cfg *config.Config
-	if configFile == "" {
-		c := config.NewConfig()
-		cfg = &c
-	} else {
+func executeNuke(cmd *cobra.Command) error {
+	startedAt := time.Now()
+
+	// Keep stdout clean for a machine-readable report, progress messages go to stderr instead
+	var out io.Writer = os.Stdout
+	if outputFormat != utils.OutputTable && outputFile == "" {
+		out = os.Stderr
+	}
+	if !isTerminal(out) {
+		color.NoColor = true
+	}
+
+	// A resumed run uses the configuration of the interrupted run unless another one is given
+	if resumeDir != "" && configFile == "" {
+		configFile = filepath.Join(resumeDir, infrastructure.RunConfigFileName)
+	}
+
+	cfg := config.NewConfig()
+	var cfgData []byte
+	if configFile != "" {
 		var err error
-		cfg, err = config.LoadConfig(configFile)
+		cfgData, err = os.ReadFile(configFile)
+		if err != nil {
+			return fmt.Errorf("loading configuration: %w", err)
+		}
+		parsed, err := validateConfig(cfgData, nil)
+		if err != nil {
+			return fmt.Errorf("loading configuration: %w", err)
+		}
+		cfg = *parsed
+	}
+
+	targets, err := prepareRun(out, cmd, &cfg)
+	if err != nil {
+		return err
+	}
+
+	// Check the regions as well before scanning, now that there are credentials to list them.
+	// Every account is checked, the regions available may differ between them.
+	if cfgData != nil && len(targets) > 0 {
+		accountRegions := make(map[string][]string)
+		for _, t := range targets {
+			regions, err := utils.FetchAllRegions(cmd.Context(), t.creds, cfg.Settings.GlobalRegion)
+			if err != nil {
+				return fmt.Errorf("fetching regions of account %s: %w", t.identity, err)
+			}
+			accountRegions[t.identity.AccountID] = regions
+		}
+		if _, err := validateConfig(cfgData, accountRegions); err != nil {
+			return fmt.Errorf("loading configuration: %w", err)
+		}
+	}
+
+	// Cancel on SIGINT/SIGTERM: scanning stops, no further deletions are scheduled and the
+	// summary is still printed. A second signal terminates the process immediately.
+	ctx, stop := notifyContext()
+	defer stop()
+
+	var resources types.Resources
+	stopTelemetry, err := startTelemetry(ctx)
+	if err != nil {
+		return err
+	}
+	defer func() { stopTelemetry(resources) }()
+	defer func() { notifyRun(&cfg, "nuke", !noDryRun, ctx.Err() != nil, targets, resources, startedAt) }()
+
+	if resources, err = scanTargets(ctx, out, targets); err != nil {
+		return err
+	}
+
+	if resumeDir != "" {
+		states, err := infrastructure.LoadJournal(resumeDir)
 		if err != nil {
-			log.Fatalf("Error loading configuration: %v", err)
+			return fmt.Errorf("resuming run: %w", err)
+		}
+		resumed := infrastructure.ResumeCollection(resources, states)
+		fmt.Fprintf(out, "Resuming run %s: %d resources left to remove, %d already deleted.\n",
+			resumeDir, resumed, resources.NumOf(types.Deleted))
+		utils.PrettyPrintStatus(out, resources)
+	}
+
+	// The report reflects the final state of the resources, whichever way the run ends
+	if outputFormat != utils.OutputTable || outputFile != "" {
+		defer writeReport(resources)
+	}
+
+	// Show the dependency-driven deletion order, including cycles and unknown edges
+	infrastructure.BuildDeletionPlan(resources).Print(out)
+
+	printLogSummary(out)
+
+	if planOut != "" {
+		if ctx.Err() != nil {
+			fmt.Fprintln(out, "Scan interrupted, no plan written.")
+		} else {
+			plan := utils.NewPlan(resources, version.GetVersion(), configFile, cfgData)
+			if err := plan.Write(planOut); err != nil {
+				return fmt.Errorf("writing plan: %w", err)
+			}
+			fmt.Fprintf(out, "Plan with %d resources written to %s, execute it with: ali-nuke apply --plan %s\n",
+				len(plan.Resources), planOut, planOut)
 		}
 	}
 
-	creds := &types.Credentials{
+	if !noDryRun {
+		fmt.Fprintln(out, "Dry run complete.")
+		return nil
+	}
+	if ctx.Err() != nil {
+		fmt.Fprintln(out, "Nuke operation aborted.")
+		return nil
+	}
+
+	// Typing the alias of every account guards against nuking the wrong account
+	fmt.Fprintln(out, "Executing actual nuke operation... do you really want to continue?")
+	if !confirmTargets(ctx, out, targets) {
+		fmt.Fprintln(out, "Nuke operation aborted.")
+		return nil
+	}
+	fmt.Fprintln(out, "Nuke operation confirmed.")
+
+	journal, err := startJournal(out, cfgData, resources)
+	if err != nil {
+		return err
+	}
+	defer journal.Close()
+
+	return removeResources(ctx, out, targets, resources, cfg.Settings)
+}
+
+// resolveCredentials resolves the credentials selected by the credential flags
+func resolveCredentials() (*types.Credentials, error) {
+	return utils.ResolveCredentials(utils.CredentialOptions{
 		AccessKeyID:     accessKeyID,
 		AccessKeySecret: accessKeySecret,
+		SecurityToken:   securityToken,
+		Profile:         profile,
+		ECSRAMRole:      ecsRAMRole,
+		RoleArn:         roleArn,
+		RoleSessionName: roleSessionName,
+	})
+}
+
+// startJournal opens the journal of the run directory, keeps a copy of the configuration
+// next to it and starts recording state transitions. A run that cannot be resumed should not
+// start, so the caller must not delete anything if it fails.
+func startJournal(out io.Writer, cfgData []byte, resources types.Resources) (*infrastructure.Journal, error) {
+	dir := resumeDir
+	if dir == "" {
+		dir = runDir
+	}
+	if dir == "" {
+		dir = "ali-nuke-run-" + time.Now().Format("20060102-150405")
 	}
 
-	// Dynamically fetch all regions and apply exclusions
-	fmt.Println("Fetching available regions...")
-	regions, err := utils.GetActiveRegions(creds, cfg.Regions.Excludes)
+	journal, err := infrastructure.OpenJournal(dir)
 	if err != nil {
-		log.Fatalf("Error fetching regions: %v", err)
+		return nil, fmt.Errorf("starting journal: %w", err)
 	}
+	if resumeDir == "" {
+		if err := os.WriteFile(filepath.Join(dir, infrastructure.RunConfigFileName), cfgData, 0o600); err != nil {
+			journal.Close()
+			return nil, fmt.Errorf("starting journal: %w", err)
+		}
+	}
+	if err := journal.Start(resources); err != nil {
+		journal.Close()
+		return nil, fmt.Errorf("starting journal: %w", err)
+	}
+	fmt.Fprintf(out, "Journal: %s, resume an interrupted run with: ali-nuke nuke --resume %s --no-dry-run\n", dir, dir)
+	return journal, nil
+}
+
+// prepareRun applies the settings flags and rate limits, resolves the credentials and returns
+// the accounts to run against
+func prepareRun(out io.Writer, cmd *cobra.Command, cfg *config.Config) ([]target, error) {
+	// Flags take precedence over the settings section of the config file
+	applySettingsFlags(cmd, &cfg.Settings)
+	if err := cfg.Settings.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid settings: %w", err)
+	}
+	fmt.Fprintln(out, "Settings:", cfg.Settings)
+
+	infrastructure.ConfigureRateLimits(cfg.RateLimits)
+
+	creds, err := resolveCredentials()
+	if err != nil {
+		return nil, fmt.Errorf("resolving credentials: %w", err)
+	}
+	fmt.Fprintln(out, "Credentials:", creds.Source)
+
+	// Make sure we are talking to the intended accounts before touching anything
+	targets, err := resolveTargets(cmd.Context(), out, cfg, creds)
+	if err != nil {
+		return nil, fmt.Errorf("refusing to run: %w", err)
+	}
+	for _, t := range targets {
+		fmt.Fprintf(out, "Account: %s, authenticated as %s\n", t.identity, t.identity.Arn)
+	}
+	return targets, nil
+}
+
+// startTelemetry starts the metrics and tracing exports selected by the flags. The returned
+// function records the final state of the resources and flushes the exports.
+func startTelemetry(ctx context.Context) (func(types.Resources), error) {
+	telemetryOpts.Version = version.GetVersion()
+	telemetry, err := utils.StartTelemetry(ctx, telemetryOpts)
+	if err != nil {
+		return nil, fmt.Errorf("starting telemetry: %w", err)
+	}
+	return func(resources types.Resources) {
+		utils.RecordResources(resources)
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		if err := telemetry.Shutdown(shutdownCtx); err != nil {
+			slog.Error("error exporting telemetry", utils.ErrorLogAttrs(err)...)
+		}
+	}, nil
+}
 
-	// Initialize logger for collecting warnings/errors
-	logger := utils.NewScanLogger()
+// notifyRun posts the summary of the run to the webhooks of the notifications section
+func notifyRun(cfg *config.Config, command string, dryRun, interrupted bool, targets []target, resources types.Resources, startedAt time.Time) {
+	if len(cfg.Notifications) == 0 {
+		return
+	}
+	var accounts, regions []string
+	for _, t := range targets {
+		accounts = append(accounts, t.identity.String())
+		for _, region := range t.regions {
+			if !slices.Contains(regions, region) {
+				regions = append(regions, region)
+			}
+		}
+	}
+	summary := infrastructure.NewRunSummary(command, dryRun, interrupted, accounts, regions, startedAt, resources)
+	// The run context may be cancelled already, notifications are sent regardless
+	infrastructure.Notify(context.Background(), cfg.Notifications, summary)
+}
 
-	// Start spinner animation
-	s := spinner.New(spinner.CharSets[33], 100*time.Millisecond)
-	s.Suffix = fmt.Sprintf(" Scanning %d regions (excluded %d)...", len(regions), len(cfg.Regions.Excludes))
-	s.Start()
+// notifyContext returns a context that is cancelled by the first SIGINT/SIGTERM. A second
+// signal terminates the process immediately.
+func notifyContext() (context.Context, context.CancelFunc) {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	go func() {
+		<-ctx.Done()
+		stop()
+	}()
+	return ctx, stop
+}
 
+// scanTargets collects and filters the resources of all accounts and prints the results
+func scanTargets(ctx context.Context, out io.Writer, targets []target) (types.Resources, error) {
 	scanStart := time.Now()
-	resources := infrastructure.ProcessCollection(creds, regions, logger)
-	infrastructure.FilterCollection(resources, cfg)
-	scanDuration := time.Since(scanStart)
+	var resources types.Resources
+	for i := range targets {
+		t := &targets[i]
+		if ctx.Err() != nil {
+			break
+		}
 
-	// Stop spinner before printing results
-	s.Stop()
+		regions := t.regions
+		if len(regions) == 0 {
+			// Dynamically fetch all regions and apply inclusions and exclusions
+			fmt.Fprintf(out, "Fetching available regions of account %s...\n", t.identity)
+			var err error
+			regions, err = activeRegions(ctx, t)
+			if err != nil {
+				return nil, fmt.Errorf("fetching regions of account %s: %w", t.identity, err)
+			}
+			t.regions = regions
+		}
 
-	visibleCount := resources.VisibleCount()
-	fmt.Printf("Scan complete in %s: Found %d resources in total. To be removed %d, Filtered %d\n",
-		formatDuration(scanDuration), visibleCount, resources.NumOf(types.Ready), resources.NumOf(types.Filtered))
-	utils.PrettyPrintStatus(resources)
+		// Start spinner animation, unless the output is redirected
+		s := spinner.New(spinner.CharSets[33], 100*time.Millisecond)
+		s.Suffix = fmt.Sprintf(" Scanning %d regions (excluded %d)...", len(regions), len(t.cfg.Regions.Excludes))
+		s.Writer = out
+		if isTerminal(out) {
+			s.Start()
+		}
 
-	// Flush logs to file and print summary if there were warnings/errors
-	if logger.HasEntries() {
-		if err := logger.Flush(); err != nil {
-			fmt.Fprintf(os.Stderr, "Warning: Failed to write log file: %v\n", err)
+		accountResources, err := infrastructure.ProcessCollection(ctx, t.creds, regions, t.cfg)
+		// Stop spinner before printing results
+		s.Stop()
+		if err != nil {
+			return nil, fmt.Errorf("scanning account %s: %w", t.identity, err)
 		}
-		logger.PrintSummary()
+		infrastructure.FilterCollection(accountResources, t.cfg)
+		resources = append(resources, accountResources...)
 	}
+	scanDuration := time.Since(scanStart)
 
-	if !noDryRun {
-		fmt.Println("Dry run complete.")
-		return
+	if ctx.Err() != nil {
+		fmt.Fprintln(out, "Scan interrupted, the results below are incomplete.")
 	}
 
-	fmt.Println("Executing actual nuke operation... do you really want to continue (yes/no)?")
-	var confirm string
-	fmt.Scanln(&confirm)
-	if confirm != "yes" {
-		fmt.Println("Nuke operation aborted.")
-		return
+	visibleCount := resources.VisibleCount()
+	fmt.Fprintf(out, "Scan complete in %s: Found %d resources in total. To be removed %d, Filtered %d\n",
+		formatDuration(scanDuration), visibleCount, resources.NumOf(types.Ready), resources.NumOf(types.Filtered))
+	utils.PrettyPrintStatus(out, resources)
+	if len(targets) > 1 {
+		printAccountSummary(out, targets, resources)
 	}
-	fmt.Println("Nuke operation confirmed.")
+	return resources, nil
+}
+
+// activeRegions returns the regions to scan in the account of the target. The global region
+// in includes only selects global resources, it is not a region to scan.
+func activeRegions(ctx context.Context, t *target) ([]string, error) {
+	includes := t.cfg.Regions.Includes
+	regional := slices.DeleteFunc(slices.Clone(includes), func(region string) bool {
+		return region == infrastructure.GlobalRegion
+	})
+	if len(includes) > 0 && len(regional) == 0 {
+		return []string{}, nil
+	}
+	return utils.GetActiveRegions(ctx, t.creds, t.cfg.Settings.GlobalRegion, regional, t.cfg.Regions.Excludes)
+}
 
+// removeResources deletes the Ready resources while printing the progress, then prints the
+// summary. It fails if resources could not be deleted, so that the run exits non-zero.
+func removeResources(ctx context.Context, out io.Writer, targets []target, resources types.Resources, s config.Settings) error {
 	var wg sync.WaitGroup
-	ctx, cancel := context.WithCancel(context.Background())
+	printCtx, cancel := context.WithCancel(context.Background())
 
 	// Start printer goroutine BEFORE removal to show progress during the operation
 	wg.Add(1)
-	go utils.PrintStatusWithContext(&wg, ctx, resources)
+	go utils.PrintStatusWithContext(&wg, printCtx, out, resources)
 
-	if err := infrastructure.RemoveCollection(ctx, resources); err != nil {
-		log.Printf("Error removing resources: %v", err)
+	err := infrastructure.RemoveCollection(ctx, out, resources, s)
+	if errors.Is(err, context.Canceled) {
+		fmt.Fprintln(out, "\nInterrupted, no further deletions were scheduled.")
+	} else if err != nil {
+		slog.Error("error removing resources", utils.ErrorLogAttrs(err)...)
 	}
 
 	// Cancel printer after removal completes, then wait for it to finish
@@ -185,17 +569,114 @@ func executeNuke() {
 	failedCount := resources.NumOf(types.Failed)
 	deletedCount := resources.NumOf(types.Deleted)
 
-	fmt.Println("\n" + strings.Repeat("=", 60))
-	fmt.Printf("Process finished. Deleted: %d, Failed: %d\n", deletedCount, failedCount)
+	fmt.Fprintln(out, "\n"+strings.Repeat("=", 60))
+	fmt.Fprintf(out, "Process finished. Deleted: %d, Failed: %d\n", deletedCount, failedCount)
+	if ctx.Err() != nil {
+		notProcessed := resources.NumOf(types.Ready) + resources.NumOf(types.PendingRetry)
+		fmt.Fprintf(out, "Interrupted before completion, %d resources were not processed, %d deletions were not verified.\n",
+			notProcessed, resources.NumOf(types.Verifying))
+	}
+	if len(targets) > 1 {
+		printAccountSummary(out, targets, resources)
+	}
 
 	if failedCount > 0 {
-		fmt.Println("\nFailed resources:")
+		fmt.Fprintln(out, "\nFailed resources:")
 		for _, resource := range resources {
-			if resource.State() == types.Failed {
-				fmt.Printf("  - [%s] %s: %s (%s)\n", resource.Region, resource.ProductName, resource.ResourceName, resource.ResourceID)
+			if resource.State() != types.Failed {
+				continue
+			}
+			fmt.Fprintf(out, "  - [%s %s] %s: %s (%s)\n", resource.AccountID, resource.Region, resource.ProductName, resource.ResourceName, resource.ResourceID)
+			if lastErr := resource.LastError(); lastErr != nil {
+				fmt.Fprintf(out, "      %s\n", lastErr)
 			}
 		}
-		fmt.Println("\nNote: Some resources may have failed due to dependencies. Run again to retry.")
+	}
+	printLogSummary(out)
+
+	if failedCount > 0 {
+		return fmt.Errorf("failed to delete %d resources", failedCount)
+	}
+	return nil
+}
+
+// printLogSummary points to the log file if warnings or errors were written to it
+func printLogSummary(out io.Writer) {
+	if logFile == "" {
+		return
+	}
+	if problems := utils.LoggedProblems(); problems > 0 {
+		fmt.Fprintf(out, "\n%d warnings/errors were logged to %s\n", problems, logFile)
+	}
+}
+
+// applySettingsFlags copies the settings flags that were set explicitly into s
+func applySettingsFlags(cmd *cobra.Command, s *config.Settings) {
+	flags := cmd.Flags()
+	if flags.Changed("max-waves") {
+		s.MaxWaves = settings.MaxWaves
+	}
+	if flags.Changed("wave-interval") {
+		s.WaveInterval = settings.WaveInterval
+	}
+	if flags.Changed("verify-interval") {
+		s.VerifyInterval = settings.VerifyInterval
+	}
+	if flags.Changed("max-total-time") {
+		s.MaxTotalTime = settings.MaxTotalTime
+	}
+	if flags.Changed("resource-timeout") {
+		s.ResourceTimeout = settings.ResourceTimeout
+	}
+	if flags.Changed("scan-concurrency") {
+		s.ScanConcurrency = settings.ScanConcurrency
+	}
+	if flags.Changed("delete-concurrency") {
+		s.DeleteConcurrency = settings.DeleteConcurrency
+	}
+	if flags.Changed("global-region") {
+		s.GlobalRegion = settings.GlobalRegion
+	}
+}
+
+// writeReport writes the resource report to --output-file, or to stdout if not set
+func writeReport(resources types.Resources) {
+	w := os.Stdout
+	if outputFile != "" {
+		f, err := os.Create(outputFile)
+		if err != nil {
+			slog.Error("error writing report", utils.ErrorLogAttrs(err)...)
+			return
+		}
+		defer f.Close()
+		w = f
+	}
+
+	if err := utils.WriteReport(w, outputFormat, resources); err != nil {
+		slog.Error("error writing report", utils.ErrorLogAttrs(err)...)
+	}
+}
+
+// isTerminal reports whether w is a terminal
+func isTerminal(w io.Writer) bool {
+	f, ok := w.(*os.File)
+	return ok && utils.IsTerminal(f)
+}
+
+// readConfirmation reads a line from stdin. It returns an empty string if ctx is cancelled first.
+func readConfirmation(ctx context.Context) string {
+	answer := make(chan string, 1)
+	go func() {
+		var line string
+		fmt.Scanln(&line)
+		answer <- line
+	}()
+
+	select {
+	case line := <-answer:
+		return line
+	case <-ctx.Done():
+		return ""
 	}
 }
 
@@ -213,7 +694,9 @@ func formatDuration(d time.Duration) string {
 }
 
 func main() {
-	if err := rootCmd.Execute(); err != nil {
+	err := rootCmd.Execute()
+	closeLog()
+	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 		os.Exit(1)
 	}
@@ -0,0 +1,209 @@
+package mockcloud
+
+// kindSpec describes a kind of resource in the inventory
+type kindSpec struct {
+	id     string // Field holding the ID
+	global bool   // Listed in every region
+}
+
+var kinds = map[string]kindSpec{
+	"ACKCluster":             {id: "cluster_id"},
+	"ALB":                    {id: "LoadBalancerId"},
+	"AutoSnapshotPolicy":     {id: "AutoSnapshotPolicyId"},
+	"CENInstance":            {id: "CenId", global: true},
+	"Command":                {id: "CommandId"},
+	"CommonBandwidthPackage": {id: "BandwidthPackageId"},
+	"ContainerRegistryRepo":  {id: "RepoId"},
+	"CRInstance":             {id: "InstanceId"},
+	"CustomerGateway":        {id: "CustomerGatewayId"},
+	"DeploymentSet":          {id: "DeploymentSetId"},
+	"Disk":                   {id: "DiskId"},
+	"ECSInstance":            {id: "InstanceId"},
+	"EIP":                    {id: "AllocationId"},
+	"ForwardEntry":           {id: "ForwardEntryId"},
+	"HaVip":                  {id: "HaVipId"},
+	"Image":                  {id: "ImageId"},
+	"KeyPair":                {id: "KeyPairName"},
+	"LaunchTemplate":         {id: "LaunchTemplateId"},
+	"MongoDBInstance":        {id: "DBInstanceId"},
+	"NASFileSystem":          {id: "FileSystemId"},
+	"NASMountTarget":         {id: "MountTargetDomain"},
+	"NatGateway":             {id: "NatGatewayId"},
+	"NetworkInterface":       {id: "NetworkInterfaceId"},
+	"NLB":                    {id: "LoadBalancerId"},
+	"OSSBucket":              {id: "Name"},
+	"PolarDBCluster":         {id: "DBClusterId"},
+	"RDSInstance":            {id: "DBInstanceId"},
+	"RedisInstance":          {id: "InstanceId"},
+	"RouteTable":             {id: "RouteTableId"},
+	"RouterInterface":        {id: "RouterInterfaceId"},
+	"ScalingConfiguration":   {id: "ScalingConfigurationId"},
+	"ScalingGroup":           {id: "ScalingGroupId"},
+	"SecurityGroup":          {id: "SecurityGroupId"},
+	"SLB":                    {id: "LoadBalancerId"},
+	"Snapshot":               {id: "SnapshotId"},
+	"SnatEntry":              {id: "SnatEntryId"},
+	"SslVpnClientCert":       {id: "SslVpnClientCertId"},
+	"SslVpnServer":           {id: "SslVpnServerId"},
+	"TransitRouter":          {id: "TransitRouterId"},
+	"VPC":                    {id: "VpcId"},
+	"VpnConnection":          {id: "VpnConnectionId"},
+	"VpnGateway":             {id: "VpnGatewayId"},
+	"VSwitch":                {id: "VSwitchId"},
+}
+
+// paging describes how a list action is paginated. The zero value returns all items at once.
+type paging struct {
+	page  string // Page number parameter, starting at 1
+	size  string // Page size parameter
+	total string // Dotted path of the total count in the response, if returned
+	token bool   // NextToken pagination instead of page numbers
+}
+
+var (
+	byPageNumber  = paging{page: "PageNumber", size: "PageSize", total: "TotalCount"}
+	byRecordCount = paging{page: "PageNumber", size: "PageSize", total: "TotalRecordCount"}
+	byPageNo      = paging{page: "PageNo", size: "PageSize"}
+	byClusterPage = paging{page: "page_number", size: "page_size", total: "page_info.total_count"}
+	byNextToken   = paging{size: "MaxResults", total: "TotalCount", token: true}
+)
+
+// listAction lists the resources of a kind
+type listAction struct {
+	kind    string
+	items   string // Dotted path of the item list in the response
+	paging  paging
+	filters map[string]string // Request parameters filtering on a field; JSON arrays or comma-separated
+}
+
+// deleteAction deletes a resource
+type deleteAction struct {
+	kind  string
+	param string // Parameter holding the ID, or a JSON array of IDs
+	path  string // Path prefix of ROA actions that take the ID from the path instead
+}
+
+// listActions are keyed by product and action
+var listActions = map[string]listAction{
+	"ecs/DescribeInstances":               {kind: "ECSInstance", items: "Instances.Instance", paging: byPageNumber, filters: map[string]string{"InstanceIds": "InstanceId"}},
+	"ecs/DescribeDisks":                   {kind: "Disk", items: "Disks.Disk", paging: byPageNumber},
+	"ecs/DescribeSnapshots":               {kind: "Snapshot", items: "Snapshots.Snapshot", paging: byPageNumber},
+	"ecs/DescribeImages":                  {kind: "Image", items: "Images.Image", paging: byPageNumber},
+	"ecs/DescribeSecurityGroups":          {kind: "SecurityGroup", items: "SecurityGroups.SecurityGroup", paging: byPageNumber},
+	"ecs/DescribeNetworkInterfaces":       {kind: "NetworkInterface", items: "NetworkInterfaceSets.NetworkInterfaceSet", paging: byPageNumber},
+	"ecs/DescribeKeyPairs":                {kind: "KeyPair", items: "KeyPairs.KeyPair", paging: byPageNumber},
+	"ecs/DescribeLaunchTemplates":         {kind: "LaunchTemplate", items: "LaunchTemplateSets.LaunchTemplateSet", paging: byPageNumber},
+	"ecs/DescribeAutoSnapshotPolicyEx":    {kind: "AutoSnapshotPolicy", items: "AutoSnapshotPolicies.AutoSnapshotPolicy", paging: byPageNumber},
+	"ecs/DescribeCommands":                {kind: "Command", items: "Commands.Command", paging: byPageNumber},
+	"ecs/DescribeDeploymentSets":          {kind: "DeploymentSet", items: "DeploymentSets.DeploymentSet", paging: byPageNumber},
+	"vpc/DescribeVpcs":                    {kind: "VPC", items: "Vpcs.Vpc", paging: byPageNumber},
+	"vpc/DescribeVSwitches":               {kind: "VSwitch", items: "VSwitches.VSwitch", paging: byPageNumber},
+	"vpc/DescribeRouteTableList":          {kind: "RouteTable", items: "RouterTableList.RouterTableListType", paging: byPageNumber},
+	"vpc/DescribeRouterInterfaces":        {kind: "RouterInterface", items: "RouterInterfaceSet.RouterInterfaceType", paging: byPageNumber},
+	"vpc/DescribeNatGateways":             {kind: "NatGateway", items: "NatGateways.NatGateway", paging: byPageNumber, filters: map[string]string{"NatGatewayId": "NatGatewayId"}},
+	"vpc/DescribeEipAddresses":            {kind: "EIP", items: "EipAddresses.EipAddress", paging: byPageNumber},
+	"vpc/DescribeCommonBandwidthPackages": {kind: "CommonBandwidthPackage", items: "CommonBandwidthPackages.CommonBandwidthPackage", paging: byPageNumber},
+	"vpc/DescribeForwardTableEntries":     {kind: "ForwardEntry", items: "ForwardTableEntries.ForwardTableEntry", paging: byPageNumber, filters: map[string]string{"ForwardTableId": "ForwardTableId"}},
+	"vpc/DescribeSnatTableEntries":        {kind: "SnatEntry", items: "SnatTableEntries.SnatTableEntry", paging: byPageNumber, filters: map[string]string{"SnatTableId": "SnatTableId"}},
+	"vpc/DescribeHaVips":                  {kind: "HaVip", items: "HaVips.HaVip", paging: byPageNumber},
+	"vpc/DescribeVpnGateways":             {kind: "VpnGateway", items: "VpnGateways.VpnGateway", paging: byPageNumber},
+	"vpc/DescribeVpnConnections":          {kind: "VpnConnection", items: "VpnConnections.VpnConnection", paging: byPageNumber},
+	"vpc/DescribeCustomerGateways":        {kind: "CustomerGateway", items: "CustomerGateways.CustomerGateway", paging: byPageNumber},
+	"vpc/DescribeSslVpnServers":           {kind: "SslVpnServer", items: "SslVpnServers.SslVpnServer", paging: byPageNumber},
+	"vpc/DescribeSslVpnClientCerts":       {kind: "SslVpnClientCert", items: "SslVpnClientCertKeys.SslVpnClientCertKey", paging: byPageNumber},
+	"nas/DescribeFileSystems":             {kind: "NASFileSystem", items: "FileSystems.FileSystem", paging: byPageNumber},
+	"nas/DescribeMountTargets":            {kind: "NASMountTarget", items: "MountTargets.MountTarget", paging: byPageNumber, filters: map[string]string{"FileSystemId": "FileSystemId"}},
+	"ess/DescribeScalingGroups":           {kind: "ScalingGroup", items: "ScalingGroups", paging: byPageNumber},
+	"ess/DescribeScalingConfigurations":   {kind: "ScalingConfiguration", items: "ScalingConfigurations", paging: byPageNumber},
+	"cr/ListInstance":                     {kind: "CRInstance", items: "Instances", paging: byPageNo},
+	"cr/ListRepository":                   {kind: "ContainerRegistryRepo", items: "Repositories", paging: byPageNo, filters: map[string]string{"InstanceId": "InstanceId"}},
+	"cs/DescribeClustersV1":               {kind: "ACKCluster", items: "clusters", paging: byClusterPage, filters: map[string]string{"cluster_id": "cluster_id"}},
+	"cbn/DescribeCens":                    {kind: "CENInstance", items: "Cens.Cen", paging: byPageNumber},
+	"cbn/ListTransitRouters":              {kind: "TransitRouter", items: "TransitRouters", paging: byPageNumber, filters: map[string]string{"CenId": "CenId"}},
+	"slb/DescribeLoadBalancers":           {kind: "SLB", items: "LoadBalancers.LoadBalancer", paging: byPageNumber},
+	"alb/ListLoadBalancers":               {kind: "ALB", items: "LoadBalancers", paging: byNextToken},
+	"nlb/ListLoadBalancers":               {kind: "NLB", items: "LoadBalancers", paging: byNextToken},
+	"rds/DescribeDBInstances":             {kind: "RDSInstance", items: "Items.DBInstance", paging: byRecordCount, filters: map[string]string{"DBInstanceId": "DBInstanceId"}},
+	"r-kvstore/DescribeInstances":         {kind: "RedisInstance", items: "Instances.KVStoreInstance", paging: byPageNumber, filters: map[string]string{"InstanceIds": "InstanceId"}},
+	"dds/DescribeDBInstances":             {kind: "MongoDBInstance", items: "DBInstances.DBInstance", paging: byPageNumber, filters: map[string]string{"DBInstanceId": "DBInstanceId"}},
+	"polardb/DescribeDBClusters":          {kind: "PolarDBCluster", items: "Items.DBCluster", paging: byRecordCount, filters: map[string]string{"DBClusterIds": "DBClusterId"}},
+}
+
+// deleteActions are keyed by product and action
+var deleteActions = map[string]deleteAction{
+	"ecs/DeleteInstance":               {kind: "ECSInstance", param: "InstanceId"},
+	"ecs/DeleteDisk":                   {kind: "Disk", param: "DiskId"},
+	"ecs/DeleteSnapshot":               {kind: "Snapshot", param: "SnapshotId"},
+	"ecs/DeleteImage":                  {kind: "Image", param: "ImageId"},
+	"ecs/DeleteSecurityGroup":          {kind: "SecurityGroup", param: "SecurityGroupId"},
+	"ecs/DeleteNetworkInterface":       {kind: "NetworkInterface", param: "NetworkInterfaceId"},
+	"ecs/DeleteKeyPairs":               {kind: "KeyPair", param: "KeyPairNames"},
+	"ecs/DeleteLaunchTemplate":         {kind: "LaunchTemplate", param: "LaunchTemplateId"},
+	"ecs/DeleteAutoSnapshotPolicy":     {kind: "AutoSnapshotPolicy", param: "autoSnapshotPolicyId"},
+	"ecs/DeleteCommand":                {kind: "Command", param: "CommandId"},
+	"ecs/DeleteDeploymentSet":          {kind: "DeploymentSet", param: "DeploymentSetId"},
+	"vpc/DeleteVpc":                    {kind: "VPC", param: "VpcId"},
+	"vpc/DeleteVSwitch":                {kind: "VSwitch", param: "VSwitchId"},
+	"vpc/DeleteRouteTable":             {kind: "RouteTable", param: "RouteTableId"},
+	"vpc/DeleteRouterInterface":        {kind: "RouterInterface", param: "RouterInterfaceId"},
+	"vpc/DeleteNatGateway":             {kind: "NatGateway", param: "NatGatewayId"},
+	"vpc/ReleaseEipAddress":            {kind: "EIP", param: "AllocationId"},
+	"vpc/DeleteCommonBandwidthPackage": {kind: "CommonBandwidthPackage", param: "BandwidthPackageId"},
+	"vpc/DeleteForwardEntry":           {kind: "ForwardEntry", param: "ForwardEntryId"},
+	"vpc/DeleteSnatEntry":              {kind: "SnatEntry", param: "SnatEntryId"},
+	"vpc/DeleteHaVip":                  {kind: "HaVip", param: "HaVipId"},
+	"vpc/DeleteVpnGateway":             {kind: "VpnGateway", param: "VpnGatewayId"},
+	"vpc/DeleteVpnConnection":          {kind: "VpnConnection", param: "VpnConnectionId"},
+	"vpc/DeleteCustomerGateway":        {kind: "CustomerGateway", param: "CustomerGatewayId"},
+	"vpc/DeleteSslVpnServer":           {kind: "SslVpnServer", param: "SslVpnServerId"},
+	"vpc/DeleteSslVpnClientCert":       {kind: "SslVpnClientCert", param: "SslVpnClientCertId"},
+	"nas/DeleteFileSystem":             {kind: "NASFileSystem", param: "FileSystemId"},
+	"nas/DeleteMountTarget":            {kind: "NASMountTarget", param: "MountTargetDomain"},
+	"ess/DeleteScalingGroup":           {kind: "ScalingGroup", param: "ScalingGroupId"},
+	"ess/DeleteScalingConfiguration":   {kind: "ScalingConfiguration", param: "ScalingConfigurationId"},
+	"cr/DeleteRepository":              {kind: "ContainerRegistryRepo", param: "RepoId"},
+	"cs/DeleteCluster":                 {kind: "ACKCluster", path: "/clusters/"},
+	"cbn/DeleteCen":                    {kind: "CENInstance", param: "CenId"},
+	"cbn/DeleteTransitRouter":          {kind: "TransitRouter", param: "TransitRouterId"},
+	"slb/DeleteLoadBalancer":           {kind: "SLB", param: "LoadBalancerId"},
+	"alb/DeleteLoadBalancer":           {kind: "ALB", param: "LoadBalancerId"},
+	"nlb/DeleteLoadBalancer":           {kind: "NLB", param: "LoadBalancerId"},
+	"rds/DeleteDBInstance":             {kind: "RDSInstance", param: "DBInstanceId"},
+	"r-kvstore/DeleteInstance":         {kind: "RedisInstance", param: "InstanceId"},
+	"dds/DeleteDBInstance":             {kind: "MongoDBInstance", param: "DBInstanceId"},
+	"polardb/DeleteDBCluster":          {kind: "PolarDBCluster", param: "DBClusterId"},
+}
+
+// noopActions succeed without changing anything, e.g. detaching before a deletion
+var noopActions = map[string]bool{
+	"ecs/DetachNetworkInterface":    true,
+	"vpc/UnassociateEipAddress":     true,
+	"vpc/DeactivateRouterInterface": true,
+	"ess/DisableScalingGroup":       true,
+}
+
+// customActions answer with a response that does not come from the inventory
+var customActions = map[string]func(s *Server, req *apiRequest) map[string]any{
+	"ecs/DescribeRegions": func(s *Server, _ *apiRequest) map[string]any {
+		regions := make([]any, 0, len(s.opts.Regions))
+		for _, region := range s.opts.Regions {
+			regions = append(regions, map[string]any{"RegionId": region, "LocalName": region})
+		}
+		return map[string]any{"Regions": map[string]any{"Region": regions}}
+	},
+	"sts/GetCallerIdentity": func(s *Server, _ *apiRequest) map[string]any {
+		return map[string]any{
+			"AccountId":    s.opts.AccountID,
+			"Arn":          "acs:ram::" + s.opts.AccountID + ":root",
+			"IdentityType": "Account",
+			"UserId":       s.opts.AccountID,
+		}
+	},
+	"ram/GetAccountAlias": func(s *Server, _ *apiRequest) map[string]any {
+		return map[string]any{"AccountAlias": s.opts.AccountAlias}
+	},
+	// Tags are part of the seeded items, the separate tag listing is always empty
+	"rds/ListTagResources": func(*Server, *apiRequest) map[string]any {
+		return map[string]any{"TagResources": map[string]any{"TagResource": []any{}}}
+	},
+}
@@ -0,0 +1,366 @@
+package mockcloud
+
+import (
+	"crypto/hmac"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// apiRequest is a decoded OpenAPI request
+type apiRequest struct {
+	product string
+	region  string
+	action  string
+	path    string
+	params  map[string]string // Query, form and JSON body parameters
+}
+
+// apiError is an error response of the OpenAPI
+type apiError struct {
+	status  int
+	code    string
+	message string
+}
+
+func (e *apiError) Error() string {
+	return e.code + ": " + e.message
+}
+
+// serveOpenAPI serves an RPC or ROA style request, signed with ACS3-HMAC-SHA256 or with the
+// HMAC-SHA1 query signature of older RPC clients
+func (s *Server) serveOpenAPI(w http.ResponseWriter, r *http.Request, product, region string) {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	query := r.URL.Query()
+	var form url.Values
+	if strings.HasPrefix(r.Header.Get("Content-Type"), "application/x-www-form-urlencoded") {
+		form, _ = url.ParseQuery(string(body))
+	}
+
+	req := &apiRequest{
+		product: product,
+		region:  region,
+		action:  r.Header.Get("x-acs-action"),
+		path:    r.URL.Path,
+		params:  make(map[string]string),
+	}
+	var apiErr *apiError
+	if query.Has("Signature") {
+		req.action = query.Get("Action")
+		apiErr = s.checkQuerySignature(r.Method, query, form)
+	} else {
+		apiErr = s.checkSignature(r, body)
+	}
+	if apiErr != nil {
+		s.problem("%s/%s: %s", product, req.action, apiErr)
+		s.writeJSON(w, apiErr.status, s.errorBody(apiErr))
+		return
+	}
+	if !s.knownRegion(region) {
+		apiErr := &apiError{http.StatusBadRequest, "InvalidRegionId", "The specified region does not exist."}
+		s.problem("%s/%s: region %s: %s", product, req.action, region, apiErr)
+		s.writeJSON(w, apiErr.status, s.errorBody(apiErr))
+		return
+	}
+
+	for _, values := range []url.Values{query, form} {
+		for key, value := range values {
+			req.params[key] = value[0]
+		}
+	}
+	if strings.HasPrefix(r.Header.Get("Content-Type"), "application/json") && len(body) > 0 {
+		var fields map[string]any
+		if json.Unmarshal(body, &fields) == nil {
+			for key, value := range fields {
+				req.params[key] = fmt.Sprint(value)
+			}
+		}
+	}
+
+	if s.stalled(req.action) {
+		<-r.Context().Done()
+		return
+	}
+
+	response, apiErr := s.dispatch(req)
+	if apiErr != nil {
+		s.writeJSON(w, apiErr.status, s.errorBody(apiErr))
+		return
+	}
+	s.mu.Lock()
+	response["RequestId"] = s.nextRequestID()
+	s.mu.Unlock()
+	s.writeJSON(w, http.StatusOK, response)
+}
+
+// checkSignature verifies the ACS3-HMAC-SHA256 signature of a request
+func (s *Server) checkSignature(r *http.Request, body []byte) *apiError {
+	const algorithm = "ACS3-HMAC-SHA256"
+	auth, ok := strings.CutPrefix(r.Header.Get("Authorization"), algorithm+" ")
+	if !ok {
+		return &apiError{http.StatusBadRequest, "IncompleteSignature", "The request is not signed with " + algorithm + "."}
+	}
+	fields := make(map[string]string)
+	for _, field := range strings.Split(auth, ",") {
+		key, value, _ := strings.Cut(field, "=")
+		fields[key] = value
+	}
+	if fields["Credential"] != s.opts.AccessKeyID {
+		return errUnknownAccessKey
+	}
+
+	payloadHash := sha256.Sum256(body)
+	if r.Header.Get("x-acs-content-sha256") != hex.EncodeToString(payloadHash[:]) {
+		return &apiError{http.StatusBadRequest, "SignatureDoesNotMatch", "The payload hash does not match."}
+	}
+
+	var headers strings.Builder
+	signedHeaders := strings.Split(fields["SignedHeaders"], ";")
+	for _, name := range signedHeaders {
+		value := r.Header.Get(name)
+		if name == "host" {
+			value = r.Host
+		}
+		headers.WriteString(name + ":" + strings.TrimSpace(value) + "\n")
+	}
+
+	canonicalRequest := strings.Join([]string{
+		r.Method,
+		escapeSigned(r.URL.EscapedPath()),
+		canonicalQuery(r.URL.Query()),
+		headers.String(),
+		strings.Join(signedHeaders, ";"),
+		hex.EncodeToString(payloadHash[:]),
+	}, "\n")
+	requestHash := sha256.Sum256([]byte(canonicalRequest))
+	mac := hmac.New(sha256.New, []byte(s.opts.AccessKeySecret))
+	mac.Write([]byte(algorithm + "\n" + hex.EncodeToString(requestHash[:])))
+	if !hmac.Equal([]byte(fields["Signature"]), []byte(hex.EncodeToString(mac.Sum(nil)))) {
+		return errSignatureMismatch
+	}
+	return nil
+}
+
+// checkQuerySignature verifies the HMAC-SHA1 signature of an RPC request, which covers the
+// query and form parameters
+func (s *Server) checkQuerySignature(method string, query, form url.Values) *apiError {
+	if query.Get("AccessKeyId") != s.opts.AccessKeyID {
+		return errUnknownAccessKey
+	}
+	signed := url.Values{}
+	for _, values := range []url.Values{query, form} {
+		for key, value := range values {
+			if key != "Signature" {
+				signed.Set(key, value[0])
+			}
+		}
+	}
+
+	stringToSign := method + "&%2F&" + url.QueryEscape(escapeSigned(signed.Encode()))
+	mac := hmac.New(sha1.New, []byte(s.opts.AccessKeySecret+"&"))
+	mac.Write([]byte(stringToSign))
+	if !hmac.Equal([]byte(query.Get("Signature")), []byte(base64.StdEncoding.EncodeToString(mac.Sum(nil)))) {
+		return errSignatureMismatch
+	}
+	return nil
+}
+
+var (
+	errUnknownAccessKey  = &apiError{http.StatusNotFound, "InvalidAccessKeyId.NotFound", "Specified access key is not found."}
+	errSignatureMismatch = &apiError{http.StatusBadRequest, "SignatureDoesNotMatch", "The request signature does not conform to Aliyun standards."}
+)
+
+// canonicalQuery returns the sorted and encoded query string that is signed
+func canonicalQuery(query url.Values) string {
+	keys := make([]string, 0, len(query))
+	for key := range query {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+
+	pairs := make([]string, len(keys))
+	for i, key := range keys {
+		pairs[i] = key + "=" + url.QueryEscape(query.Get(key))
+	}
+	return escapeSigned(strings.Join(pairs, "&"))
+}
+
+// escapeSigned applies the percent-encoding differences of the signature to a URL encoded string
+func escapeSigned(s string) string {
+	return strings.NewReplacer("+", "%20", "*", "%2A", "%7E", "~").Replace(s)
+}
+
+// dispatch runs the action of a request
+func (s *Server) dispatch(req *apiRequest) (map[string]any, *apiError) {
+	key := req.product + "/" + req.action
+
+	s.mu.Lock()
+	throttled := s.call(req.action)
+	s.mu.Unlock()
+	if throttled {
+		return nil, &apiError{http.StatusBadRequest, "Throttling.User", "Request was denied due to user flow control."}
+	}
+
+	if list, ok := listActions[key]; ok {
+		return s.list(req, list), nil
+	}
+	if del, ok := deleteActions[key]; ok {
+		return s.delete(req, del)
+	}
+	if noopActions[key] {
+		return map[string]any{}, nil
+	}
+	if custom, ok := customActions[key]; ok {
+		return custom(s, req), nil
+	}
+
+	s.problem("unknown action %s", key)
+	return nil, &apiError{http.StatusNotFound, "InvalidAction.NotFound", "Specified api is not found, please check your url and method."}
+}
+
+// list returns a page of the resources of a kind in the region of the request
+func (s *Server) list(req *apiRequest, action listAction) map[string]any {
+	s.mu.Lock()
+	var items []any
+	for _, r := range s.records {
+		if r.kind == action.kind && r.visibleIn(req.region) && matchesFilters(r, req.params, action.filters) {
+			items = append(items, r.fields)
+		}
+	}
+	s.mu.Unlock()
+
+	response := make(map[string]any)
+	p := action.paging
+	if p.total != "" {
+		setPath(response, p.total, len(items))
+	}
+
+	if p.size != "" {
+		size := s.opts.MaxPageSize
+		if n, err := strconv.Atoi(req.params[p.size]); err == nil && n > 0 {
+			size = min(n, size)
+		} else {
+			size = min(10, size)
+		}
+
+		start := 0
+		if p.token {
+			start, _ = strconv.Atoi(req.params["NextToken"])
+		} else if n, err := strconv.Atoi(req.params[p.page]); err == nil && n > 1 {
+			start = (n - 1) * size
+		}
+		start = min(start, len(items))
+		end := min(start+size, len(items))
+		if p.token && end < len(items) {
+			response["NextToken"] = strconv.Itoa(end)
+		}
+		items = items[start:end]
+	}
+
+	if items == nil {
+		items = []any{}
+	}
+	setPath(response, action.items, items)
+	return response
+}
+
+// matchesFilters reports whether a record matches the filter parameters set in a request
+func matchesFilters(r *record, params map[string]string, filters map[string]string) bool {
+	for param, field := range filters {
+		value, ok := params[param]
+		if !ok || value == "" {
+			continue
+		}
+		id, _ := r.fields[field].(string)
+		if !slices.Contains(splitIDs(value), id) {
+			return false
+		}
+	}
+	return true
+}
+
+// delete removes the resources named in a request, unless a block applies
+func (s *Server) delete(req *apiRequest, action deleteAction) (map[string]any, *apiError) {
+	var ids []string
+	if action.path != "" {
+		id, ok := strings.CutPrefix(req.path, action.path)
+		if ok && id != "" {
+			ids = []string{id}
+		}
+	} else if value := req.params[action.param]; value != "" {
+		ids = splitIDs(value)
+	}
+	if len(ids) == 0 {
+		s.problem("%s/%s: missing %s", req.product, req.action, action.param)
+		return nil, &apiError{http.StatusBadRequest, "MissingParameter", "The input parameter " + action.param + " that is mandatory for processing this request is not supplied."}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, id := range ids {
+		i := slices.IndexFunc(s.records, func(r *record) bool {
+			return r.kind == action.kind && r.id() == id && r.visibleIn(req.region)
+		})
+		if i < 0 {
+			return nil, &apiError{http.StatusNotFound, "InvalidResourceId.NotFound", "The specified resource " + id + " does not exist."}
+		}
+		if code := s.blocked(id); code != "" {
+			return nil, &apiError{http.StatusBadRequest, code, "The resource " + id + " is still in use."}
+		}
+	}
+	s.records = slices.DeleteFunc(s.records, func(r *record) bool {
+		return r.kind == action.kind && slices.Contains(ids, r.id()) && r.visibleIn(req.region)
+	})
+	return map[string]any{}, nil
+}
+
+// splitIDs splits a JSON array or comma-separated list of IDs
+func splitIDs(value string) []string {
+	var ids []string
+	if strings.HasPrefix(value, "[") && json.Unmarshal([]byte(value), &ids) == nil {
+		return ids
+	}
+	return strings.Split(value, ",")
+}
+
+// setPath sets a value at a dotted path of nested maps
+func setPath(m map[string]any, path string, value any) {
+	keys := strings.Split(path, ".")
+	for _, key := range keys[:len(keys)-1] {
+		child, ok := m[key].(map[string]any)
+		if !ok {
+			child = make(map[string]any)
+			m[key] = child
+		}
+		m = child
+	}
+	m[keys[len(keys)-1]] = value
+}
+
+// errorBody returns the response body of an error
+func (s *Server) errorBody(e *apiError) map[string]any {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return map[string]any{"Code": e.code, "Message": e.message, "RequestId": s.nextRequestID()}
+}
+
+// writeJSON writes a JSON response
+func (s *Server) writeJSON(w http.ResponseWriter, status int, body map[string]any) {
+	w.Header().Set("Content-Type", "application/json;charset=utf-8")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(body)
+}
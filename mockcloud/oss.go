@@ -0,0 +1,284 @@
+package mockcloud
+
+import (
+	"encoding/xml"
+	"io"
+	"net/http"
+	"slices"
+	"strings"
+)
+
+// ossError is the XML error response of OSS
+type ossError struct {
+	XMLName   xml.Name `xml:"Error"`
+	Code      string   `xml:"Code"`
+	Message   string   `xml:"Message"`
+	RequestID string   `xml:"RequestId"`
+	status    int
+}
+
+// serveOSS serves the OSS operations used to list, empty and delete buckets. Buckets are
+// addressed path-style. Signatures are not verified, only the access key is checked.
+func (s *Server) serveOSS(w http.ResponseWriter, r *http.Request, region string) {
+	bucket, key, _ := strings.Cut(strings.TrimPrefix(r.URL.Path, "/"), "/")
+	query := r.URL.Query()
+
+	var action string
+	switch {
+	case bucket == "" && r.Method == http.MethodGet:
+		action = "ListBuckets"
+	case key == "" && r.Method == http.MethodGet && query.Has("tagging"):
+		action = "GetBucketTags"
+	case key == "" && r.Method == http.MethodGet && query.Has("versions"):
+		action = "ListObjectVersions"
+	case key == "" && r.Method == http.MethodGet && query.Get("list-type") == "2":
+		action = "ListObjectsV2"
+	case key == "" && r.Method == http.MethodPost && query.Has("delete"):
+		action = "DeleteMultipleObjects"
+	case key == "" && r.Method == http.MethodDelete:
+		action = "DeleteBucket"
+	case key != "" && r.Method == http.MethodDelete:
+		action = "DeleteObject"
+	default:
+		s.problem("oss: unknown operation %s %s", r.Method, r.URL)
+		s.writeOSSError(w, &ossError{Code: "NotImplemented", Message: "The operation is not implemented.", status: http.StatusNotImplemented})
+		return
+	}
+
+	if !strings.Contains(r.Header.Get("Authorization"), "Credential="+s.opts.AccessKeyID+"/") {
+		s.problem("oss/%s: unknown access key", action)
+		s.writeOSSError(w, &ossError{Code: "InvalidAccessKeyId", Message: "The OSS Access Key Id you provided does not exist in our records.", status: http.StatusForbidden})
+		return
+	}
+	if !s.knownRegion(region) {
+		s.problem("oss/%s: unknown region %s", action, region)
+		s.writeOSSError(w, &ossError{Code: "InvalidRegionId", Message: "The specified region does not exist.", status: http.StatusBadRequest})
+		return
+	}
+
+	s.mu.Lock()
+	throttled := s.call(action)
+	s.mu.Unlock()
+	if throttled {
+		s.writeOSSError(w, &ossError{Code: "Throttling.User", Message: "Request was denied due to user flow control.", status: http.StatusServiceUnavailable})
+		return
+	}
+
+	if action == "ListBuckets" {
+		s.listBuckets(w)
+		return
+	}
+	if ossErr := s.checkBucket(bucket, region); ossErr != nil {
+		s.writeOSSError(w, ossErr)
+		return
+	}
+
+	switch action {
+	case "GetBucketTags":
+		s.getBucketTags(w, bucket)
+	case "ListObjectsV2":
+		s.listObjects(w, bucket)
+	case "ListObjectVersions":
+		s.listObjectVersions(w, bucket)
+	case "DeleteMultipleObjects":
+		s.deleteObjects(w, r, bucket)
+	case "DeleteObject":
+		s.removeObjects(bucket, key)
+		w.WriteHeader(http.StatusNoContent)
+	case "DeleteBucket":
+		s.deleteBucket(w, bucket)
+	}
+}
+
+// bucketRecord returns the record of a bucket. s.mu must be held.
+func (s *Server) bucketRecord(name string) *record {
+	i := slices.IndexFunc(s.records, func(r *record) bool { return r.kind == "OSSBucket" && r.id() == name })
+	if i < 0 {
+		return nil
+	}
+	return s.records[i]
+}
+
+// checkBucket returns an error unless the bucket exists and is accessed through its region
+func (s *Server) checkBucket(name, region string) *ossError {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	b := s.bucketRecord(name)
+	if b == nil {
+		return &ossError{Code: "NoSuchBucket", Message: "The specified bucket does not exist.", status: http.StatusNotFound}
+	}
+	if b.region != region {
+		return &ossError{Code: "AccessDenied", Message: "The bucket you are attempting to access must be addressed using the specified endpoint.", status: http.StatusForbidden}
+	}
+	return nil
+}
+
+func (s *Server) listBuckets(w http.ResponseWriter) {
+	type bucket struct {
+		Name         string `xml:"Name"`
+		Location     string `xml:"Location"`
+		Region       string `xml:"Region"`
+		CreationDate string `xml:"CreationDate"`
+	}
+	result := struct {
+		XMLName     xml.Name `xml:"ListAllMyBucketsResult"`
+		IsTruncated bool     `xml:"IsTruncated"`
+		Buckets     []bucket `xml:"Buckets>Bucket"`
+	}{}
+
+	s.mu.Lock()
+	for _, r := range s.records {
+		if r.kind == "OSSBucket" {
+			result.Buckets = append(result.Buckets, bucket{
+				Name:         r.id(),
+				Location:     "oss-" + r.region,
+				Region:       r.region,
+				CreationDate: "2024-01-01T00:00:00.000Z",
+			})
+		}
+	}
+	s.mu.Unlock()
+	s.writeXML(w, http.StatusOK, result)
+}
+
+// getBucketTags returns the tags of the "Tags" field of the bucket, a map[string]string
+func (s *Server) getBucketTags(w http.ResponseWriter, name string) {
+	type tag struct {
+		Key   string `xml:"Key"`
+		Value string `xml:"Value"`
+	}
+	result := struct {
+		XMLName xml.Name `xml:"Tagging"`
+		Tags    []tag    `xml:"TagSet>Tag"`
+	}{}
+
+	s.mu.Lock()
+	if b := s.bucketRecord(name); b != nil {
+		tags, _ := b.fields["Tags"].(map[string]string)
+		for key, value := range tags {
+			result.Tags = append(result.Tags, tag{Key: key, Value: value})
+		}
+	}
+	s.mu.Unlock()
+	s.writeXML(w, http.StatusOK, result)
+}
+
+func (s *Server) listObjects(w http.ResponseWriter, name string) {
+	type object struct {
+		Key string `xml:"Key"`
+	}
+	result := struct {
+		XMLName     xml.Name `xml:"ListBucketResult"`
+		Name        string   `xml:"Name"`
+		IsTruncated bool     `xml:"IsTruncated"`
+		KeyCount    int      `xml:"KeyCount"`
+		Contents    []object `xml:"Contents"`
+	}{Name: name}
+
+	s.mu.Lock()
+	for _, key := range s.objects[name] {
+		result.Contents = append(result.Contents, object{Key: key})
+	}
+	s.mu.Unlock()
+	result.KeyCount = len(result.Contents)
+	s.writeXML(w, http.StatusOK, result)
+}
+
+// listObjectVersions lists the objects of an unversioned bucket, whose version ID is "null"
+func (s *Server) listObjectVersions(w http.ResponseWriter, name string) {
+	type version struct {
+		Key       string `xml:"Key"`
+		VersionID string `xml:"VersionId"`
+		IsLatest  bool   `xml:"IsLatest"`
+	}
+	result := struct {
+		XMLName     xml.Name  `xml:"ListVersionsResult"`
+		Name        string    `xml:"Name"`
+		IsTruncated bool      `xml:"IsTruncated"`
+		Versions    []version `xml:"Version"`
+	}{Name: name}
+
+	s.mu.Lock()
+	for _, key := range s.objects[name] {
+		result.Versions = append(result.Versions, version{Key: key, VersionID: "null", IsLatest: true})
+	}
+	s.mu.Unlock()
+	s.writeXML(w, http.StatusOK, result)
+}
+
+func (s *Server) deleteObjects(w http.ResponseWriter, r *http.Request, name string) {
+	var request struct {
+		Quiet   bool `xml:"Quiet"`
+		Objects []struct {
+			Key string `xml:"Key"`
+		} `xml:"Object"`
+	}
+	body, err := io.ReadAll(r.Body)
+	if err == nil {
+		err = xml.Unmarshal(body, &request)
+	}
+	if err != nil {
+		s.writeOSSError(w, &ossError{Code: "MalformedXML", Message: err.Error(), status: http.StatusBadRequest})
+		return
+	}
+
+	type deleted struct {
+		Key string `xml:"Key"`
+	}
+	result := struct {
+		XMLName xml.Name  `xml:"DeleteResult"`
+		Deleted []deleted `xml:"Deleted"`
+	}{}
+	for _, object := range request.Objects {
+		s.removeObjects(name, object.Key)
+		if !request.Quiet {
+			result.Deleted = append(result.Deleted, deleted{Key: object.Key})
+		}
+	}
+	s.writeXML(w, http.StatusOK, result)
+}
+
+// removeObjects deletes objects from a bucket
+func (s *Server) removeObjects(name string, keys ...string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.objects[name] = slices.DeleteFunc(s.objects[name], func(key string) bool { return slices.Contains(keys, key) })
+}
+
+func (s *Server) deleteBucket(w http.ResponseWriter, name string) {
+	s.mu.Lock()
+	var ossErr *ossError
+	if len(s.objects[name]) > 0 {
+		ossErr = &ossError{Code: "BucketNotEmpty", Message: "The bucket you tried to delete is not empty.", status: http.StatusConflict}
+	} else if code := s.blocked(name); code != "" {
+		ossErr = &ossError{Code: code, Message: "The bucket is still in use.", status: http.StatusConflict}
+	} else {
+		s.records = slices.DeleteFunc(s.records, func(r *record) bool { return r.kind == "OSSBucket" && r.id() == name })
+		delete(s.objects, name)
+	}
+	s.mu.Unlock()
+
+	if ossErr != nil {
+		s.writeOSSError(w, ossErr)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// writeOSSError writes an OSS error response
+func (s *Server) writeOSSError(w http.ResponseWriter, e *ossError) {
+	s.mu.Lock()
+	e.RequestID = s.nextRequestID()
+	s.mu.Unlock()
+	w.Header().Set("x-oss-request-id", e.RequestID)
+	s.writeXML(w, e.status, e)
+}
+
+// writeXML writes an XML response
+func (s *Server) writeXML(w http.ResponseWriter, status int, body any) {
+	w.Header().Set("Content-Type", "application/xml")
+	w.WriteHeader(status)
+	io.WriteString(w, xml.Header)
+	xml.NewEncoder(w).Encode(body)
+}
@@ -0,0 +1,263 @@
+// Package mockcloud is a fake Alibaba Cloud API server for offline tests. It serves the
+// OpenAPI actions (RPC and ROA style) and the OSS operations used by the resources package
+// from an in-memory inventory, with pagination, dependency violations, throttling and stalled calls.
+//
+// Point the SDK clients at it with utils.RedirectEndpoints(server.Addr()).
+package mockcloud
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// Options configures a Server
+type Options struct {
+	AccountID       string
+	AccountAlias    string // Returned by RAM GetAccountAlias, may be empty
+	AccessKeyID     string // The only access key accepted
+	AccessKeySecret string
+	Regions         []string // Returned by ECS DescribeRegions; requests for other regions fail
+	MaxPageSize     int      // Upper bound of the page size requested by clients, 0 for 100
+}
+
+// Server is a fake Alibaba Cloud endpoint for all products and regions. Requests are routed
+// by their host name, <product>.<region>.aliyuncs.com.
+type Server struct {
+	opts Options
+	http *httptest.Server
+
+	mu        sync.Mutex
+	records   []*record
+	objects   map[string][]string // Object keys by bucket name
+	blocks    map[string]block    // Deletions failing with a dependency error, by ID
+	throttles map[string]int      // Number of calls still to throttle, by action
+	stalls    map[string]bool     // Actions answered only once the client gives up
+	calls     map[string]int      // Number of calls, by action
+	problems  []string            // Requests the server could not or would not serve
+	requestID int
+}
+
+// record is a resource in the inventory
+type record struct {
+	kind   string
+	region string // Empty for kinds that are listed in every region
+	fields map[string]any
+}
+
+// block makes the deletion of a resource fail while other resources exist, or once if there
+// are no blockers
+type block struct {
+	code     string
+	blockers []string
+}
+
+// New starts a server. Close it when done.
+func New(opts Options) *Server {
+	if opts.MaxPageSize <= 0 {
+		opts.MaxPageSize = 100
+	}
+	s := &Server{
+		opts:      opts,
+		objects:   make(map[string][]string),
+		blocks:    make(map[string]block),
+		throttles: make(map[string]int),
+		stalls:    make(map[string]bool),
+		calls:     make(map[string]int),
+	}
+	s.http = httptest.NewServer(s)
+	return s
+}
+
+// Addr returns the host:port the server listens on
+func (s *Server) Addr() string {
+	return s.http.Listener.Addr().String()
+}
+
+// Close shuts the server down
+func (s *Server) Close() {
+	s.http.Close()
+}
+
+// Add puts a resource of a kind into the inventory of a region. Kinds are the resource types
+// of ali-nuke plus a few auxiliary ones like CRInstance. fields is the resource as returned
+// by the List or Describe action and must hold the ID field of the kind. Tags and other
+// nested values use the JSON layout of the product.
+func (s *Server) Add(region, kind string, fields map[string]any) {
+	spec, ok := kinds[kind]
+	if !ok {
+		panic(fmt.Sprintf("mockcloud: unknown kind %q", kind))
+	}
+	if _, ok := fields[spec.id].(string); !ok {
+		panic(fmt.Sprintf("mockcloud: %s without %s", kind, spec.id))
+	}
+	if spec.global {
+		region = ""
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.records = append(s.records, &record{kind: kind, region: region, fields: fields})
+}
+
+// AddObjects puts objects into an OSS bucket added with Add
+func (s *Server) AddObjects(bucket string, keys ...string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.objects[bucket] = append(s.objects[bucket], keys...)
+}
+
+// Block makes deleting the resource with the given ID fail with code, e.g.
+// "DependencyViolation", as long as any of the blocking resources exists. Without blockers
+// only the next attempt fails, which does not depend on the order of concurrent deletions.
+func (s *Server) Block(id, code string, blockers ...string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.blocks[id] = block{code: code, blockers: blockers}
+}
+
+// Throttle makes the next n calls of an action fail with Throttling.User
+func (s *Server) Throttle(action string, n int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.throttles[action] += n
+}
+
+// Stall makes the calls of an action hang until the client gives up, like a request lost on
+// the way to an overloaded endpoint
+func (s *Server) Stall(action string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.stalls[action] = true
+}
+
+// IDs returns the IDs of the resources of a kind still in the inventory
+func (s *Server) IDs(kind string) []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var ids []string
+	for _, r := range s.records {
+		if r.kind == kind {
+			ids = append(ids, r.id())
+		}
+	}
+	return ids
+}
+
+// Exists reports whether a resource is still in the inventory
+func (s *Server) Exists(kind, id string) bool {
+	return slices.Contains(s.IDs(kind), id)
+}
+
+// Calls returns how often an action was called, including failed calls
+func (s *Server) Calls(action string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.calls[action]
+}
+
+// Problems returns the requests that were rejected because of an unknown action or region,
+// a bad signature or a missing parameter. A test should expect none.
+func (s *Server) Problems() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return slices.Clone(s.problems)
+}
+
+// ServeHTTP routes a request by the product and region in its host name
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	product, region, ok := parseHost(r.Host)
+	if !ok {
+		http.Error(w, "unknown host "+r.Host, http.StatusNotFound)
+		s.problem("unknown host %s", r.Host)
+		return
+	}
+	if product == "oss" {
+		s.serveOSS(w, r, region)
+		return
+	}
+	s.serveOpenAPI(w, r, product, region)
+}
+
+// parseHost splits <product>.<region>.aliyuncs.com
+func parseHost(host string) (product, region string, ok bool) {
+	name, _, _ := strings.Cut(host, ":")
+	labels := strings.Split(name, ".")
+	if len(labels) != 4 || labels[2] != "aliyuncs" || labels[3] != "com" {
+		return "", "", false
+	}
+	return labels[0], labels[1], true
+}
+
+// knownRegion reports whether requests may be sent to a region
+func (s *Server) knownRegion(region string) bool {
+	return region == "global" || slices.Contains(s.opts.Regions, region)
+}
+
+// call counts a call of an action and reports whether it is to be throttled. s.mu must be held.
+func (s *Server) call(action string) (throttled bool) {
+	s.calls[action]++
+	if s.throttles[action] > 0 {
+		s.throttles[action]--
+		return true
+	}
+	return false
+}
+
+// stalled counts a call of an action and reports whether it is to be stalled. Calls that are
+// not stalled are counted by call.
+func (s *Server) stalled(action string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.stalls[action] {
+		return false
+	}
+	s.calls[action]++
+	return true
+}
+
+// blocked returns the error code if deleting id is blocked by an existing resource. s.mu must be held.
+func (s *Server) blocked(id string) string {
+	b, ok := s.blocks[id]
+	if !ok {
+		return ""
+	}
+	if len(b.blockers) == 0 {
+		delete(s.blocks, id)
+		return b.code
+	}
+	for _, r := range s.records {
+		if slices.Contains(b.blockers, r.id()) {
+			return b.code
+		}
+	}
+	return ""
+}
+
+// nextRequestID returns a new request ID. s.mu must be held.
+func (s *Server) nextRequestID() string {
+	s.requestID++
+	return fmt.Sprintf("MOCK-%08d", s.requestID)
+}
+
+// problem records a rejected request
+func (s *Server) problem(format string, args ...any) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.problems = append(s.problems, fmt.Sprintf(format, args...))
+}
+
+// id returns the ID of the record
+func (r *record) id() string {
+	id, _ := r.fields[kinds[r.kind].id].(string)
+	return id
+}
+
+// visibleIn reports whether the record is listed in a region
+func (r *record) visibleIn(region string) bool {
+	return r.region == "" || r.region == region
+}
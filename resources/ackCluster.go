@@ -1,6 +1,8 @@
 package resources
 
 import (
+	"context"
+
 	cs "github.com/alibabacloud-go/cs-20151215/v5/client"
 	"github.com/alibabacloud-go/tea/tea"
 
@@ -10,18 +12,24 @@ import (
 )
 
 func init() {
-	infrastructure.RegisterCollector("ackCluster", CollectACKClusters)
+	infrastructure.Register(infrastructure.Descriptor{
+		Name:        "ACKCluster",
+		Product:     "cs",
+		Description: "Container Service for Kubernetes (ACK) cluster",
+		SideEffects: "Deletes the resources created by the cluster (nodes, load balancers, NAT gateway, ...)",
+		Collect:     CollectACKClusters,
+	})
 }
 
 // ACKCluster represents an Alibaba Cloud Container Service for Kubernetes (ACK) cluster resource
 type ACKCluster struct {
-	Client *cs.Client
+	Creds  *types.Credentials
 	Region string
 }
 
 // CollectACKClusters discovers all ACK clusters in the specified region
-func CollectACKClusters(creds *types.Credentials, region string) (types.Resources, error) {
-	client, err := utils.CreateCSClient(creds, region)
+func CollectACKClusters(ctx context.Context, creds *types.Credentials, region string) (types.Resources, error) {
+	client, err := utils.GetCSClient(ctx, creds, region)
 	if err != nil {
 		return nil, err
 	}
@@ -31,6 +39,10 @@ func CollectACKClusters(creds *types.Credentials, region string) (types.Resource
 	pageSize := int64(50)
 
 	for {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
 		request := &cs.DescribeClustersV1Request{
 			RegionId:   tea.String(region),
 			PageNumber: tea.Int64(pageNumber),
@@ -72,12 +84,30 @@ func CollectACKClusters(creds *types.Credentials, region string) (types.Resource
 			clusterName = clusterID
 		}
 
+		props := types.Properties{}
+		props.Set(types.PropertyStatus, cluster.State)
+		props.Set(types.PropertyCreationTime, cluster.Created)
+		props.Set(types.PropertyVpcID, cluster.VpcId)
+		props.Set(types.PropertyVSwitchID, cluster.VswitchId)
+		props.Set(types.PropertyZoneID, cluster.ZoneId)
+		props.Set(types.PropertyResourceGroupID, cluster.ResourceGroupId)
+		props.Set("ClusterType", cluster.ClusterType)
+		props.Set("CurrentVersion", cluster.CurrentVersion)
+		props.SetBool("DeletionProtection", cluster.DeletionProtection)
+
+		tags := types.Tags{}
+		for _, tag := range cluster.Tags {
+			tags.Set(tag.Key, tag.Value)
+		}
+
 		res := types.Resource{
-			Removable:    ACKCluster{Client: client, Region: region},
+			Removable:    ACKCluster{Creds: creds, Region: region},
 			Region:       region,
 			ResourceID:   clusterID,
 			ResourceName: clusterName,
 			ProductName:  "ACKCluster",
+			Properties:   props,
+			Tags:         tags,
 		}
 		allResources = append(allResources, &res)
 	}
@@ -86,12 +116,40 @@ func CollectACKClusters(creds *types.Credentials, region string) (types.Resource
 }
 
 // Remove deletes the ACK cluster
-func (a ACKCluster) Remove(region string, resourceID string, resourceName string) error {
+func (a ACKCluster) Remove(ctx context.Context, region string, resourceID string, resourceName string) error {
+	client, err := utils.GetCSClient(ctx, a.Creds, region)
+	if err != nil {
+		return err
+	}
+
 	request := &cs.DeleteClusterRequest{
 		// Do not retain resources - delete everything associated with the cluster
 		RetainAllResources: tea.Bool(false),
 	}
 
-	_, err := a.Client.DeleteCluster(tea.String(resourceID), request)
+	_, err = client.DeleteCluster(tea.String(resourceID), request)
 	return err
 }
+
+// CheckDeletion reports whether the ACK cluster is gone
+func (a ACKCluster) CheckDeletion(ctx context.Context, region string, resourceID string) (types.DeletionStatus, error) {
+	client, err := utils.GetCSClient(ctx, a.Creds, region)
+	if err != nil {
+		return types.DeletionPending, err
+	}
+
+	response, err := client.DescribeClustersV1(&cs.DescribeClustersV1Request{
+		RegionId:  tea.String(region),
+		ClusterId: tea.String(resourceID),
+	})
+	if err != nil {
+		return types.DeletionPending, err
+	}
+	if response.Body == nil || len(response.Body.Clusters) == 0 {
+		return types.DeletionComplete, nil
+	}
+	if state := response.Body.Clusters[0].State; state != nil && *state == "delete_failed" {
+		return types.DeletionFailed, nil
+	}
+	return types.DeletionPending, nil
+}
@@ -1,6 +1,8 @@
 package resources
 
 import (
+	"context"
+
 	alb "github.com/alibabacloud-go/alb-20200616/v2/client"
 	"github.com/alibabacloud-go/tea/tea"
 
@@ -10,18 +12,26 @@ import (
 )
 
 func init() {
-	infrastructure.RegisterCollector("alb", CollectALBInstances)
+	infrastructure.Register(infrastructure.Descriptor{
+		Name:        "ALB",
+		Product:     "alb",
+		Description: "Application Load Balancer instance",
+		Collect:     CollectALBInstances,
+	})
+
+	// The API calls ALB instances load balancers
+	types.RegisterErrorCodes("ALB", types.ErrorNotFound, "ResourceNotFound.LoadBalancer")
 }
 
 // ALB represents an Alibaba Cloud Application Load Balancer resource
 type ALB struct {
-	Client *alb.Client
+	Creds  *types.Credentials
 	Region string
 }
 
 // CollectALBInstances discovers all ALB instances in the specified region
-func CollectALBInstances(creds *types.Credentials, region string) (types.Resources, error) {
-	client, err := utils.CreateALBClient(creds, region)
+func CollectALBInstances(ctx context.Context, creds *types.Credentials, region string) (types.Resources, error) {
+	client, err := utils.GetALBClient(ctx, creds, region)
 	if err != nil {
 		return nil, err
 	}
@@ -30,6 +40,10 @@ func CollectALBInstances(creds *types.Credentials, region string) (types.Resourc
 	nextToken := ""
 
 	for {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
 		request := &alb.ListLoadBalancersRequest{
 			MaxResults: tea.Int32(100),
 		}
@@ -67,12 +81,27 @@ func CollectALBInstances(creds *types.Credentials, region string) (types.Resourc
 			lbName = lbID
 		}
 
+		props := types.Properties{}
+		props.Set(types.PropertyStatus, lb.LoadBalancerStatus)
+		props.Set(types.PropertyCreationTime, lb.CreateTime)
+		props.Set(types.PropertyVpcID, lb.VpcId)
+		props.Set(types.PropertyResourceGroupID, lb.ResourceGroupId)
+		props.Set("AddressType", lb.AddressType)
+		props.Set("LoadBalancerEdition", lb.LoadBalancerEdition)
+
+		tags := types.Tags{}
+		for _, tag := range lb.Tags {
+			tags.Set(tag.Key, tag.Value)
+		}
+
 		res := types.Resource{
-			Removable:    ALB{Client: client, Region: region},
+			Removable:    ALB{Creds: creds, Region: region},
 			Region:       region,
 			ResourceID:   lbID,
 			ResourceName: lbName,
 			ProductName:  "ALB",
+			Properties:   props,
+			Tags:         tags,
 		}
 		allResources = append(allResources, &res)
 	}
@@ -81,11 +110,16 @@ func CollectALBInstances(creds *types.Credentials, region string) (types.Resourc
 }
 
 // Remove deletes the ALB instance
-func (a ALB) Remove(region string, resourceID string, resourceName string) error {
+func (a ALB) Remove(ctx context.Context, region string, resourceID string, resourceName string) error {
+	client, err := utils.GetALBClient(ctx, a.Creds, region)
+	if err != nil {
+		return err
+	}
+
 	request := &alb.DeleteLoadBalancerRequest{
 		LoadBalancerId: tea.String(resourceID),
 	}
 
-	_, err := a.Client.DeleteLoadBalancer(request)
+	_, err = client.DeleteLoadBalancer(request)
 	return err
 }
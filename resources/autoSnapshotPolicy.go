@@ -1,6 +1,8 @@
 package resources
 
 import (
+	"context"
+
 	ecs "github.com/alibabacloud-go/ecs-20140526/v7/client"
 	"github.com/alibabacloud-go/tea/tea"
 
@@ -10,18 +12,24 @@ import (
 )
 
 func init() {
-	infrastructure.RegisterCollector("autoSnapshotPolicy", CollectAutoSnapshotPolicies)
+	infrastructure.Register(infrastructure.Descriptor{
+		Name:        "AutoSnapshotPolicy",
+		Product:     "ecs",
+		Description: "ECS automatic snapshot policy",
+		SideEffects: "Disks using the policy stop being snapshotted",
+		Collect:     CollectAutoSnapshotPolicies,
+	})
 }
 
 // AutoSnapshotPolicy represents an Alibaba Cloud ECS Auto Snapshot Policy resource
 type AutoSnapshotPolicy struct {
-	Client *ecs.Client
+	Creds  *types.Credentials
 	Region string
 }
 
 // CollectAutoSnapshotPolicies discovers all Auto Snapshot Policies in the specified region
-func CollectAutoSnapshotPolicies(creds *types.Credentials, region string) (types.Resources, error) {
-	client, err := utils.CreateECSClient(creds, region)
+func CollectAutoSnapshotPolicies(ctx context.Context, creds *types.Credentials, region string) (types.Resources, error) {
+	client, err := utils.GetECSClient(ctx, creds, region)
 	if err != nil {
 		return nil, err
 	}
@@ -31,6 +39,10 @@ func CollectAutoSnapshotPolicies(creds *types.Credentials, region string) (types
 	pageSize := int32(50)
 
 	for {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
 		request := &ecs.DescribeAutoSnapshotPolicyExRequest{
 			RegionId:   tea.String(region),
 			PageNumber: tea.Int32(pageNumber),
@@ -72,12 +84,28 @@ func CollectAutoSnapshotPolicies(creds *types.Credentials, region string) (types
 			policyName = policyID
 		}
 
+		props := types.Properties{}
+		props.Set(types.PropertyStatus, policy.Status)
+		props.Set(types.PropertyCreationTime, policy.CreationTime)
+		props.Set(types.PropertyResourceGroupID, policy.ResourceGroupId)
+		props.SetInt32("RetentionDays", policy.RetentionDays)
+		props.SetInt32("DiskNums", policy.DiskNums)
+
+		tags := types.Tags{}
+		if policy.Tags != nil {
+			for _, tag := range policy.Tags.Tag {
+				tags.Set(tag.TagKey, tag.TagValue)
+			}
+		}
+
 		res := types.Resource{
-			Removable:    AutoSnapshotPolicy{Client: client, Region: region},
+			Removable:    AutoSnapshotPolicy{Creds: creds, Region: region},
 			Region:       region,
 			ResourceID:   policyID,
 			ResourceName: policyName,
 			ProductName:  "AutoSnapshotPolicy",
+			Properties:   props,
+			Tags:         tags,
 		}
 		allResources = append(allResources, &res)
 	}
@@ -86,12 +114,17 @@ func CollectAutoSnapshotPolicies(creds *types.Credentials, region string) (types
 }
 
 // Remove deletes the Auto Snapshot Policy
-func (a AutoSnapshotPolicy) Remove(region string, resourceID string, resourceName string) error {
+func (a AutoSnapshotPolicy) Remove(ctx context.Context, region string, resourceID string, resourceName string) error {
+	client, err := utils.GetECSClient(ctx, a.Creds, region)
+	if err != nil {
+		return err
+	}
+
 	request := &ecs.DeleteAutoSnapshotPolicyRequest{
 		RegionId:             tea.String(region),
 		AutoSnapshotPolicyId: tea.String(resourceID),
 	}
 
-	_, err := a.Client.DeleteAutoSnapshotPolicy(request)
+	_, err = client.DeleteAutoSnapshotPolicy(request)
 	return err
 }
@@ -1,6 +1,8 @@
 package resources
 
 import (
+	"context"
+
 	cbn "github.com/alibabacloud-go/cbn-20170912/v2/client"
 	"github.com/alibabacloud-go/tea/tea"
 
@@ -10,24 +12,26 @@ import (
 )
 
 func init() {
-	infrastructure.RegisterCollector("cenInstance", CollectCENInstances)
+	infrastructure.Register(infrastructure.Descriptor{
+		Name:        "CENInstance",
+		Product:     "cbn",
+		Scope:       infrastructure.ScopeGlobal,
+		Description: "Cloud Enterprise Network (CEN) instance",
+		DependsOn:   []string{"TransitRouter"},
+		Collect:     CollectCENInstances,
+	})
 }
 
 // CENInstance represents an Alibaba Cloud Cloud Enterprise Network (CEN) instance resource
 type CENInstance struct {
-	Client *cbn.Client
+	Creds  *types.Credentials
 	Region string
 }
 
-// CollectCENInstances discovers all CEN instances
-// Note: CEN instances are global resources, we only collect them once using a fixed region
-func CollectCENInstances(creds *types.Credentials, region string) (types.Resources, error) {
-	// CEN is a global service, only collect from cn-hangzhou to avoid duplicates
-	if region != "cn-hangzhou" {
-		return nil, nil
-	}
-
-	client, err := utils.CreateCENClient(creds, region)
+// CollectCENInstances discovers all CEN instances of the account. CEN is a global service,
+// region is the global region of the settings.
+func CollectCENInstances(ctx context.Context, creds *types.Credentials, region string) (types.Resources, error) {
+	client, err := utils.GetCENClient(ctx, creds, region)
 	if err != nil {
 		return nil, err
 	}
@@ -37,6 +41,10 @@ func CollectCENInstances(creds *types.Credentials, region string) (types.Resourc
 	pageSize := int32(50)
 
 	for {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
 		request := &cbn.DescribeCensRequest{
 			PageNumber: tea.Int32(pageNumber),
 			PageSize:   tea.Int32(pageSize),
@@ -77,12 +85,27 @@ func CollectCENInstances(creds *types.Credentials, region string) (types.Resourc
 			cenName = cenID
 		}
 
+		props := types.Properties{}
+		props.Set(types.PropertyStatus, cen.Status)
+		props.Set(types.PropertyCreationTime, cen.CreationTime)
+		props.Set(types.PropertyResourceGroupID, cen.ResourceGroupId)
+		props.Set("Description", cen.Description)
+
+		tags := types.Tags{}
+		if cen.Tags != nil {
+			for _, tag := range cen.Tags.Tag {
+				tags.Set(tag.Key, tag.Value)
+			}
+		}
+
 		res := types.Resource{
-			Removable:    CENInstance{Client: client, Region: region},
-			Region:       "global", // CEN is a global resource
+			Removable:    CENInstance{Creds: creds, Region: region},
+			Region:       infrastructure.GlobalRegion,
 			ResourceID:   cenID,
 			ResourceName: cenName,
 			ProductName:  "CENInstance",
+			Properties:   props,
+			Tags:         tags,
 		}
 		allResources = append(allResources, &res)
 	}
@@ -91,11 +114,16 @@ func CollectCENInstances(creds *types.Credentials, region string) (types.Resourc
 }
 
 // Remove deletes the CEN instance
-func (c CENInstance) Remove(region string, resourceID string, resourceName string) error {
+func (c CENInstance) Remove(ctx context.Context, region string, resourceID string, resourceName string) error {
+	client, err := utils.GetCENClient(ctx, c.Creds, region)
+	if err != nil {
+		return err
+	}
+
 	request := &cbn.DeleteCenRequest{
 		CenId: tea.String(resourceID),
 	}
 
-	_, err := c.Client.DeleteCen(request)
+	_, err = client.DeleteCen(request)
 	return err
 }
@@ -1,6 +1,8 @@
 package resources
 
 import (
+	"context"
+
 	ecs "github.com/alibabacloud-go/ecs-20140526/v7/client"
 	"github.com/alibabacloud-go/tea/tea"
 
@@ -10,18 +12,26 @@ import (
 )
 
 func init() {
-	infrastructure.RegisterCollector("command", CollectCommands)
+	infrastructure.Register(infrastructure.Descriptor{
+		Name:        "Command",
+		Product:     "ecs",
+		Description: "ECS Cloud Assistant command",
+		Collect:     CollectCommands,
+	})
+
+	// The API abbreviates commands
+	types.RegisterErrorCodes("Command", types.ErrorNotFound, "InvalidCmdId.NotFound")
 }
 
 // Command represents an Alibaba Cloud ECS Cloud Assistant Command resource
 type Command struct {
-	Client *ecs.Client
+	Creds  *types.Credentials
 	Region string
 }
 
 // CollectCommands discovers all Cloud Assistant Commands in the specified region
-func CollectCommands(creds *types.Credentials, region string) (types.Resources, error) {
-	client, err := utils.CreateECSClient(creds, region)
+func CollectCommands(ctx context.Context, creds *types.Credentials, region string) (types.Resources, error) {
+	client, err := utils.GetECSClient(ctx, creds, region)
 	if err != nil {
 		return nil, err
 	}
@@ -31,6 +41,10 @@ func CollectCommands(creds *types.Credentials, region string) (types.Resources,
 	pageSize := int64(50)
 
 	for {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
 		request := &ecs.DescribeCommandsRequest{
 			RegionId:   tea.String(region),
 			PageNumber: tea.Int64(pageNumber),
@@ -81,12 +95,27 @@ func CollectCommands(creds *types.Credentials, region string) (types.Resources,
 			continue
 		}
 
+		props := types.Properties{}
+		props.Set(types.PropertyCreationTime, cmd.CreationTime)
+		props.Set(types.PropertyResourceGroupID, cmd.ResourceGroupId)
+		props.Set("Type", cmd.Type)
+		props.Set("Description", cmd.Description)
+
+		tags := types.Tags{}
+		if cmd.Tags != nil {
+			for _, tag := range cmd.Tags.Tag {
+				tags.Set(tag.TagKey, tag.TagValue)
+			}
+		}
+
 		res := types.Resource{
-			Removable:    Command{Client: client, Region: region},
+			Removable:    Command{Creds: creds, Region: region},
 			Region:       region,
 			ResourceID:   cmdID,
 			ResourceName: cmdName,
 			ProductName:  "Command",
+			Properties:   props,
+			Tags:         tags,
 		}
 		allResources = append(allResources, &res)
 	}
@@ -95,12 +124,17 @@ func CollectCommands(creds *types.Credentials, region string) (types.Resources,
 }
 
 // Remove deletes the Command
-func (c Command) Remove(region string, resourceID string, resourceName string) error {
+func (c Command) Remove(ctx context.Context, region string, resourceID string, resourceName string) error {
+	client, err := utils.GetECSClient(ctx, c.Creds, region)
+	if err != nil {
+		return err
+	}
+
 	request := &ecs.DeleteCommandRequest{
 		RegionId:  tea.String(region),
 		CommandId: tea.String(resourceID),
 	}
 
-	_, err := c.Client.DeleteCommand(request)
+	_, err = client.DeleteCommand(request)
 	return err
 }
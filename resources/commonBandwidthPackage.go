@@ -1,6 +1,8 @@
 package resources
 
 import (
+	"context"
+
 	"github.com/alibabacloud-go/tea/tea"
 	vpc "github.com/alibabacloud-go/vpc-20160428/v6/client"
 
@@ -10,18 +12,24 @@ import (
 )
 
 func init() {
-	infrastructure.RegisterCollector("commonBandwidthPackage", CollectCommonBandwidthPackages)
+	infrastructure.Register(infrastructure.Descriptor{
+		Name:        "CommonBandwidthPackage",
+		Product:     "vpc",
+		Description: "Common bandwidth package",
+		SideEffects: "Removes the associated EIPs from the package",
+		Collect:     CollectCommonBandwidthPackages,
+	})
 }
 
 // CommonBandwidthPackage represents an Alibaba Cloud Common Bandwidth Package resource
 type CommonBandwidthPackage struct {
-	Client *vpc.Client
+	Creds  *types.Credentials
 	Region string
 }
 
 // CollectCommonBandwidthPackages discovers all Common Bandwidth Packages in the specified region
-func CollectCommonBandwidthPackages(creds *types.Credentials, region string) (types.Resources, error) {
-	client, err := utils.CreateVPCClient(creds, region)
+func CollectCommonBandwidthPackages(ctx context.Context, creds *types.Credentials, region string) (types.Resources, error) {
+	client, err := utils.GetVPCClient(ctx, creds, region)
 	if err != nil {
 		return nil, err
 	}
@@ -31,6 +39,10 @@ func CollectCommonBandwidthPackages(creds *types.Credentials, region string) (ty
 	pageSize := int32(50)
 
 	for {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
 		request := &vpc.DescribeCommonBandwidthPackagesRequest{
 			RegionId:   tea.String(region),
 			PageNumber: tea.Int32(pageNumber),
@@ -72,12 +84,29 @@ func CollectCommonBandwidthPackages(creds *types.Credentials, region string) (ty
 			pkgName = pkgID
 		}
 
+		props := types.Properties{}
+		props.Set(types.PropertyStatus, pkg.Status)
+		props.Set(types.PropertyCreationTime, pkg.CreationTime)
+		props.Set(types.PropertyChargeType, pkg.InstanceChargeType)
+		props.Set(types.PropertyResourceGroupID, pkg.ResourceGroupId)
+		props.Set("InternetChargeType", pkg.InternetChargeType)
+		props.Set("Bandwidth", pkg.Bandwidth)
+
+		tags := types.Tags{}
+		if pkg.Tags != nil {
+			for _, tag := range pkg.Tags.Tag {
+				tags.Set(tag.Key, tag.Value)
+			}
+		}
+
 		res := types.Resource{
-			Removable:    CommonBandwidthPackage{Client: client, Region: region},
+			Removable:    CommonBandwidthPackage{Creds: creds, Region: region},
 			Region:       region,
 			ResourceID:   pkgID,
 			ResourceName: pkgName,
 			ProductName:  "CommonBandwidthPackage",
+			Properties:   props,
+			Tags:         tags,
 		}
 		allResources = append(allResources, &res)
 	}
@@ -86,13 +115,18 @@ func CollectCommonBandwidthPackages(creds *types.Credentials, region string) (ty
 }
 
 // Remove deletes the Common Bandwidth Package
-func (c CommonBandwidthPackage) Remove(region string, resourceID string, resourceName string) error {
+func (c CommonBandwidthPackage) Remove(ctx context.Context, region string, resourceID string, resourceName string) error {
+	client, err := utils.GetVPCClient(ctx, c.Creds, region)
+	if err != nil {
+		return err
+	}
+
 	request := &vpc.DeleteCommonBandwidthPackageRequest{
 		BandwidthPackageId: tea.String(resourceID),
 		RegionId:           tea.String(region),
 		Force:              tea.String("true"), // Force delete even if EIPs are associated
 	}
 
-	_, err := c.Client.DeleteCommonBandwidthPackage(request)
+	_, err = client.DeleteCommonBandwidthPackage(request)
 	return err
 }
@@ -1,6 +1,8 @@
 package resources
 
 import (
+	"context"
+
 	cr "github.com/alibabacloud-go/cr-20181201/v2/client"
 	"github.com/alibabacloud-go/tea/tea"
 
@@ -10,19 +12,25 @@ import (
 )
 
 func init() {
-	infrastructure.RegisterCollector("containerRegistryRepo", CollectContainerRegistryRepos)
+	infrastructure.Register(infrastructure.Descriptor{
+		Name:        "ContainerRegistryRepo",
+		Product:     "cr",
+		Description: "Container Registry repository",
+		SideEffects: "Deletes all images of the repository",
+		Collect:     CollectContainerRegistryRepos,
+	})
 }
 
 // ContainerRegistryRepo represents an Alibaba Cloud Container Registry Repository
 type ContainerRegistryRepo struct {
-	Client     *cr.Client
+	Creds      *types.Credentials
 	Region     string
 	InstanceId string
 }
 
 // CollectContainerRegistryRepos discovers all Container Registry Repositories in the specified region
-func CollectContainerRegistryRepos(creds *types.Credentials, region string) (types.Resources, error) {
-	client, err := utils.CreateCRClient(creds, region)
+func CollectContainerRegistryRepos(ctx context.Context, creds *types.Credentials, region string) (types.Resources, error) {
+	client, err := utils.GetCRClient(ctx, creds, region)
 	if err != nil {
 		return nil, err
 	}
@@ -73,12 +81,20 @@ func CollectContainerRegistryRepos(creds *types.Credentials, region string) (typ
 						repoName = repoID
 					}
 
+					props := types.Properties{}
+					props.Set(types.PropertyStatus, repo.RepoStatus)
+					props.SetUnixMilli(types.PropertyCreationTime, repo.CreateTime)
+					props.Set(types.PropertyResourceGroupID, repo.ResourceGroupId)
+					props.Set("RepoType", repo.RepoType)
+					props.Set("InstanceId", repo.InstanceId)
+
 					res := types.Resource{
-						Removable:    ContainerRegistryRepo{Client: client, Region: region, InstanceId: instanceID},
+						Removable:    ContainerRegistryRepo{Creds: creds, Region: region, InstanceId: instanceID},
 						Region:       region,
 						ResourceID:   repoID,
 						ResourceName: repoName,
 						ProductName:  "ContainerRegistryRepo",
+						Properties:   props,
 					}
 					allResources = append(allResources, &res)
 				}
@@ -90,12 +106,17 @@ func CollectContainerRegistryRepos(creds *types.Credentials, region string) (typ
 }
 
 // Remove deletes the Container Registry Repository
-func (c ContainerRegistryRepo) Remove(region string, resourceID string, resourceName string) error {
+func (c ContainerRegistryRepo) Remove(ctx context.Context, region string, resourceID string, resourceName string) error {
+	client, err := utils.GetCRClient(ctx, c.Creds, region)
+	if err != nil {
+		return err
+	}
+
 	request := &cr.DeleteRepositoryRequest{
 		InstanceId: tea.String(c.InstanceId),
 		RepoId:     tea.String(resourceID),
 	}
 
-	_, err := c.Client.DeleteRepository(request)
+	_, err = client.DeleteRepository(request)
 	return err
 }
@@ -1,6 +1,8 @@
 package resources
 
 import (
+	"context"
+
 	"github.com/alibabacloud-go/tea/tea"
 	vpc "github.com/alibabacloud-go/vpc-20160428/v6/client"
 
@@ -10,18 +12,24 @@ import (
 )
 
 func init() {
-	infrastructure.RegisterCollector("customerGateway", CollectCustomerGateways)
+	infrastructure.Register(infrastructure.Descriptor{
+		Name:        "CustomerGateway",
+		Product:     "vpc",
+		Description: "VPN customer gateway",
+		DependsOn:   []string{"VpnConnection"},
+		Collect:     CollectCustomerGateways,
+	})
 }
 
 // CustomerGateway represents an Alibaba Cloud Customer Gateway resource (for VPN)
 type CustomerGateway struct {
-	Client *vpc.Client
+	Creds  *types.Credentials
 	Region string
 }
 
 // CollectCustomerGateways discovers all Customer Gateways in the specified region
-func CollectCustomerGateways(creds *types.Credentials, region string) (types.Resources, error) {
-	client, err := utils.CreateVPCClient(creds, region)
+func CollectCustomerGateways(ctx context.Context, creds *types.Credentials, region string) (types.Resources, error) {
+	client, err := utils.GetVPCClient(ctx, creds, region)
 	if err != nil {
 		return nil, err
 	}
@@ -31,6 +39,10 @@ func CollectCustomerGateways(creds *types.Credentials, region string) (types.Res
 	pageSize := int32(50)
 
 	for {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
 		request := &vpc.DescribeCustomerGatewaysRequest{
 			RegionId:   tea.String(region),
 			PageNumber: tea.Int32(pageNumber),
@@ -75,12 +87,26 @@ func CollectCustomerGateways(creds *types.Credentials, region string) (types.Res
 			cgwName = cgwID
 		}
 
+		props := types.Properties{}
+		props.SetUnixMilli(types.PropertyCreationTime, cgw.CreateTime)
+		props.Set(types.PropertyResourceGroupID, cgw.ResourceGroupId)
+		props.Set("IpAddress", cgw.IpAddress)
+
+		tags := types.Tags{}
+		if cgw.Tags != nil {
+			for _, tag := range cgw.Tags.Tag {
+				tags.Set(tag.Key, tag.Value)
+			}
+		}
+
 		res := types.Resource{
-			Removable:    CustomerGateway{Client: client, Region: region},
+			Removable:    CustomerGateway{Creds: creds, Region: region},
 			Region:       region,
 			ResourceID:   cgwID,
 			ResourceName: cgwName,
 			ProductName:  "CustomerGateway",
+			Properties:   props,
+			Tags:         tags,
 		}
 		allResources = append(allResources, &res)
 	}
@@ -89,12 +115,17 @@ func CollectCustomerGateways(creds *types.Credentials, region string) (types.Res
 }
 
 // Remove deletes the Customer Gateway
-func (c CustomerGateway) Remove(region string, resourceID string, resourceName string) error {
+func (c CustomerGateway) Remove(ctx context.Context, region string, resourceID string, resourceName string) error {
+	client, err := utils.GetVPCClient(ctx, c.Creds, region)
+	if err != nil {
+		return err
+	}
+
 	request := &vpc.DeleteCustomerGatewayRequest{
 		CustomerGatewayId: tea.String(resourceID),
 		RegionId:          tea.String(region),
 	}
 
-	_, err := c.Client.DeleteCustomerGateway(request)
+	_, err = client.DeleteCustomerGateway(request)
 	return err
 }
@@ -1,6 +1,8 @@
 package resources
 
 import (
+	"context"
+
 	ecs "github.com/alibabacloud-go/ecs-20140526/v7/client"
 	"github.com/alibabacloud-go/tea/tea"
 
@@ -10,18 +12,24 @@ import (
 )
 
 func init() {
-	infrastructure.RegisterCollector("deploymentSet", CollectDeploymentSets)
+	infrastructure.Register(infrastructure.Descriptor{
+		Name:        "DeploymentSet",
+		Product:     "ecs",
+		Description: "ECS deployment set",
+		DependsOn:   []string{"ECSInstance"},
+		Collect:     CollectDeploymentSets,
+	})
 }
 
 // DeploymentSet represents an Alibaba Cloud ECS Deployment Set resource
 type DeploymentSet struct {
-	Client *ecs.Client
+	Creds  *types.Credentials
 	Region string
 }
 
 // CollectDeploymentSets discovers all Deployment Sets in the specified region
-func CollectDeploymentSets(creds *types.Credentials, region string) (types.Resources, error) {
-	client, err := utils.CreateECSClient(creds, region)
+func CollectDeploymentSets(ctx context.Context, creds *types.Credentials, region string) (types.Resources, error) {
+	client, err := utils.GetECSClient(ctx, creds, region)
 	if err != nil {
 		return nil, err
 	}
@@ -31,6 +39,10 @@ func CollectDeploymentSets(creds *types.Credentials, region string) (types.Resou
 	pageSize := int32(50)
 
 	for {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
 		request := &ecs.DescribeDeploymentSetsRequest{
 			RegionId:   tea.String(region),
 			PageNumber: tea.Int32(pageNumber),
@@ -72,12 +84,18 @@ func CollectDeploymentSets(creds *types.Credentials, region string) (types.Resou
 			dsName = dsID
 		}
 
+		props := types.Properties{}
+		props.Set(types.PropertyCreationTime, ds.CreationTime)
+		props.Set("Strategy", ds.Strategy)
+		props.SetInt32("InstanceAmount", ds.InstanceAmount)
+
 		res := types.Resource{
-			Removable:    DeploymentSet{Client: client, Region: region},
+			Removable:    DeploymentSet{Creds: creds, Region: region},
 			Region:       region,
 			ResourceID:   dsID,
 			ResourceName: dsName,
 			ProductName:  "DeploymentSet",
+			Properties:   props,
 		}
 		allResources = append(allResources, &res)
 	}
@@ -86,12 +104,17 @@ func CollectDeploymentSets(creds *types.Credentials, region string) (types.Resou
 }
 
 // Remove deletes the Deployment Set
-func (d DeploymentSet) Remove(region string, resourceID string, resourceName string) error {
+func (d DeploymentSet) Remove(ctx context.Context, region string, resourceID string, resourceName string) error {
+	client, err := utils.GetECSClient(ctx, d.Creds, region)
+	if err != nil {
+		return err
+	}
+
 	request := &ecs.DeleteDeploymentSetRequest{
 		RegionId:        tea.String(region),
 		DeploymentSetId: tea.String(resourceID),
 	}
 
-	_, err := d.Client.DeleteDeploymentSet(request)
+	_, err = client.DeleteDeploymentSet(request)
 	return err
 }
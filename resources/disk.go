@@ -1,6 +1,8 @@
 package resources
 
 import (
+	"context"
+
 	ecs "github.com/alibabacloud-go/ecs-20140526/v7/client"
 	"github.com/alibabacloud-go/tea/tea"
 
@@ -10,18 +12,24 @@ import (
 )
 
 func init() {
-	infrastructure.RegisterCollector("disk", CollectDisks)
+	infrastructure.Register(infrastructure.Descriptor{
+		Name:        "Disk",
+		Product:     "ecs",
+		Description: "ECS cloud disk",
+		DependsOn:   []string{"ECSInstance"},
+		Collect:     CollectDisks,
+	})
 }
 
 // Disk represents an Alibaba Cloud ECS Disk resource
 type Disk struct {
-	Client *ecs.Client
+	Creds  *types.Credentials
 	Region string
 }
 
 // CollectDisks discovers all Disks in the specified region
-func CollectDisks(creds *types.Credentials, region string) (types.Resources, error) {
-	client, err := utils.CreateECSClient(creds, region)
+func CollectDisks(ctx context.Context, creds *types.Credentials, region string) (types.Resources, error) {
+	client, err := utils.GetECSClient(ctx, creds, region)
 	if err != nil {
 		return nil, err
 	}
@@ -31,6 +39,10 @@ func CollectDisks(creds *types.Credentials, region string) (types.Resources, err
 	pageSize := int32(100)
 
 	for {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
 		request := &ecs.DescribeDisksRequest{
 			RegionId:   tea.String(region),
 			PageNumber: tea.Int32(pageNumber),
@@ -88,12 +100,31 @@ func CollectDisks(creds *types.Credentials, region string) (types.Resources, err
 			status = *disk.Status
 		}
 
+		props := types.Properties{}
+		props.Set(types.PropertyStatus, disk.Status)
+		props.Set(types.PropertyCreationTime, disk.CreationTime)
+		props.Set(types.PropertyChargeType, disk.DiskChargeType)
+		props.Set(types.PropertyZoneID, disk.ZoneId)
+		props.Set(types.PropertyResourceGroupID, disk.ResourceGroupId)
+		props.Set("Category", disk.Category)
+		props.SetInt32("Size", disk.Size)
+		props.Set("InstanceId", disk.InstanceId)
+
+		tags := types.Tags{}
+		if disk.Tags != nil {
+			for _, tag := range disk.Tags.Tag {
+				tags.Set(tag.TagKey, tag.TagValue)
+			}
+		}
+
 		res := types.Resource{
-			Removable:    Disk{Client: client, Region: region},
+			Removable:    Disk{Creds: creds, Region: region},
 			Region:       region,
 			ResourceID:   diskID,
 			ResourceName: diskName,
 			ProductName:  "Disk",
+			Properties:   props,
+			Tags:         tags,
 		}
 
 		// Hide attached disks - they need instance deletion first
@@ -108,11 +139,16 @@ func CollectDisks(creds *types.Credentials, region string) (types.Resources, err
 }
 
 // Remove deletes the Disk
-func (d Disk) Remove(region string, resourceID string, resourceName string) error {
+func (d Disk) Remove(ctx context.Context, region string, resourceID string, resourceName string) error {
+	client, err := utils.GetECSClient(ctx, d.Creds, region)
+	if err != nil {
+		return err
+	}
+
 	request := &ecs.DeleteDiskRequest{
 		DiskId: tea.String(resourceID),
 	}
 
-	_, err := d.Client.DeleteDisk(request)
+	_, err = client.DeleteDisk(request)
 	return err
 }
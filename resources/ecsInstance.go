@@ -1,6 +1,8 @@
 package resources
 
 import (
+	"context"
+
 	ecs "github.com/alibabacloud-go/ecs-20140526/v7/client"
 	"github.com/alibabacloud-go/tea/tea"
 
@@ -10,18 +12,25 @@ import (
 )
 
 func init() {
-	infrastructure.RegisterCollector("ecsInstance", CollectECSInstances)
+	infrastructure.Register(infrastructure.Descriptor{
+		Name:        "ECSInstance",
+		Product:     "ecs",
+		Description: "ECS instance",
+		DependsOn:   []string{"ScalingGroup", "ACKCluster"},
+		SideEffects: "Stops running instances, terminates subscriptions and releases disks set to be deleted with the instance",
+		Collect:     CollectECSInstances,
+	})
 }
 
 // ECSInstance represents an Alibaba Cloud ECS instance resource
 type ECSInstance struct {
-	Client *ecs.Client
+	Creds  *types.Credentials
 	Region string
 }
 
 // CollectECSInstances discovers all ECS instances in the specified region
-func CollectECSInstances(creds *types.Credentials, region string) (types.Resources, error) {
-	client, err := utils.CreateECSClient(creds, region)
+func CollectECSInstances(ctx context.Context, creds *types.Credentials, region string) (types.Resources, error) {
+	client, err := utils.GetECSClient(ctx, creds, region)
 	if err != nil {
 		return nil, err
 	}
@@ -32,6 +41,10 @@ func CollectECSInstances(creds *types.Credentials, region string) (types.Resourc
 
 	// Paginate through all instances using PageNumber/PageSize
 	for {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
 		request := &ecs.DescribeInstancesRequest{
 			RegionId:   tea.String(region),
 			PageNumber: tea.Int32(pageNumber),
@@ -71,12 +84,35 @@ func CollectECSInstances(creds *types.Credentials, region string) (types.Resourc
 			instanceName = *instance.InstanceName
 		}
 
+		props := types.Properties{}
+		props.Set(types.PropertyStatus, instance.Status)
+		props.Set(types.PropertyCreationTime, instance.CreationTime)
+		props.Set(types.PropertyChargeType, instance.InstanceChargeType)
+		props.Set(types.PropertyZoneID, instance.ZoneId)
+		props.Set(types.PropertyResourceGroupID, instance.ResourceGroupId)
+		props.Set("InstanceType", instance.InstanceType)
+		props.Set("ImageId", instance.ImageId)
+		props.SetBool("DeletionProtection", instance.DeletionProtection)
+		if instance.VpcAttributes != nil {
+			props.Set(types.PropertyVpcID, instance.VpcAttributes.VpcId)
+			props.Set(types.PropertyVSwitchID, instance.VpcAttributes.VSwitchId)
+		}
+
+		tags := types.Tags{}
+		if instance.Tags != nil {
+			for _, tag := range instance.Tags.Tag {
+				tags.Set(tag.TagKey, tag.TagValue)
+			}
+		}
+
 		res := types.Resource{
-			Removable:    ECSInstance{Client: client, Region: region},
+			Removable:    ECSInstance{Creds: creds, Region: region},
 			Region:       region,
 			ResourceID:   instanceID,
 			ResourceName: instanceName,
 			ProductName:  "ECSInstance",
+			Properties:   props,
+			Tags:         tags,
 		}
 		allResources = append(allResources, &res)
 	}
@@ -85,7 +121,12 @@ func CollectECSInstances(creds *types.Credentials, region string) (types.Resourc
 }
 
 // Remove deletes the ECS instance
-func (e ECSInstance) Remove(region string, resourceID string, resourceName string) error {
+func (e ECSInstance) Remove(ctx context.Context, region string, resourceID string, resourceName string) error {
+	client, err := utils.GetECSClient(ctx, e.Creds, region)
+	if err != nil {
+		return err
+	}
+
 	// Delete the instance with force option
 	// Force=true allows deletion of running instances (will stop first) and subscription instances
 	request := &ecs.DeleteInstanceRequest{
@@ -94,6 +135,26 @@ func (e ECSInstance) Remove(region string, resourceID string, resourceName strin
 		TerminateSubscription: tea.Bool(true),
 	}
 
-	_, err := e.Client.DeleteInstance(request)
+	_, err = client.DeleteInstance(request)
 	return err
 }
+
+// CheckDeletion reports whether the ECS instance is gone
+func (e ECSInstance) CheckDeletion(ctx context.Context, region string, resourceID string) (types.DeletionStatus, error) {
+	client, err := utils.GetECSClient(ctx, e.Creds, region)
+	if err != nil {
+		return types.DeletionPending, err
+	}
+
+	response, err := client.DescribeInstances(&ecs.DescribeInstancesRequest{
+		RegionId:    tea.String(region),
+		InstanceIds: tea.String(`["` + resourceID + `"]`),
+	})
+	if err != nil {
+		return types.DeletionPending, err
+	}
+	if response.Body == nil || response.Body.Instances == nil || len(response.Body.Instances.Instance) == 0 {
+		return types.DeletionComplete, nil
+	}
+	return types.DeletionPending, nil
+}
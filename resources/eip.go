@@ -1,6 +1,8 @@
 package resources
 
 import (
+	"context"
+
 	"github.com/alibabacloud-go/tea/tea"
 	vpc "github.com/alibabacloud-go/vpc-20160428/v6/client"
 
@@ -10,18 +12,28 @@ import (
 )
 
 func init() {
-	infrastructure.RegisterCollector("eip", CollectEIPs)
+	infrastructure.Register(infrastructure.Descriptor{
+		Name:        "EIP",
+		Product:     "vpc",
+		Description: "Elastic IP address",
+		DependsOn:   []string{"ForwardEntry", "SnatEntry", "CommonBandwidthPackage"},
+		SideEffects: "Unassociates the address from its instance first",
+		Collect:     CollectEIPs,
+	})
+
+	// The API names addresses by their allocation ID
+	types.RegisterErrorCodes("EIP", types.ErrorNotFound, "InvalidAllocationId.NotFound")
 }
 
 // EIP represents an Alibaba Cloud Elastic IP Address resource
 type EIP struct {
-	Client *vpc.Client
+	Creds  *types.Credentials
 	Region string
 }
 
 // CollectEIPs discovers all Elastic IP Addresses in the specified region
-func CollectEIPs(creds *types.Credentials, region string) (types.Resources, error) {
-	client, err := utils.CreateVPCClient(creds, region)
+func CollectEIPs(ctx context.Context, creds *types.Credentials, region string) (types.Resources, error) {
+	client, err := utils.GetVPCClient(ctx, creds, region)
 	if err != nil {
 		return nil, err
 	}
@@ -31,6 +43,10 @@ func CollectEIPs(creds *types.Credentials, region string) (types.Resources, erro
 	pageSize := int32(50)
 
 	for {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
 		request := &vpc.DescribeEipAddressesRequest{
 			RegionId:   tea.String(region),
 			PageNumber: tea.Int32(pageNumber),
@@ -74,12 +90,32 @@ func CollectEIPs(creds *types.Credentials, region string) (types.Resources, erro
 			eipName = eipID
 		}
 
+		props := types.Properties{}
+		props.Set(types.PropertyStatus, eip.Status)
+		props.Set(types.PropertyCreationTime, eip.AllocationTime)
+		props.Set(types.PropertyChargeType, eip.ChargeType)
+		props.Set(types.PropertyVpcID, eip.VpcId)
+		props.Set(types.PropertyResourceGroupID, eip.ResourceGroupId)
+		props.Set("IpAddress", eip.IpAddress)
+		props.Set("InstanceId", eip.InstanceId)
+		props.Set("InstanceType", eip.InstanceType)
+		props.Set("BandwidthPackageId", eip.BandwidthPackageId)
+
+		tags := types.Tags{}
+		if eip.Tags != nil {
+			for _, tag := range eip.Tags.Tag {
+				tags.Set(tag.Key, tag.Value)
+			}
+		}
+
 		res := types.Resource{
-			Removable:    EIP{Client: client, Region: region},
+			Removable:    EIP{Creds: creds, Region: region},
 			Region:       region,
 			ResourceID:   eipID,
 			ResourceName: eipName,
 			ProductName:  "EIP",
+			Properties:   props,
+			Tags:         tags,
 		}
 		allResources = append(allResources, &res)
 	}
@@ -88,7 +124,12 @@ func CollectEIPs(creds *types.Credentials, region string) (types.Resources, erro
 }
 
 // Remove deletes the Elastic IP Address
-func (e EIP) Remove(region string, resourceID string, resourceName string) error {
+func (e EIP) Remove(ctx context.Context, region string, resourceID string, resourceName string) error {
+	client, err := utils.GetVPCClient(ctx, e.Creds, region)
+	if err != nil {
+		return err
+	}
+
 	// First try to unassociate if attached
 	unassociateReq := &vpc.UnassociateEipAddressRequest{
 		AllocationId: tea.String(resourceID),
@@ -96,7 +137,7 @@ func (e EIP) Remove(region string, resourceID string, resourceName string) error
 		Force:        tea.Bool(true),
 	}
 	// Ignore unassociate errors - it may not be associated
-	e.Client.UnassociateEipAddress(unassociateReq)
+	client.UnassociateEipAddress(unassociateReq)
 
 	// Release the EIP
 	request := &vpc.ReleaseEipAddressRequest{
@@ -104,6 +145,6 @@ func (e EIP) Remove(region string, resourceID string, resourceName string) error
 		RegionId:     tea.String(region),
 	}
 
-	_, err := e.Client.ReleaseEipAddress(request)
+	_, err = client.ReleaseEipAddress(request)
 	return err
 }
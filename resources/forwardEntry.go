@@ -1,6 +1,8 @@
 package resources
 
 import (
+	"context"
+
 	"github.com/alibabacloud-go/tea/tea"
 	vpc "github.com/alibabacloud-go/vpc-20160428/v6/client"
 
@@ -10,19 +12,27 @@ import (
 )
 
 func init() {
-	infrastructure.RegisterCollector("forwardEntry", CollectForwardEntries)
+	infrastructure.Register(infrastructure.Descriptor{
+		Name:        "ForwardEntry",
+		Product:     "vpc",
+		Description: "DNAT entry of a NAT gateway",
+		Collect:     CollectForwardEntries,
+	})
+
+	// The entry was deleted together with its NAT gateway
+	types.RegisterErrorCodes("ForwardEntry", types.ErrorNotFound, "InvalidForwardTableId.NotFound")
 }
 
 // ForwardEntry represents an Alibaba Cloud Forward Entry (DNAT) resource
 type ForwardEntry struct {
-	Client         *vpc.Client
+	Creds          *types.Credentials
 	Region         string
 	ForwardTableId string
 }
 
 // CollectForwardEntries discovers all Forward Entries (DNAT) in the specified region
-func CollectForwardEntries(creds *types.Credentials, region string) (types.Resources, error) {
-	client, err := utils.CreateVPCClient(creds, region)
+func CollectForwardEntries(ctx context.Context, creds *types.Credentials, region string) (types.Resources, error) {
+	client, err := utils.GetVPCClient(ctx, creds, region)
 	if err != nil {
 		return nil, err
 	}
@@ -33,6 +43,10 @@ func CollectForwardEntries(creds *types.Credentials, region string) (types.Resou
 	pageSize := int32(50)
 
 	for {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
 		request := &vpc.DescribeNatGatewaysRequest{
 			RegionId:   tea.String(region),
 			PageNumber: tea.Int32(pageNumber),
@@ -103,12 +117,21 @@ func CollectForwardEntries(creds *types.Credentials, region string) (types.Resou
 						}
 					}
 
+					props := types.Properties{}
+					props.Set(types.PropertyStatus, entry.Status)
+					props.Set("NatGatewayId", entry.NatGatewayId)
+					props.Set("ForwardTableId", entry.ForwardTableId)
+					props.Set("ExternalIp", entry.ExternalIp)
+					props.Set("InternalIp", entry.InternalIp)
+					props.Set("IpProtocol", entry.IpProtocol)
+
 					res := types.Resource{
-						Removable:    ForwardEntry{Client: client, Region: region, ForwardTableId: *forwardTableId},
+						Removable:    ForwardEntry{Creds: creds, Region: region, ForwardTableId: *forwardTableId},
 						Region:       region,
 						ResourceID:   entryID,
 						ResourceName: entryName,
 						ProductName:  "ForwardEntry",
+						Properties:   props,
 					}
 					allResources = append(allResources, &res)
 				}
@@ -120,13 +143,18 @@ func CollectForwardEntries(creds *types.Credentials, region string) (types.Resou
 }
 
 // Remove deletes the Forward Entry
-func (f ForwardEntry) Remove(region string, resourceID string, resourceName string) error {
+func (f ForwardEntry) Remove(ctx context.Context, region string, resourceID string, resourceName string) error {
+	client, err := utils.GetVPCClient(ctx, f.Creds, region)
+	if err != nil {
+		return err
+	}
+
 	request := &vpc.DeleteForwardEntryRequest{
 		ForwardTableId: tea.String(f.ForwardTableId),
 		ForwardEntryId: tea.String(resourceID),
 		RegionId:       tea.String(region),
 	}
 
-	_, err := f.Client.DeleteForwardEntry(request)
+	_, err = client.DeleteForwardEntry(request)
 	return err
 }
@@ -1,6 +1,8 @@
 package resources
 
 import (
+	"context"
+
 	"github.com/alibabacloud-go/tea/tea"
 	vpc "github.com/alibabacloud-go/vpc-20160428/v6/client"
 
@@ -10,18 +12,24 @@ import (
 )
 
 func init() {
-	infrastructure.RegisterCollector("haVip", CollectHaVips)
+	infrastructure.Register(infrastructure.Descriptor{
+		Name:        "HaVip",
+		Product:     "vpc",
+		Description: "High-availability virtual IP address",
+		DependsOn:   []string{"ECSInstance", "NetworkInterface"},
+		Collect:     CollectHaVips,
+	})
 }
 
 // HaVip represents an Alibaba Cloud High Availability Virtual IP resource
 type HaVip struct {
-	Client *vpc.Client
+	Creds  *types.Credentials
 	Region string
 }
 
 // CollectHaVips discovers all HA VIPs in the specified region
-func CollectHaVips(creds *types.Credentials, region string) (types.Resources, error) {
-	client, err := utils.CreateVPCClient(creds, region)
+func CollectHaVips(ctx context.Context, creds *types.Credentials, region string) (types.Resources, error) {
+	client, err := utils.GetVPCClient(ctx, creds, region)
 	if err != nil {
 		return nil, err
 	}
@@ -31,6 +39,10 @@ func CollectHaVips(creds *types.Credentials, region string) (types.Resources, er
 	pageSize := int32(50)
 
 	for {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
 		request := &vpc.DescribeHaVipsRequest{
 			RegionId:   tea.String(region),
 			PageNumber: tea.Int32(pageNumber),
@@ -75,12 +87,30 @@ func CollectHaVips(creds *types.Credentials, region string) (types.Resources, er
 			havipName = havipID
 		}
 
+		props := types.Properties{}
+		props.Set(types.PropertyStatus, havip.Status)
+		props.Set(types.PropertyCreationTime, havip.CreateTime)
+		props.Set(types.PropertyChargeType, havip.ChargeType)
+		props.Set(types.PropertyVpcID, havip.VpcId)
+		props.Set(types.PropertyVSwitchID, havip.VSwitchId)
+		props.Set(types.PropertyResourceGroupID, havip.ResourceGroupId)
+		props.Set("IpAddress", havip.IpAddress)
+
+		tags := types.Tags{}
+		if havip.Tags != nil {
+			for _, tag := range havip.Tags.Tag {
+				tags.Set(tag.Key, tag.Value)
+			}
+		}
+
 		res := types.Resource{
-			Removable:    HaVip{Client: client, Region: region},
+			Removable:    HaVip{Creds: creds, Region: region},
 			Region:       region,
 			ResourceID:   havipID,
 			ResourceName: havipName,
 			ProductName:  "HaVip",
+			Properties:   props,
+			Tags:         tags,
 		}
 		allResources = append(allResources, &res)
 	}
@@ -89,12 +119,17 @@ func CollectHaVips(creds *types.Credentials, region string) (types.Resources, er
 }
 
 // Remove deletes the HA VIP
-func (h HaVip) Remove(region string, resourceID string, resourceName string) error {
+func (h HaVip) Remove(ctx context.Context, region string, resourceID string, resourceName string) error {
+	client, err := utils.GetVPCClient(ctx, h.Creds, region)
+	if err != nil {
+		return err
+	}
+
 	request := &vpc.DeleteHaVipRequest{
 		HaVipId:  tea.String(resourceID),
 		RegionId: tea.String(region),
 	}
 
-	_, err := h.Client.DeleteHaVip(request)
+	_, err = client.DeleteHaVip(request)
 	return err
 }
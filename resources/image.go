@@ -1,6 +1,8 @@
 package resources
 
 import (
+	"context"
+
 	ecs "github.com/alibabacloud-go/ecs-20140526/v7/client"
 	"github.com/alibabacloud-go/tea/tea"
 
@@ -10,18 +12,24 @@ import (
 )
 
 func init() {
-	infrastructure.RegisterCollector("image", CollectImages)
+	infrastructure.Register(infrastructure.Descriptor{
+		Name:        "Image",
+		Product:     "ecs",
+		Description: "ECS custom image",
+		SideEffects: "Also deletes images still used by instances",
+		Collect:     CollectImages,
+	})
 }
 
 // Image represents an Alibaba Cloud ECS Custom Image resource
 type Image struct {
-	Client *ecs.Client
+	Creds  *types.Credentials
 	Region string
 }
 
 // CollectImages discovers all custom Images in the specified region
-func CollectImages(creds *types.Credentials, region string) (types.Resources, error) {
-	client, err := utils.CreateECSClient(creds, region)
+func CollectImages(ctx context.Context, creds *types.Credentials, region string) (types.Resources, error) {
+	client, err := utils.GetECSClient(ctx, creds, region)
 	if err != nil {
 		return nil, err
 	}
@@ -31,6 +39,10 @@ func CollectImages(creds *types.Credentials, region string) (types.Resources, er
 	pageSize := int32(100)
 
 	for {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
 		request := &ecs.DescribeImagesRequest{
 			RegionId:        tea.String(region),
 			PageNumber:      tea.Int32(pageNumber),
@@ -73,12 +85,30 @@ func CollectImages(creds *types.Credentials, region string) (types.Resources, er
 			imageName = imageID
 		}
 
+		props := types.Properties{}
+		props.Set(types.PropertyStatus, image.Status)
+		props.Set(types.PropertyCreationTime, image.CreationTime)
+		props.Set(types.PropertyResourceGroupID, image.ResourceGroupId)
+		props.Set("ImageFamily", image.ImageFamily)
+		props.Set("OSName", image.OSNameEn)
+		props.Set("Usage", image.Usage)
+		props.SetInt32("Size", image.Size)
+
+		tags := types.Tags{}
+		if image.Tags != nil {
+			for _, tag := range image.Tags.Tag {
+				tags.Set(tag.TagKey, tag.TagValue)
+			}
+		}
+
 		res := types.Resource{
-			Removable:    Image{Client: client, Region: region},
+			Removable:    Image{Creds: creds, Region: region},
 			Region:       region,
 			ResourceID:   imageID,
 			ResourceName: imageName,
 			ProductName:  "Image",
+			Properties:   props,
+			Tags:         tags,
 		}
 		allResources = append(allResources, &res)
 	}
@@ -87,13 +117,18 @@ func CollectImages(creds *types.Credentials, region string) (types.Resources, er
 }
 
 // Remove deletes the Image
-func (i Image) Remove(region string, resourceID string, resourceName string) error {
+func (i Image) Remove(ctx context.Context, region string, resourceID string, resourceName string) error {
+	client, err := utils.GetECSClient(ctx, i.Creds, region)
+	if err != nil {
+		return err
+	}
+
 	request := &ecs.DeleteImageRequest{
 		ImageId:  tea.String(resourceID),
 		RegionId: tea.String(region),
 		Force:    tea.Bool(true), // Force delete even if used by instances
 	}
 
-	_, err := i.Client.DeleteImage(request)
+	_, err = client.DeleteImage(request)
 	return err
 }
@@ -1,6 +1,8 @@
 package resources
 
 import (
+	"context"
+
 	ecs "github.com/alibabacloud-go/ecs-20140526/v7/client"
 	"github.com/alibabacloud-go/tea/tea"
 
@@ -10,18 +12,25 @@ import (
 )
 
 func init() {
-	infrastructure.RegisterCollector("keyPair", CollectKeyPairs)
+	infrastructure.Register(infrastructure.Descriptor{
+		Name:        "KeyPair",
+		Product:     "ecs",
+		Description: "ECS SSH key pair",
+		DependsOn:   []string{"ECSInstance"},
+		SideEffects: "Instances using the key pair keep their existing login keys",
+		Collect:     CollectKeyPairs,
+	})
 }
 
 // KeyPair represents an Alibaba Cloud ECS Key Pair resource
 type KeyPair struct {
-	Client *ecs.Client
+	Creds  *types.Credentials
 	Region string
 }
 
 // CollectKeyPairs discovers all Key Pairs in the specified region
-func CollectKeyPairs(creds *types.Credentials, region string) (types.Resources, error) {
-	client, err := utils.CreateECSClient(creds, region)
+func CollectKeyPairs(ctx context.Context, creds *types.Credentials, region string) (types.Resources, error) {
+	client, err := utils.GetECSClient(ctx, creds, region)
 	if err != nil {
 		return nil, err
 	}
@@ -31,6 +40,10 @@ func CollectKeyPairs(creds *types.Credentials, region string) (types.Resources,
 	pageSize := int32(50)
 
 	for {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
 		request := &ecs.DescribeKeyPairsRequest{
 			RegionId:   tea.String(region),
 			PageNumber: tea.Int32(pageNumber),
@@ -65,12 +78,26 @@ func CollectKeyPairs(creds *types.Credentials, region string) (types.Resources,
 		}
 
 		// Key pairs use name as the identifier
+		props := types.Properties{}
+		props.Set(types.PropertyCreationTime, keyPair.CreationTime)
+		props.Set(types.PropertyResourceGroupID, keyPair.ResourceGroupId)
+		props.Set("KeyPairFingerPrint", keyPair.KeyPairFingerPrint)
+
+		tags := types.Tags{}
+		if keyPair.Tags != nil {
+			for _, tag := range keyPair.Tags.Tag {
+				tags.Set(tag.TagKey, tag.TagValue)
+			}
+		}
+
 		res := types.Resource{
-			Removable:    KeyPair{Client: client, Region: region},
+			Removable:    KeyPair{Creds: creds, Region: region},
 			Region:       region,
 			ResourceID:   keyPairName,
 			ResourceName: keyPairName,
 			ProductName:  "KeyPair",
+			Properties:   props,
+			Tags:         tags,
 		}
 		allResources = append(allResources, &res)
 	}
@@ -79,12 +106,17 @@ func CollectKeyPairs(creds *types.Credentials, region string) (types.Resources,
 }
 
 // Remove deletes the Key Pair
-func (k KeyPair) Remove(region string, resourceID string, resourceName string) error {
+func (k KeyPair) Remove(ctx context.Context, region string, resourceID string, resourceName string) error {
+	client, err := utils.GetECSClient(ctx, k.Creds, region)
+	if err != nil {
+		return err
+	}
+
 	request := &ecs.DeleteKeyPairsRequest{
 		RegionId:     tea.String(region),
 		KeyPairNames: tea.String("[\"" + resourceID + "\"]"), // API expects JSON array
 	}
 
-	_, err := k.Client.DeleteKeyPairs(request)
+	_, err = client.DeleteKeyPairs(request)
 	return err
 }
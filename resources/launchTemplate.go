@@ -1,6 +1,8 @@
 package resources
 
 import (
+	"context"
+
 	ecs "github.com/alibabacloud-go/ecs-20140526/v7/client"
 	"github.com/alibabacloud-go/tea/tea"
 
@@ -10,18 +12,25 @@ import (
 )
 
 func init() {
-	infrastructure.RegisterCollector("launchTemplate", CollectLaunchTemplates)
+	infrastructure.Register(infrastructure.Descriptor{
+		Name:        "LaunchTemplate",
+		Product:     "ecs",
+		Description: "ECS launch template",
+		DependsOn:   []string{"ScalingGroup"},
+		SideEffects: "Deletes all versions of the template",
+		Collect:     CollectLaunchTemplates,
+	})
 }
 
 // LaunchTemplate represents an Alibaba Cloud ECS Launch Template resource
 type LaunchTemplate struct {
-	Client *ecs.Client
+	Creds  *types.Credentials
 	Region string
 }
 
 // CollectLaunchTemplates discovers all Launch Templates in the specified region
-func CollectLaunchTemplates(creds *types.Credentials, region string) (types.Resources, error) {
-	client, err := utils.CreateECSClient(creds, region)
+func CollectLaunchTemplates(ctx context.Context, creds *types.Credentials, region string) (types.Resources, error) {
+	client, err := utils.GetECSClient(ctx, creds, region)
 	if err != nil {
 		return nil, err
 	}
@@ -31,6 +40,10 @@ func CollectLaunchTemplates(creds *types.Credentials, region string) (types.Reso
 	pageSize := int32(50)
 
 	for {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
 		request := &ecs.DescribeLaunchTemplatesRequest{
 			RegionId:   tea.String(region),
 			PageNumber: tea.Int32(pageNumber),
@@ -72,12 +85,27 @@ func CollectLaunchTemplates(creds *types.Credentials, region string) (types.Reso
 			templateName = templateID
 		}
 
+		props := types.Properties{}
+		props.Set(types.PropertyCreationTime, template.CreateTime)
+		props.Set(types.PropertyResourceGroupID, template.ResourceGroupId)
+		props.Set("CreatedBy", template.CreatedBy)
+		props.SetInt64("LatestVersionNumber", template.LatestVersionNumber)
+
+		tags := types.Tags{}
+		if template.Tags != nil {
+			for _, tag := range template.Tags.Tag {
+				tags.Set(tag.TagKey, tag.TagValue)
+			}
+		}
+
 		res := types.Resource{
-			Removable:    LaunchTemplate{Client: client, Region: region},
+			Removable:    LaunchTemplate{Creds: creds, Region: region},
 			Region:       region,
 			ResourceID:   templateID,
 			ResourceName: templateName,
 			ProductName:  "LaunchTemplate",
+			Properties:   props,
+			Tags:         tags,
 		}
 		allResources = append(allResources, &res)
 	}
@@ -86,12 +114,17 @@ func CollectLaunchTemplates(creds *types.Credentials, region string) (types.Reso
 }
 
 // Remove deletes the Launch Template
-func (l LaunchTemplate) Remove(region string, resourceID string, resourceName string) error {
+func (l LaunchTemplate) Remove(ctx context.Context, region string, resourceID string, resourceName string) error {
+	client, err := utils.GetECSClient(ctx, l.Creds, region)
+	if err != nil {
+		return err
+	}
+
 	request := &ecs.DeleteLaunchTemplateRequest{
 		RegionId:         tea.String(region),
 		LaunchTemplateId: tea.String(resourceID),
 	}
 
-	_, err := l.Client.DeleteLaunchTemplate(request)
+	_, err = client.DeleteLaunchTemplate(request)
 	return err
 }
@@ -1,6 +1,8 @@
 package resources
 
 import (
+	"context"
+
 	dds "github.com/alibabacloud-go/dds-20151201/v4/client"
 	"github.com/alibabacloud-go/tea/tea"
 
@@ -10,18 +12,23 @@ import (
 )
 
 func init() {
-	infrastructure.RegisterCollector("mongodbInstance", CollectMongoDBInstances)
+	infrastructure.Register(infrastructure.Descriptor{
+		Name:        "MongoDBInstance",
+		Product:     "dds",
+		Description: "ApsaraDB for MongoDB instance",
+		Collect:     CollectMongoDBInstances,
+	})
 }
 
 // MongoDBInstance represents an Alibaba Cloud MongoDB Instance resource
 type MongoDBInstance struct {
-	Client *dds.Client
+	Creds  *types.Credentials
 	Region string
 }
 
 // CollectMongoDBInstances discovers all MongoDB instances in the specified region
-func CollectMongoDBInstances(creds *types.Credentials, region string) (types.Resources, error) {
-	client, err := utils.CreateMongoDBClient(creds, region)
+func CollectMongoDBInstances(ctx context.Context, creds *types.Credentials, region string) (types.Resources, error) {
+	client, err := utils.GetMongoDBClient(ctx, creds, region)
 	if err != nil {
 		return nil, err
 	}
@@ -31,6 +38,10 @@ func CollectMongoDBInstances(creds *types.Credentials, region string) (types.Res
 	pageSize := int32(30)
 
 	for {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
 		request := &dds.DescribeDBInstancesRequest{
 			RegionId:   tea.String(region),
 			PageNumber: tea.Int32(pageNumber),
@@ -72,12 +83,32 @@ func CollectMongoDBInstances(creds *types.Credentials, region string) (types.Res
 			instanceName = instanceID
 		}
 
+		props := types.Properties{}
+		props.Set(types.PropertyStatus, instance.DBInstanceStatus)
+		props.Set(types.PropertyCreationTime, instance.CreationTime)
+		props.Set(types.PropertyChargeType, instance.ChargeType)
+		props.Set(types.PropertyZoneID, instance.ZoneId)
+		props.Set(types.PropertyResourceGroupID, instance.ResourceGroupId)
+		props.Set("Engine", instance.Engine)
+		props.Set("EngineVersion", instance.EngineVersion)
+		props.Set("DBInstanceType", instance.DBInstanceType)
+		props.Set("DBInstanceClass", instance.DBInstanceClass)
+
+		tags := types.Tags{}
+		if instance.Tags != nil {
+			for _, tag := range instance.Tags.Tag {
+				tags.Set(tag.Key, tag.Value)
+			}
+		}
+
 		res := types.Resource{
-			Removable:    MongoDBInstance{Client: client, Region: region},
+			Removable:    MongoDBInstance{Creds: creds, Region: region},
 			Region:       region,
 			ResourceID:   instanceID,
 			ResourceName: instanceName,
 			ProductName:  "MongoDBInstance",
+			Properties:   props,
+			Tags:         tags,
 		}
 		allResources = append(allResources, &res)
 	}
@@ -86,11 +117,36 @@ func CollectMongoDBInstances(creds *types.Credentials, region string) (types.Res
 }
 
 // Remove deletes the MongoDB instance
-func (m MongoDBInstance) Remove(region string, resourceID string, resourceName string) error {
+func (m MongoDBInstance) Remove(ctx context.Context, region string, resourceID string, resourceName string) error {
+	client, err := utils.GetMongoDBClient(ctx, m.Creds, region)
+	if err != nil {
+		return err
+	}
+
 	request := &dds.DeleteDBInstanceRequest{
 		DBInstanceId: tea.String(resourceID),
 	}
 
-	_, err := m.Client.DeleteDBInstance(request)
+	_, err = client.DeleteDBInstance(request)
 	return err
 }
+
+// CheckDeletion reports whether the MongoDB instance is gone
+func (m MongoDBInstance) CheckDeletion(ctx context.Context, region string, resourceID string) (types.DeletionStatus, error) {
+	client, err := utils.GetMongoDBClient(ctx, m.Creds, region)
+	if err != nil {
+		return types.DeletionPending, err
+	}
+
+	response, err := client.DescribeDBInstances(&dds.DescribeDBInstancesRequest{
+		RegionId:     tea.String(region),
+		DBInstanceId: tea.String(resourceID),
+	})
+	if err != nil {
+		return types.DeletionPending, err
+	}
+	if response.Body == nil || response.Body.DBInstances == nil || len(response.Body.DBInstances.DBInstance) == 0 {
+		return types.DeletionComplete, nil
+	}
+	return types.DeletionPending, nil
+}
@@ -1,6 +1,8 @@
 package resources
 
 import (
+	"context"
+
 	nas "github.com/alibabacloud-go/nas-20170626/v3/client"
 	"github.com/alibabacloud-go/tea/tea"
 
@@ -10,18 +12,28 @@ import (
 )
 
 func init() {
-	infrastructure.RegisterCollector("nasFileSystem", CollectNASFileSystems)
+	infrastructure.Register(infrastructure.Descriptor{
+		Name:        "NASFileSystem",
+		Product:     "nas",
+		Description: "NAS file system",
+		DependsOn:   []string{"NASMountTarget"},
+		SideEffects: "Deletes all data stored in the file system",
+		Collect:     CollectNASFileSystems,
+	})
+
+	// The file system still has mount targets that are being deleted
+	types.RegisterErrorCodes("NASFileSystem", types.ErrorDependency, "HasMountTarget")
 }
 
 // NASFileSystem represents an Alibaba Cloud NAS File System resource
 type NASFileSystem struct {
-	Client *nas.Client
+	Creds  *types.Credentials
 	Region string
 }
 
 // CollectNASFileSystems discovers all NAS File Systems in the specified region
-func CollectNASFileSystems(creds *types.Credentials, region string) (types.Resources, error) {
-	client, err := utils.CreateNASClient(creds, region)
+func CollectNASFileSystems(ctx context.Context, creds *types.Credentials, region string) (types.Resources, error) {
+	client, err := utils.GetNASClient(ctx, creds, region)
 	if err != nil {
 		return nil, err
 	}
@@ -31,6 +43,10 @@ func CollectNASFileSystems(creds *types.Credentials, region string) (types.Resou
 	pageSize := int32(100)
 
 	for {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
 		request := &nas.DescribeFileSystemsRequest{
 			PageNumber: tea.Int32(pageNumber),
 			PageSize:   tea.Int32(pageSize),
@@ -74,12 +90,32 @@ func CollectNASFileSystems(creds *types.Credentials, region string) (types.Resou
 			displayName = fsID
 		}
 
+		props := types.Properties{}
+		props.Set(types.PropertyStatus, fs.Status)
+		props.Set(types.PropertyCreationTime, fs.CreateTime)
+		props.Set(types.PropertyChargeType, fs.ChargeType)
+		props.Set(types.PropertyVpcID, fs.VpcId)
+		props.Set(types.PropertyZoneID, fs.ZoneId)
+		props.Set("FileSystemType", fs.FileSystemType)
+		props.Set("ProtocolType", fs.ProtocolType)
+		props.Set("StorageType", fs.StorageType)
+		props.SetInt64("Capacity", fs.Capacity)
+
+		tags := types.Tags{}
+		if fs.Tags != nil {
+			for _, tag := range fs.Tags.Tag {
+				tags.Set(tag.Key, tag.Value)
+			}
+		}
+
 		res := types.Resource{
-			Removable:    NASFileSystem{Client: client, Region: region},
+			Removable:    NASFileSystem{Creds: creds, Region: region},
 			Region:       region,
 			ResourceID:   fsID,
 			ResourceName: displayName,
 			ProductName:  "NASFileSystem",
+			Properties:   props,
+			Tags:         tags,
 		}
 		allResources = append(allResources, &res)
 	}
@@ -88,11 +124,16 @@ func CollectNASFileSystems(creds *types.Credentials, region string) (types.Resou
 }
 
 // Remove deletes the NAS File System
-func (fs NASFileSystem) Remove(region string, resourceID string, resourceName string) error {
+func (fs NASFileSystem) Remove(ctx context.Context, region string, resourceID string, resourceName string) error {
+	client, err := utils.GetNASClient(ctx, fs.Creds, region)
+	if err != nil {
+		return err
+	}
+
 	request := &nas.DeleteFileSystemRequest{
 		FileSystemId: tea.String(resourceID),
 	}
 
-	_, err := fs.Client.DeleteFileSystem(request)
+	_, err = client.DeleteFileSystem(request)
 	return err
 }
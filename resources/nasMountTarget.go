@@ -1,6 +1,8 @@
 package resources
 
 import (
+	"context"
+
 	nas "github.com/alibabacloud-go/nas-20170626/v3/client"
 	"github.com/alibabacloud-go/tea/tea"
 
@@ -10,19 +12,28 @@ import (
 )
 
 func init() {
-	infrastructure.RegisterCollector("nasMountTarget", CollectNASMountTargets)
+	infrastructure.Register(infrastructure.Descriptor{
+		Name:        "NASMountTarget",
+		Product:     "nas",
+		Description: "NAS mount target",
+		SideEffects: "Clients using the mount target lose access",
+		Collect:     CollectNASMountTargets,
+	})
+
+	// The mount target was deleted together with its file system
+	types.RegisterErrorCodes("NASMountTarget", types.ErrorNotFound, "InvalidFileSystem.NotFound")
 }
 
 // NASMountTarget represents an Alibaba Cloud NAS Mount Target resource
 type NASMountTarget struct {
-	Client       *nas.Client
+	Creds        *types.Credentials
 	Region       string
 	FileSystemID string // Required for deletion
 }
 
 // CollectNASMountTargets discovers all NAS Mount Targets in the specified region
-func CollectNASMountTargets(creds *types.Credentials, region string) (types.Resources, error) {
-	client, err := utils.CreateNASClient(creds, region)
+func CollectNASMountTargets(ctx context.Context, creds *types.Credentials, region string) (types.Resources, error) {
+	client, err := utils.GetNASClient(ctx, creds, region)
 	if err != nil {
 		return nil, err
 	}
@@ -33,6 +44,10 @@ func CollectNASMountTargets(creds *types.Credentials, region string) (types.Reso
 	pageSize := int32(100)
 
 	for {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
 		fsRequest := &nas.DescribeFileSystemsRequest{
 			PageNumber: tea.Int32(pageNumber),
 			PageSize:   tea.Int32(pageSize),
@@ -89,12 +104,20 @@ func CollectNASMountTargets(creds *types.Credentials, region string) (types.Reso
 					displayName = fsID + " (mount target)"
 				}
 
+				props := types.Properties{}
+				props.Set(types.PropertyStatus, mt.Status)
+				props.Set(types.PropertyVpcID, mt.VpcId)
+				props.Set(types.PropertyVSwitchID, mt.VswId)
+				props.Set("NetworkType", mt.NetworkType)
+				props["FileSystemId"] = fsID
+
 				res := types.Resource{
-					Removable:    NASMountTarget{Client: client, Region: region, FileSystemID: fsID},
+					Removable:    NASMountTarget{Creds: creds, Region: region, FileSystemID: fsID},
 					Region:       region,
 					ResourceID:   mtDomain, // Mount target domain is the ID
 					ResourceName: displayName,
 					ProductName:  "NASMountTarget",
+					Properties:   props,
 				}
 				allResources = append(allResources, &res)
 			}
@@ -105,12 +128,17 @@ func CollectNASMountTargets(creds *types.Credentials, region string) (types.Reso
 }
 
 // Remove deletes the NAS Mount Target
-func (mt NASMountTarget) Remove(region string, resourceID string, resourceName string) error {
+func (mt NASMountTarget) Remove(ctx context.Context, region string, resourceID string, resourceName string) error {
+	client, err := utils.GetNASClient(ctx, mt.Creds, region)
+	if err != nil {
+		return err
+	}
+
 	request := &nas.DeleteMountTargetRequest{
 		FileSystemId:      tea.String(mt.FileSystemID),
 		MountTargetDomain: tea.String(resourceID),
 	}
 
-	_, err := mt.Client.DeleteMountTarget(request)
+	_, err = client.DeleteMountTarget(request)
 	return err
 }
@@ -1,6 +1,8 @@
 package resources
 
 import (
+	"context"
+
 	"github.com/alibabacloud-go/tea/tea"
 	vpc "github.com/alibabacloud-go/vpc-20160428/v6/client"
 
@@ -10,18 +12,25 @@ import (
 )
 
 func init() {
-	infrastructure.RegisterCollector("natGateway", CollectNatGateways)
+	infrastructure.Register(infrastructure.Descriptor{
+		Name:        "NatGateway",
+		Product:     "vpc",
+		Description: "NAT gateway",
+		DependsOn:   []string{"ForwardEntry", "SnatEntry"},
+		SideEffects: "Deletes its SNAT and DNAT entries",
+		Collect:     CollectNatGateways,
+	})
 }
 
 // NatGateway represents an Alibaba Cloud NAT Gateway resource
 type NatGateway struct {
-	Client *vpc.Client
+	Creds  *types.Credentials
 	Region string
 }
 
 // CollectNatGateways discovers all NAT Gateways in the specified region
-func CollectNatGateways(creds *types.Credentials, region string) (types.Resources, error) {
-	client, err := utils.CreateVPCClient(creds, region)
+func CollectNatGateways(ctx context.Context, creds *types.Credentials, region string) (types.Resources, error) {
+	client, err := utils.GetVPCClient(ctx, creds, region)
 	if err != nil {
 		return nil, err
 	}
@@ -31,6 +40,10 @@ func CollectNatGateways(creds *types.Credentials, region string) (types.Resource
 	pageSize := int32(50)
 
 	for {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
 		request := &vpc.DescribeNatGatewaysRequest{
 			RegionId:   tea.String(region),
 			PageNumber: tea.Int32(pageNumber),
@@ -72,12 +85,32 @@ func CollectNatGateways(creds *types.Credentials, region string) (types.Resource
 			natName = natID
 		}
 
+		props := types.Properties{}
+		props.Set(types.PropertyStatus, nat.Status)
+		props.Set(types.PropertyCreationTime, nat.CreationTime)
+		props.Set(types.PropertyChargeType, nat.InstanceChargeType)
+		props.Set(types.PropertyVpcID, nat.VpcId)
+		props.Set(types.PropertyResourceGroupID, nat.ResourceGroupId)
+		props.Set("NatType", nat.NatType)
+		props.Set("Spec", nat.Spec)
+		props.Set("InternetChargeType", nat.InternetChargeType)
+		props.SetBool("DeletionProtection", nat.DeletionProtection)
+
+		tags := types.Tags{}
+		if nat.Tags != nil {
+			for _, tag := range nat.Tags.Tag {
+				tags.Set(tag.TagKey, tag.TagValue)
+			}
+		}
+
 		res := types.Resource{
-			Removable:    NatGateway{Client: client, Region: region},
+			Removable:    NatGateway{Creds: creds, Region: region},
 			Region:       region,
 			ResourceID:   natID,
 			ResourceName: natName,
 			ProductName:  "NatGateway",
+			Properties:   props,
+			Tags:         tags,
 		}
 		allResources = append(allResources, &res)
 	}
@@ -86,13 +119,38 @@ func CollectNatGateways(creds *types.Credentials, region string) (types.Resource
 }
 
 // Remove deletes the NAT Gateway
-func (n NatGateway) Remove(region string, resourceID string, resourceName string) error {
+func (n NatGateway) Remove(ctx context.Context, region string, resourceID string, resourceName string) error {
+	client, err := utils.GetVPCClient(ctx, n.Creds, region)
+	if err != nil {
+		return err
+	}
+
 	request := &vpc.DeleteNatGatewayRequest{
 		NatGatewayId: tea.String(resourceID),
 		RegionId:     tea.String(region),
 		Force:        tea.Bool(true), // Force delete even if SNAT/DNAT entries exist
 	}
 
-	_, err := n.Client.DeleteNatGateway(request)
+	_, err = client.DeleteNatGateway(request)
 	return err
 }
+
+// CheckDeletion reports whether the NAT gateway is gone
+func (n NatGateway) CheckDeletion(ctx context.Context, region string, resourceID string) (types.DeletionStatus, error) {
+	client, err := utils.GetVPCClient(ctx, n.Creds, region)
+	if err != nil {
+		return types.DeletionPending, err
+	}
+
+	response, err := client.DescribeNatGateways(&vpc.DescribeNatGatewaysRequest{
+		RegionId:     tea.String(region),
+		NatGatewayId: tea.String(resourceID),
+	})
+	if err != nil {
+		return types.DeletionPending, err
+	}
+	if response.Body == nil || response.Body.NatGateways == nil || len(response.Body.NatGateways.NatGateway) == 0 {
+		return types.DeletionComplete, nil
+	}
+	return types.DeletionPending, nil
+}
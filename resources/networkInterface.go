@@ -1,6 +1,8 @@
 package resources
 
 import (
+	"context"
+
 	ecs "github.com/alibabacloud-go/ecs-20140526/v7/client"
 	"github.com/alibabacloud-go/tea/tea"
 
@@ -10,18 +12,28 @@ import (
 )
 
 func init() {
-	infrastructure.RegisterCollector("networkInterface", CollectNetworkInterfaces)
+	infrastructure.Register(infrastructure.Descriptor{
+		Name:        "NetworkInterface",
+		Product:     "ecs",
+		Description: "Elastic network interface (ENI)",
+		DependsOn:   []string{"ECSInstance"},
+		SideEffects: "Detaches the interface from its instance first",
+		Collect:     CollectNetworkInterfaces,
+	})
+
+	// The API abbreviates network interfaces
+	types.RegisterErrorCodes("NetworkInterface", types.ErrorNotFound, "InvalidEniId.NotFound")
 }
 
 // NetworkInterface represents an Alibaba Cloud Elastic Network Interface (ENI) resource
 type NetworkInterface struct {
-	Client *ecs.Client
+	Creds  *types.Credentials
 	Region string
 }
 
 // CollectNetworkInterfaces discovers all Network Interfaces in the specified region
-func CollectNetworkInterfaces(creds *types.Credentials, region string) (types.Resources, error) {
-	client, err := utils.CreateECSClient(creds, region)
+func CollectNetworkInterfaces(ctx context.Context, creds *types.Credentials, region string) (types.Resources, error) {
+	client, err := utils.GetECSClient(ctx, creds, region)
 	if err != nil {
 		return nil, err
 	}
@@ -32,6 +44,10 @@ func CollectNetworkInterfaces(creds *types.Credentials, region string) (types.Re
 
 	// Paginate through all network interfaces
 	for {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
 		request := &ecs.DescribeNetworkInterfacesRequest{
 			RegionId:   tea.String(region),
 			PageNumber: tea.Int32(pageNumber),
@@ -84,12 +100,32 @@ func CollectNetworkInterfaces(creds *types.Credentials, region string) (types.Re
 			continue
 		}
 
+		props := types.Properties{}
+		props.Set(types.PropertyStatus, eni.Status)
+		props.Set(types.PropertyCreationTime, eni.CreationTime)
+		props.Set(types.PropertyVpcID, eni.VpcId)
+		props.Set(types.PropertyVSwitchID, eni.VSwitchId)
+		props.Set(types.PropertyZoneID, eni.ZoneId)
+		props.Set(types.PropertyResourceGroupID, eni.ResourceGroupId)
+		props.Set("Type", eni.Type)
+		props.Set("InstanceId", eni.InstanceId)
+		props.Set("PrivateIpAddress", eni.PrivateIpAddress)
+
+		tags := types.Tags{}
+		if eni.Tags != nil {
+			for _, tag := range eni.Tags.Tag {
+				tags.Set(tag.TagKey, tag.TagValue)
+			}
+		}
+
 		res := types.Resource{
-			Removable:    NetworkInterface{Client: client, Region: region},
+			Removable:    NetworkInterface{Creds: creds, Region: region},
 			Region:       region,
 			ResourceID:   eniID,
 			ResourceName: eniName,
 			ProductName:  "NetworkInterface",
+			Properties:   props,
+			Tags:         tags,
 		}
 		allResources = append(allResources, &res)
 	}
@@ -98,14 +134,19 @@ func CollectNetworkInterfaces(creds *types.Credentials, region string) (types.Re
 }
 
 // Remove deletes the Network Interface
-func (eni NetworkInterface) Remove(region string, resourceID string, resourceName string) error {
+func (eni NetworkInterface) Remove(ctx context.Context, region string, resourceID string, resourceName string) error {
+	client, err := utils.GetECSClient(ctx, eni.Creds, region)
+	if err != nil {
+		return err
+	}
+
 	// First detach the ENI if it's attached to an instance
 	detachReq := &ecs.DetachNetworkInterfaceRequest{
 		RegionId:           tea.String(region),
 		NetworkInterfaceId: tea.String(resourceID),
 	}
 	// Ignore detach errors - it may not be attached
-	eni.Client.DetachNetworkInterface(detachReq)
+	client.DetachNetworkInterface(detachReq)
 
 	// Delete the network interface
 	request := &ecs.DeleteNetworkInterfaceRequest{
@@ -113,6 +154,6 @@ func (eni NetworkInterface) Remove(region string, resourceID string, resourceNam
 		NetworkInterfaceId: tea.String(resourceID),
 	}
 
-	_, err := eni.Client.DeleteNetworkInterface(request)
+	_, err = client.DeleteNetworkInterface(request)
 	return err
 }
@@ -1,6 +1,8 @@
 package resources
 
 import (
+	"context"
+
 	nlb "github.com/alibabacloud-go/nlb-20220430/v2/client"
 	"github.com/alibabacloud-go/tea/tea"
 
@@ -10,18 +12,26 @@ import (
 )
 
 func init() {
-	infrastructure.RegisterCollector("nlb", CollectNLBInstances)
+	infrastructure.Register(infrastructure.Descriptor{
+		Name:        "NLB",
+		Product:     "nlb",
+		Description: "Network Load Balancer instance",
+		Collect:     CollectNLBInstances,
+	})
+
+	// The API calls NLB instances load balancers
+	types.RegisterErrorCodes("NLB", types.ErrorNotFound, "ResourceNotFound.LoadBalancer")
 }
 
 // NLB represents an Alibaba Cloud Network Load Balancer resource
 type NLB struct {
-	Client *nlb.Client
+	Creds  *types.Credentials
 	Region string
 }
 
 // CollectNLBInstances discovers all NLB instances in the specified region
-func CollectNLBInstances(creds *types.Credentials, region string) (types.Resources, error) {
-	client, err := utils.CreateNLBClient(creds, region)
+func CollectNLBInstances(ctx context.Context, creds *types.Credentials, region string) (types.Resources, error) {
+	client, err := utils.GetNLBClient(ctx, creds, region)
 	if err != nil {
 		return nil, err
 	}
@@ -30,6 +40,10 @@ func CollectNLBInstances(creds *types.Credentials, region string) (types.Resourc
 	nextToken := ""
 
 	for {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
 		request := &nlb.ListLoadBalancersRequest{
 			RegionId:   tea.String(region),
 			MaxResults: tea.Int32(100),
@@ -68,12 +82,27 @@ func CollectNLBInstances(creds *types.Credentials, region string) (types.Resourc
 			lbName = lbID
 		}
 
+		props := types.Properties{}
+		props.Set(types.PropertyStatus, lb.LoadBalancerStatus)
+		props.Set(types.PropertyCreationTime, lb.CreateTime)
+		props.Set(types.PropertyVpcID, lb.VpcId)
+		props.Set(types.PropertyResourceGroupID, lb.ResourceGroupId)
+		props.Set("AddressType", lb.AddressType)
+		props.Set("LoadBalancerType", lb.LoadBalancerType)
+
+		tags := types.Tags{}
+		for _, tag := range lb.Tags {
+			tags.Set(tag.Key, tag.Value)
+		}
+
 		res := types.Resource{
-			Removable:    NLB{Client: client, Region: region},
+			Removable:    NLB{Creds: creds, Region: region},
 			Region:       region,
 			ResourceID:   lbID,
 			ResourceName: lbName,
 			ProductName:  "NLB",
+			Properties:   props,
+			Tags:         tags,
 		}
 		allResources = append(allResources, &res)
 	}
@@ -82,12 +111,17 @@ func CollectNLBInstances(creds *types.Credentials, region string) (types.Resourc
 }
 
 // Remove deletes the NLB instance
-func (n NLB) Remove(region string, resourceID string, resourceName string) error {
+func (n NLB) Remove(ctx context.Context, region string, resourceID string, resourceName string) error {
+	client, err := utils.GetNLBClient(ctx, n.Creds, region)
+	if err != nil {
+		return err
+	}
+
 	request := &nlb.DeleteLoadBalancerRequest{
 		LoadBalancerId: tea.String(resourceID),
 		RegionId:       tea.String(region),
 	}
 
-	_, err := n.Client.DeleteLoadBalancer(request)
+	_, err = client.DeleteLoadBalancer(request)
 	return err
 }
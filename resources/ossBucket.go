@@ -2,7 +2,10 @@ package resources
 
 import (
 	"context"
+	"log/slog"
+	"strings"
 
+	"github.com/alibabacloud-go/tea/tea"
 	"github.com/aliyun/alibabacloud-oss-go-sdk-v2/oss"
 
 	"github.com/arafato/ali-nuke/infrastructure"
@@ -11,75 +14,131 @@ import (
 )
 
 func init() {
-	infrastructure.RegisterCollector("ossBucket", CollectOSSBuckets)
+	infrastructure.Register(infrastructure.Descriptor{
+		Name:        "OSSBucket",
+		Product:     "oss",
+		Scope:       infrastructure.ScopeGlobal,
+		Description: "OSS bucket",
+		SideEffects: "Deletes all objects and object versions of the bucket",
+		Collect:     CollectOSSBuckets,
+	})
+
+	// Objects or versions are still being removed
+	types.RegisterErrorCodes("OSSBucket", types.ErrorDependency, "BucketNotEmpty")
 }
 
 // OSSBucket represents an Alibaba Cloud OSS Bucket resource
 type OSSBucket struct {
-	Client *oss.Client
+	Creds  *types.Credentials
 	Region string
 }
 
-// CollectOSSBuckets discovers all OSS buckets
-// Note: OSS buckets are global but have a region, we filter by region
-func CollectOSSBuckets(creds *types.Credentials, region string) (types.Resources, error) {
-	client, err := utils.CreateOSSClient(creds, region)
+// CollectOSSBuckets discovers all OSS buckets of the account. ListBuckets returns the buckets
+// of all regions, so it is called once through the endpoint of the global region. Every bucket
+// gets its location as region and is accessed through the endpoint of that region.
+func CollectOSSBuckets(ctx context.Context, creds *types.Credentials, region string) (types.Resources, error) {
+	client, err := utils.GetOSSClient(ctx, creds, region)
 	if err != nil {
 		return nil, err
 	}
 
-	// List all buckets
-	request := &oss.ListBucketsRequest{}
-	response, err := client.ListBuckets(context.Background(), request)
-	if err != nil {
-		return nil, err
+	var buckets []oss.BucketProperties
+	paginator := client.NewListBucketsPaginator(&oss.ListBucketsRequest{})
+	for paginator.HasNext() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, err
+		}
+		buckets = append(buckets, page.Buckets...)
 	}
 
 	var allResources types.Resources
-	if response.Buckets != nil {
-		for _, bucket := range response.Buckets {
-			bucketName := ""
-			if bucket.Name != nil {
-				bucketName = *bucket.Name
-			}
+	for _, bucket := range buckets {
+		bucketName := ""
+		if bucket.Name != nil {
+			bucketName = *bucket.Name
+		}
 
-			// Filter by region
-			bucketRegion := ""
-			if bucket.Location != nil {
-				// Location is like "oss-cn-hangzhou", extract region
-				loc := *bucket.Location
-				if len(loc) > 4 && loc[:4] == "oss-" {
-					bucketRegion = loc[4:]
-				} else {
-					bucketRegion = loc
-				}
-			}
+		bucketRegion := tea.StringValue(bucket.Region)
+		if bucketRegion == "" && bucket.Location != nil {
+			// Location is like "oss-cn-hangzhou", extract region
+			bucketRegion = strings.TrimPrefix(*bucket.Location, "oss-")
+		}
+		if !infrastructure.RegionScanned(ctx, bucketRegion) {
+			continue
+		}
 
-			// Only include buckets in this region
-			if bucketRegion != region {
-				continue
-			}
+		// The bucket can only be managed through the endpoint of its region
+		bucketClient, err := utils.GetOSSClient(ctx, creds, bucketRegion)
+		if err != nil {
+			return nil, err
+		}
 
-			res := types.Resource{
-				Removable:    OSSBucket{Client: client, Region: region},
-				Region:       region,
-				ResourceID:   bucketName,
-				ResourceName: bucketName,
-				ProductName:  "OSSBucket",
-			}
-			allResources = append(allResources, &res)
+		props := types.Properties{}
+		props.SetTime(types.PropertyCreationTime, bucket.CreationDate)
+		props.Set(types.PropertyResourceGroupID, bucket.ResourceGroupId)
+		props.Set("StorageClass", bucket.StorageClass)
+		props.Set("Location", bucket.Location)
+
+		// ListBuckets does not return tags, fetch them per bucket. A bucket whose tags cannot
+		// be read, e.g. because of a restrictive bucket policy, is still listed, but tag rules
+		// cannot be evaluated for it.
+		tags, err := getBucketTags(ctx, bucketClient, bucketName)
+		if err != nil {
+			slog.Warn("failed to get bucket tags", append([]any{
+				utils.LogKeyAccount, creds.AccountID,
+				utils.LogKeyRegion, bucketRegion,
+				utils.LogKeyProduct, "OSSBucket",
+				utils.LogKeyResourceID, bucketName,
+			}, utils.ErrorLogAttrs(err)...)...)
+		}
+
+		res := types.Resource{
+			Removable:    OSSBucket{Creds: creds, Region: bucketRegion},
+			Region:       bucketRegion,
+			ResourceID:   bucketName,
+			ResourceName: bucketName,
+			ProductName:  "OSSBucket",
+			Properties:   props,
+			Tags:         tags,
+			TagsUnknown:  err != nil,
 		}
+		allResources = append(allResources, &res)
 	}
 
 	return allResources, nil
 }
 
+// getBucketTags returns the tags of an OSS bucket. A bucket without tag set has no tags.
+func getBucketTags(ctx context.Context, client *oss.Client, bucketName string) (types.Tags, error) {
+	result, err := client.GetBucketTags(ctx, &oss.GetBucketTagsRequest{
+		Bucket: oss.Ptr(bucketName),
+	})
+	if apiErr, ok := types.AsAPIError(err); ok && apiErr.Code == "NoSuchTagSet" {
+		return types.Tags{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	tags := types.Tags{}
+	if result.Tagging != nil && result.Tagging.TagSet != nil {
+		for _, tag := range result.Tagging.TagSet.Tags {
+			tags.Set(tag.Key, tag.Value)
+		}
+	}
+	return tags, nil
+}
+
 // Remove deletes the OSS bucket (must be empty first)
-func (o OSSBucket) Remove(region string, resourceID string, resourceName string) error {
-	ctx := context.Background()
+func (o OSSBucket) Remove(ctx context.Context, region string, resourceID string, resourceName string) error {
+	client, err := utils.GetOSSClient(ctx, o.Creds, region)
+	if err != nil {
+		return err
+	}
 
 	// First delete all objects in the bucket
-	paginator := o.Client.NewListObjectsV2Paginator(&oss.ListObjectsV2Request{
+	paginator := client.NewListObjectsV2Paginator(&oss.ListObjectsV2Request{
 		Bucket: oss.Ptr(resourceID),
 	})
 
@@ -95,7 +154,7 @@ func (o OSSBucket) Remove(region string, resourceID string, resourceName string)
 				objects = append(objects, oss.DeleteObject{Key: obj.Key})
 			}
 
-			_, err = o.Client.DeleteMultipleObjects(ctx, &oss.DeleteMultipleObjectsRequest{
+			_, err = client.DeleteMultipleObjects(ctx, &oss.DeleteMultipleObjectsRequest{
 				Bucket:  oss.Ptr(resourceID),
 				Objects: objects,
 			})
@@ -106,7 +165,7 @@ func (o OSSBucket) Remove(region string, resourceID string, resourceName string)
 	}
 
 	// Delete all versions if versioning is enabled
-	versionPaginator := o.Client.NewListObjectVersionsPaginator(&oss.ListObjectVersionsRequest{
+	versionPaginator := client.NewListObjectVersionsPaginator(&oss.ListObjectVersionsRequest{
 		Bucket: oss.Ptr(resourceID),
 	})
 
@@ -119,7 +178,7 @@ func (o OSSBucket) Remove(region string, resourceID string, resourceName string)
 
 		// Delete object versions
 		for _, version := range page.ObjectVersions {
-			_, err = o.Client.DeleteObject(ctx, &oss.DeleteObjectRequest{
+			_, err = client.DeleteObject(ctx, &oss.DeleteObjectRequest{
 				Bucket:    oss.Ptr(resourceID),
 				Key:       version.Key,
 				VersionId: version.VersionId,
@@ -131,7 +190,7 @@ func (o OSSBucket) Remove(region string, resourceID string, resourceName string)
 
 		// Delete delete markers
 		for _, marker := range page.ObjectDeleteMarkers {
-			_, err = o.Client.DeleteObject(ctx, &oss.DeleteObjectRequest{
+			_, err = client.DeleteObject(ctx, &oss.DeleteObjectRequest{
 				Bucket:    oss.Ptr(resourceID),
 				Key:       marker.Key,
 				VersionId: marker.VersionId,
@@ -143,7 +202,7 @@ func (o OSSBucket) Remove(region string, resourceID string, resourceName string)
 	}
 
 	// Now delete the bucket
-	_, err := o.Client.DeleteBucket(ctx, &oss.DeleteBucketRequest{
+	_, err = client.DeleteBucket(ctx, &oss.DeleteBucketRequest{
 		Bucket: oss.Ptr(resourceID),
 	})
 	return err
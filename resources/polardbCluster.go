@@ -1,6 +1,8 @@
 package resources
 
 import (
+	"context"
+
 	polardb "github.com/alibabacloud-go/polardb-20170801/v6/client"
 	"github.com/alibabacloud-go/tea/tea"
 
@@ -10,18 +12,23 @@ import (
 )
 
 func init() {
-	infrastructure.RegisterCollector("polardbCluster", CollectPolarDBClusters)
+	infrastructure.Register(infrastructure.Descriptor{
+		Name:        "PolarDBCluster",
+		Product:     "polardb",
+		Description: "PolarDB cluster",
+		Collect:     CollectPolarDBClusters,
+	})
 }
 
 // PolarDBCluster represents an Alibaba Cloud PolarDB Cluster resource
 type PolarDBCluster struct {
-	Client *polardb.Client
+	Creds  *types.Credentials
 	Region string
 }
 
 // CollectPolarDBClusters discovers all PolarDB clusters in the specified region
-func CollectPolarDBClusters(creds *types.Credentials, region string) (types.Resources, error) {
-	client, err := utils.CreatePolarDBClient(creds, region)
+func CollectPolarDBClusters(ctx context.Context, creds *types.Credentials, region string) (types.Resources, error) {
+	client, err := utils.GetPolarDBClient(ctx, creds, region)
 	if err != nil {
 		return nil, err
 	}
@@ -31,6 +38,10 @@ func CollectPolarDBClusters(creds *types.Credentials, region string) (types.Reso
 	pageSize := int32(50)
 
 	for {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
 		request := &polardb.DescribeDBClustersRequest{
 			RegionId:   tea.String(region),
 			PageNumber: tea.Int32(pageNumber),
@@ -72,12 +83,33 @@ func CollectPolarDBClusters(creds *types.Credentials, region string) (types.Reso
 			clusterName = clusterID
 		}
 
+		props := types.Properties{}
+		props.Set(types.PropertyStatus, cluster.DBClusterStatus)
+		props.Set(types.PropertyCreationTime, cluster.CreateTime)
+		props.Set(types.PropertyChargeType, cluster.PayType)
+		props.Set(types.PropertyVpcID, cluster.VpcId)
+		props.Set(types.PropertyVSwitchID, cluster.VswitchId)
+		props.Set(types.PropertyZoneID, cluster.ZoneId)
+		props.Set(types.PropertyResourceGroupID, cluster.ResourceGroupId)
+		props.Set("DBType", cluster.DBType)
+		props.Set("DBVersion", cluster.DBVersion)
+		props.Set("Category", cluster.Category)
+
+		tags := types.Tags{}
+		if cluster.Tags != nil {
+			for _, tag := range cluster.Tags.Tag {
+				tags.Set(tag.Key, tag.Value)
+			}
+		}
+
 		res := types.Resource{
-			Removable:    PolarDBCluster{Client: client, Region: region},
+			Removable:    PolarDBCluster{Creds: creds, Region: region},
 			Region:       region,
 			ResourceID:   clusterID,
 			ResourceName: clusterName,
 			ProductName:  "PolarDBCluster",
+			Properties:   props,
+			Tags:         tags,
 		}
 		allResources = append(allResources, &res)
 	}
@@ -86,11 +118,36 @@ func CollectPolarDBClusters(creds *types.Credentials, region string) (types.Reso
 }
 
 // Remove deletes the PolarDB cluster
-func (p PolarDBCluster) Remove(region string, resourceID string, resourceName string) error {
+func (p PolarDBCluster) Remove(ctx context.Context, region string, resourceID string, resourceName string) error {
+	client, err := utils.GetPolarDBClient(ctx, p.Creds, region)
+	if err != nil {
+		return err
+	}
+
 	request := &polardb.DeleteDBClusterRequest{
 		DBClusterId: tea.String(resourceID),
 	}
 
-	_, err := p.Client.DeleteDBCluster(request)
+	_, err = client.DeleteDBCluster(request)
 	return err
 }
+
+// CheckDeletion reports whether the PolarDB cluster is gone
+func (p PolarDBCluster) CheckDeletion(ctx context.Context, region string, resourceID string) (types.DeletionStatus, error) {
+	client, err := utils.GetPolarDBClient(ctx, p.Creds, region)
+	if err != nil {
+		return types.DeletionPending, err
+	}
+
+	response, err := client.DescribeDBClusters(&polardb.DescribeDBClustersRequest{
+		RegionId:     tea.String(region),
+		DBClusterIds: tea.String(resourceID),
+	})
+	if err != nil {
+		return types.DeletionPending, err
+	}
+	if response.Body == nil || response.Body.Items == nil || len(response.Body.Items.DBCluster) == 0 {
+		return types.DeletionComplete, nil
+	}
+	return types.DeletionPending, nil
+}
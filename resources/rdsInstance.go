@@ -1,6 +1,8 @@
 package resources
 
 import (
+	"context"
+
 	rds "github.com/alibabacloud-go/rds-20140815/v4/client"
 	"github.com/alibabacloud-go/tea/tea"
 
@@ -10,18 +12,30 @@ import (
 )
 
 func init() {
-	infrastructure.RegisterCollector("rdsInstance", CollectRDSInstances)
+	infrastructure.Register(infrastructure.Descriptor{
+		Name:        "RDSInstance",
+		Product:     "rds",
+		Description: "ApsaraDB RDS instance",
+		SideEffects: "Backups are not retained",
+		Collect:     CollectRDSInstances,
+	})
+
+	// The instance is busy, e.g. still being created or backed up
+	types.RegisterErrorCodes("RDSInstance", types.ErrorDependency, "OperationDenied.DBInstanceStatus")
+
+	// The API calls RDS instances DB instances
+	types.RegisterErrorCodes("RDSInstance", types.ErrorNotFound, "InvalidDBInstanceId.NotFound")
 }
 
 // RDSInstance represents an Alibaba Cloud RDS Instance resource
 type RDSInstance struct {
-	Client *rds.Client
+	Creds  *types.Credentials
 	Region string
 }
 
 // CollectRDSInstances discovers all RDS instances in the specified region
-func CollectRDSInstances(creds *types.Credentials, region string) (types.Resources, error) {
-	client, err := utils.CreateRDSClient(creds, region)
+func CollectRDSInstances(ctx context.Context, creds *types.Credentials, region string) (types.Resources, error) {
+	client, err := utils.GetRDSClient(ctx, creds, region)
 	if err != nil {
 		return nil, err
 	}
@@ -31,6 +45,10 @@ func CollectRDSInstances(creds *types.Credentials, region string) (types.Resourc
 	pageSize := int32(100)
 
 	for {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
 		request := &rds.DescribeDBInstancesRequest{
 			RegionId:   tea.String(region),
 			PageNumber: tea.Int32(pageNumber),
@@ -57,6 +75,18 @@ func CollectRDSInstances(creds *types.Credentials, region string) (types.Resourc
 		pageNumber++
 	}
 
+	// DescribeDBInstances does not return tags, fetch them separately
+	var instanceIDs []string
+	for _, instance := range allInstances {
+		if instance.DBInstanceId != nil {
+			instanceIDs = append(instanceIDs, *instance.DBInstanceId)
+		}
+	}
+	instanceTags, err := listRDSTags(ctx, client, region, instanceIDs)
+	if err != nil {
+		return nil, err
+	}
+
 	var allResources types.Resources
 	for _, instance := range allInstances {
 		instanceID := ""
@@ -72,12 +102,33 @@ func CollectRDSInstances(creds *types.Credentials, region string) (types.Resourc
 			instanceName = instanceID
 		}
 
+		props := types.Properties{}
+		props.Set(types.PropertyStatus, instance.DBInstanceStatus)
+		props.Set(types.PropertyCreationTime, instance.CreateTime)
+		props.Set(types.PropertyChargeType, instance.PayType)
+		props.Set(types.PropertyVpcID, instance.VpcId)
+		props.Set(types.PropertyVSwitchID, instance.VSwitchId)
+		props.Set(types.PropertyZoneID, instance.ZoneId)
+		props.Set(types.PropertyResourceGroupID, instance.ResourceGroupId)
+		props.Set("Engine", instance.Engine)
+		props.Set("EngineVersion", instance.EngineVersion)
+		props.Set("DBInstanceClass", instance.DBInstanceClass)
+		props.Set("DBInstanceType", instance.DBInstanceType)
+		props.SetBool("DeletionProtection", instance.DeletionProtection)
+
+		tags := instanceTags[instanceID]
+		if tags == nil {
+			tags = types.Tags{}
+		}
+
 		res := types.Resource{
-			Removable:    RDSInstance{Client: client, Region: region},
+			Removable:    RDSInstance{Creds: creds, Region: region},
 			Region:       region,
 			ResourceID:   instanceID,
 			ResourceName: instanceName,
 			ProductName:  "RDSInstance",
+			Properties:   props,
+			Tags:         tags,
 		}
 		allResources = append(allResources, &res)
 	}
@@ -85,14 +136,85 @@ func CollectRDSInstances(creds *types.Credentials, region string) (types.Resourc
 	return allResources, nil
 }
 
+// listRDSTags returns the tags of the given RDS instances keyed by instance ID
+func listRDSTags(ctx context.Context, client *rds.Client, region string, instanceIDs []string) (map[string]types.Tags, error) {
+	instanceTags := make(map[string]types.Tags)
+	batchSize := 50 // ListTagResources accepts up to 50 resource IDs per call
+
+	for start := 0; start < len(instanceIDs); start += batchSize {
+		end := min(start+batchSize, len(instanceIDs))
+		var nextToken *string
+
+		for {
+			if err := ctx.Err(); err != nil {
+				return nil, err
+			}
+
+			request := &rds.ListTagResourcesRequest{
+				RegionId:     tea.String(region),
+				ResourceType: tea.String("INSTANCE"),
+				ResourceId:   tea.StringSlice(instanceIDs[start:end]),
+				NextToken:    nextToken,
+			}
+
+			response, err := client.ListTagResources(request)
+			if err != nil {
+				return nil, err
+			}
+
+			if response.Body != nil && response.Body.TagResources != nil {
+				for _, tagResource := range response.Body.TagResources.TagResource {
+					id := tea.StringValue(tagResource.ResourceId)
+					if instanceTags[id] == nil {
+						instanceTags[id] = types.Tags{}
+					}
+					instanceTags[id].Set(tagResource.TagKey, tagResource.TagValue)
+				}
+			}
+
+			if response.Body == nil || tea.StringValue(response.Body.NextToken) == "" {
+				break
+			}
+			nextToken = response.Body.NextToken
+		}
+	}
+
+	return instanceTags, nil
+}
+
 // Remove deletes the RDS instance
-func (r RDSInstance) Remove(region string, resourceID string, resourceName string) error {
+func (r RDSInstance) Remove(ctx context.Context, region string, resourceID string, resourceName string) error {
+	client, err := utils.GetRDSClient(ctx, r.Creds, region)
+	if err != nil {
+		return err
+	}
+
 	// First release the instance (for pay-as-you-go instances)
 	request := &rds.DeleteDBInstanceRequest{
 		DBInstanceId:       tea.String(resourceID),
 		ReleasedKeepPolicy: tea.String("None"), // Don't keep backups
 	}
 
-	_, err := r.Client.DeleteDBInstance(request)
+	_, err = client.DeleteDBInstance(request)
 	return err
 }
+
+// CheckDeletion reports whether the RDS instance is gone
+func (r RDSInstance) CheckDeletion(ctx context.Context, region string, resourceID string) (types.DeletionStatus, error) {
+	client, err := utils.GetRDSClient(ctx, r.Creds, region)
+	if err != nil {
+		return types.DeletionPending, err
+	}
+
+	response, err := client.DescribeDBInstances(&rds.DescribeDBInstancesRequest{
+		RegionId:     tea.String(region),
+		DBInstanceId: tea.String(resourceID),
+	})
+	if err != nil {
+		return types.DeletionPending, err
+	}
+	if response.Body == nil || response.Body.Items == nil || len(response.Body.Items.DBInstance) == 0 {
+		return types.DeletionComplete, nil
+	}
+	return types.DeletionPending, nil
+}
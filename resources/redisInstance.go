@@ -1,6 +1,8 @@
 package resources
 
 import (
+	"context"
+
 	r_kvstore "github.com/alibabacloud-go/r-kvstore-20150101/v4/client"
 	"github.com/alibabacloud-go/tea/tea"
 
@@ -10,18 +12,23 @@ import (
 )
 
 func init() {
-	infrastructure.RegisterCollector("redisInstance", CollectRedisInstances)
+	infrastructure.Register(infrastructure.Descriptor{
+		Name:        "RedisInstance",
+		Product:     "r-kvstore",
+		Description: "ApsaraDB for Redis (Tair) instance",
+		Collect:     CollectRedisInstances,
+	})
 }
 
 // RedisInstance represents an Alibaba Cloud Redis Instance resource
 type RedisInstance struct {
-	Client *r_kvstore.Client
+	Creds  *types.Credentials
 	Region string
 }
 
 // CollectRedisInstances discovers all Redis instances in the specified region
-func CollectRedisInstances(creds *types.Credentials, region string) (types.Resources, error) {
-	client, err := utils.CreateRedisClient(creds, region)
+func CollectRedisInstances(ctx context.Context, creds *types.Credentials, region string) (types.Resources, error) {
+	client, err := utils.GetRedisClient(ctx, creds, region)
 	if err != nil {
 		return nil, err
 	}
@@ -31,6 +38,10 @@ func CollectRedisInstances(creds *types.Credentials, region string) (types.Resou
 	pageSize := int32(50)
 
 	for {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
 		request := &r_kvstore.DescribeInstancesRequest{
 			RegionId:   tea.String(region),
 			PageNumber: tea.Int32(pageNumber),
@@ -72,12 +83,33 @@ func CollectRedisInstances(creds *types.Credentials, region string) (types.Resou
 			instanceName = instanceID
 		}
 
+		props := types.Properties{}
+		props.Set(types.PropertyStatus, instance.InstanceStatus)
+		props.Set(types.PropertyCreationTime, instance.CreateTime)
+		props.Set(types.PropertyChargeType, instance.ChargeType)
+		props.Set(types.PropertyVpcID, instance.VpcId)
+		props.Set(types.PropertyVSwitchID, instance.VSwitchId)
+		props.Set(types.PropertyZoneID, instance.ZoneId)
+		props.Set(types.PropertyResourceGroupID, instance.ResourceGroupId)
+		props.Set("InstanceType", instance.InstanceType)
+		props.Set("EngineVersion", instance.EngineVersion)
+		props.Set("InstanceClass", instance.InstanceClass)
+
+		tags := types.Tags{}
+		if instance.Tags != nil {
+			for _, tag := range instance.Tags.Tag {
+				tags.Set(tag.Key, tag.Value)
+			}
+		}
+
 		res := types.Resource{
-			Removable:    RedisInstance{Client: client, Region: region},
+			Removable:    RedisInstance{Creds: creds, Region: region},
 			Region:       region,
 			ResourceID:   instanceID,
 			ResourceName: instanceName,
 			ProductName:  "RedisInstance",
+			Properties:   props,
+			Tags:         tags,
 		}
 		allResources = append(allResources, &res)
 	}
@@ -86,11 +118,36 @@ func CollectRedisInstances(creds *types.Credentials, region string) (types.Resou
 }
 
 // Remove deletes the Redis instance
-func (r RedisInstance) Remove(region string, resourceID string, resourceName string) error {
+func (r RedisInstance) Remove(ctx context.Context, region string, resourceID string, resourceName string) error {
+	client, err := utils.GetRedisClient(ctx, r.Creds, region)
+	if err != nil {
+		return err
+	}
+
 	request := &r_kvstore.DeleteInstanceRequest{
 		InstanceId: tea.String(resourceID),
 	}
 
-	_, err := r.Client.DeleteInstance(request)
+	_, err = client.DeleteInstance(request)
 	return err
 }
+
+// CheckDeletion reports whether the Redis instance is gone
+func (r RedisInstance) CheckDeletion(ctx context.Context, region string, resourceID string) (types.DeletionStatus, error) {
+	client, err := utils.GetRedisClient(ctx, r.Creds, region)
+	if err != nil {
+		return types.DeletionPending, err
+	}
+
+	response, err := client.DescribeInstances(&r_kvstore.DescribeInstancesRequest{
+		RegionId:    tea.String(region),
+		InstanceIds: tea.String(resourceID),
+	})
+	if err != nil {
+		return types.DeletionPending, err
+	}
+	if response.Body == nil || response.Body.Instances == nil || len(response.Body.Instances.KVStoreInstance) == 0 {
+		return types.DeletionComplete, nil
+	}
+	return types.DeletionPending, nil
+}
@@ -1,6 +1,8 @@
 package resources
 
 import (
+	"context"
+
 	"github.com/alibabacloud-go/tea/tea"
 	vpc "github.com/alibabacloud-go/vpc-20160428/v6/client"
 
@@ -10,18 +12,24 @@ import (
 )
 
 func init() {
-	infrastructure.RegisterCollector("routeTable", CollectRouteTables)
+	infrastructure.Register(infrastructure.Descriptor{
+		Name:        "RouteTable",
+		Product:     "vpc",
+		Description: "Custom VPC route table",
+		DependsOn:   []string{"VSwitch"},
+		Collect:     CollectRouteTables,
+	})
 }
 
 // RouteTable represents an Alibaba Cloud Route Table resource
 type RouteTable struct {
-	Client *vpc.Client
+	Creds  *types.Credentials
 	Region string
 }
 
 // CollectRouteTables discovers all Route Tables in the specified region
-func CollectRouteTables(creds *types.Credentials, region string) (types.Resources, error) {
-	client, err := utils.CreateVPCClient(creds, region)
+func CollectRouteTables(ctx context.Context, creds *types.Credentials, region string) (types.Resources, error) {
+	client, err := utils.GetVPCClient(ctx, creds, region)
 	if err != nil {
 		return nil, err
 	}
@@ -32,6 +40,10 @@ func CollectRouteTables(creds *types.Credentials, region string) (types.Resource
 
 	// Paginate through all route tables
 	for {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
 		request := &vpc.DescribeRouteTableListRequest{
 			RegionId:   tea.String(region),
 			PageNumber: tea.Int32(pageNumber),
@@ -82,12 +94,29 @@ func CollectRouteTables(creds *types.Credentials, region string) (types.Resource
 			isSystem = true
 		}
 
+		props := types.Properties{}
+		props.Set(types.PropertyStatus, rt.Status)
+		props.Set(types.PropertyCreationTime, rt.CreationTime)
+		props.Set(types.PropertyVpcID, rt.VpcId)
+		props.Set(types.PropertyResourceGroupID, rt.ResourceGroupId)
+		props.Set("RouteTableType", rt.RouteTableType)
+		props.Set("AssociateType", rt.AssociateType)
+
+		tags := types.Tags{}
+		if rt.Tags != nil {
+			for _, tag := range rt.Tags.Tag {
+				tags.Set(tag.Key, tag.Value)
+			}
+		}
+
 		res := types.Resource{
-			Removable:    RouteTable{Client: client, Region: region},
+			Removable:    RouteTable{Creds: creds, Region: region},
 			Region:       region,
 			ResourceID:   rtID,
 			ResourceName: rtName,
 			ProductName:  "RouteTable",
+			Properties:   props,
+			Tags:         tags,
 		}
 
 		// Hide system route tables - they cannot be deleted
@@ -102,12 +131,17 @@ func CollectRouteTables(creds *types.Credentials, region string) (types.Resource
 }
 
 // Remove deletes the Route Table
-func (rt RouteTable) Remove(region string, resourceID string, resourceName string) error {
+func (rt RouteTable) Remove(ctx context.Context, region string, resourceID string, resourceName string) error {
+	client, err := utils.GetVPCClient(ctx, rt.Creds, region)
+	if err != nil {
+		return err
+	}
+
 	request := &vpc.DeleteRouteTableRequest{
 		RouteTableId: tea.String(resourceID),
 		RegionId:     tea.String(region),
 	}
 
-	_, err := rt.Client.DeleteRouteTable(request)
+	_, err = client.DeleteRouteTable(request)
 	return err
 }
@@ -1,6 +1,8 @@
 package resources
 
 import (
+	"context"
+
 	"github.com/alibabacloud-go/tea/tea"
 	vpc "github.com/alibabacloud-go/vpc-20160428/v6/client"
 
@@ -10,18 +12,23 @@ import (
 )
 
 func init() {
-	infrastructure.RegisterCollector("routerInterface", CollectRouterInterfaces)
+	infrastructure.Register(infrastructure.Descriptor{
+		Name:        "RouterInterface",
+		Product:     "vpc",
+		Description: "Router interface for VPC peering",
+		Collect:     CollectRouterInterfaces,
+	})
 }
 
 // RouterInterface represents an Alibaba Cloud Router Interface resource (used for VPC peering)
 type RouterInterface struct {
-	Client *vpc.Client
+	Creds  *types.Credentials
 	Region string
 }
 
 // CollectRouterInterfaces discovers all Router Interfaces in the specified region
-func CollectRouterInterfaces(creds *types.Credentials, region string) (types.Resources, error) {
-	client, err := utils.CreateVPCClient(creds, region)
+func CollectRouterInterfaces(ctx context.Context, creds *types.Credentials, region string) (types.Resources, error) {
+	client, err := utils.GetVPCClient(ctx, creds, region)
 	if err != nil {
 		return nil, err
 	}
@@ -32,6 +39,10 @@ func CollectRouterInterfaces(creds *types.Credentials, region string) (types.Res
 
 	// Paginate through all router interfaces
 	for {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
 		request := &vpc.DescribeRouterInterfacesRequest{
 			RegionId:   tea.String(region),
 			PageNumber: tea.Int32(pageNumber),
@@ -84,12 +95,32 @@ func CollectRouterInterfaces(creds *types.Credentials, region string) (types.Res
 			riName = riID + " (" + role + ")"
 		}
 
+		props := types.Properties{}
+		props.Set(types.PropertyStatus, ri.Status)
+		props.Set(types.PropertyCreationTime, ri.CreationTime)
+		props.Set(types.PropertyChargeType, ri.ChargeType)
+		props.Set(types.PropertyVpcID, ri.VpcInstanceId)
+		props.Set(types.PropertyResourceGroupID, ri.ResourceGroupId)
+		props.Set("Role", ri.Role)
+		props.Set("Spec", ri.Spec)
+		props.Set("RouterType", ri.RouterType)
+		props.Set("OppositeRegionId", ri.OppositeRegionId)
+
+		tags := types.Tags{}
+		if ri.Tags != nil {
+			for _, tag := range ri.Tags.Tags {
+				tags.Set(tag.Key, tag.Value)
+			}
+		}
+
 		res := types.Resource{
-			Removable:    RouterInterface{Client: client, Region: region},
+			Removable:    RouterInterface{Creds: creds, Region: region},
 			Region:       region,
 			ResourceID:   riID,
 			ResourceName: riName,
 			ProductName:  "RouterInterface",
+			Properties:   props,
+			Tags:         tags,
 		}
 		allResources = append(allResources, &res)
 	}
@@ -98,14 +129,19 @@ func CollectRouterInterfaces(creds *types.Credentials, region string) (types.Res
 }
 
 // Remove deletes the Router Interface
-func (ri RouterInterface) Remove(region string, resourceID string, resourceName string) error {
+func (ri RouterInterface) Remove(ctx context.Context, region string, resourceID string, resourceName string) error {
+	client, err := utils.GetVPCClient(ctx, ri.Creds, region)
+	if err != nil {
+		return err
+	}
+
 	// First deactivate the router interface if it's active
 	deactivateReq := &vpc.DeactivateRouterInterfaceRequest{
 		RouterInterfaceId: tea.String(resourceID),
 		RegionId:          tea.String(region),
 	}
 	// Ignore deactivation errors - it may already be inactive
-	ri.Client.DeactivateRouterInterface(deactivateReq)
+	client.DeactivateRouterInterface(deactivateReq)
 
 	// Delete the router interface
 	request := &vpc.DeleteRouterInterfaceRequest{
@@ -113,6 +149,6 @@ func (ri RouterInterface) Remove(region string, resourceID string, resourceName
 		RegionId:          tea.String(region),
 	}
 
-	_, err := ri.Client.DeleteRouterInterface(request)
+	_, err = client.DeleteRouterInterface(request)
 	return err
 }
@@ -1,6 +1,8 @@
 package resources
 
 import (
+	"context"
+
 	ess "github.com/alibabacloud-go/ess-20220222/v2/client"
 	"github.com/alibabacloud-go/tea/tea"
 
@@ -10,18 +12,27 @@ import (
 )
 
 func init() {
-	infrastructure.RegisterCollector("scalingConfiguration", CollectScalingConfigurations)
+	infrastructure.Register(infrastructure.Descriptor{
+		Name:        "ScalingConfiguration",
+		Product:     "ess",
+		Description: "Auto Scaling configuration",
+		DependsOn:   []string{"ScalingGroup"},
+		Collect:     CollectScalingConfigurations,
+	})
+
+	// The configuration was deleted together with its scaling group
+	types.RegisterErrorCodes("ScalingConfiguration", types.ErrorNotFound, "InvalidScalingGroupId.NotFound")
 }
 
 // ScalingConfiguration represents an Alibaba Cloud Auto Scaling Configuration resource
 type ScalingConfiguration struct {
-	Client *ess.Client
+	Creds  *types.Credentials
 	Region string
 }
 
 // CollectScalingConfigurations discovers all Scaling Configurations in the specified region
-func CollectScalingConfigurations(creds *types.Credentials, region string) (types.Resources, error) {
-	client, err := utils.CreateESSClient(creds, region)
+func CollectScalingConfigurations(ctx context.Context, creds *types.Credentials, region string) (types.Resources, error) {
+	client, err := utils.GetESSClient(ctx, creds, region)
 	if err != nil {
 		return nil, err
 	}
@@ -31,6 +42,10 @@ func CollectScalingConfigurations(creds *types.Credentials, region string) (type
 	pageSize := int32(50)
 
 	for {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
 		request := &ess.DescribeScalingConfigurationsRequest{
 			RegionId:   tea.String(region),
 			PageNumber: tea.Int32(pageNumber),
@@ -72,12 +87,29 @@ func CollectScalingConfigurations(creds *types.Credentials, region string) (type
 			configName = configID
 		}
 
+		props := types.Properties{}
+		props.Set(types.PropertyStatus, config.LifecycleState)
+		props.Set(types.PropertyCreationTime, config.CreationTime)
+		props.Set(types.PropertyZoneID, config.ZoneId)
+		props.Set(types.PropertyResourceGroupID, config.ResourceGroupId)
+		props.Set("ScalingGroupId", config.ScalingGroupId)
+		props.Set("InstanceType", config.InstanceType)
+		props.Set("ImageId", config.ImageId)
+		props.Set("SecurityGroupId", config.SecurityGroupId)
+
+		tags := types.Tags{}
+		for _, tag := range config.Tags {
+			tags.Set(tag.Key, tag.Value)
+		}
+
 		res := types.Resource{
-			Removable:    ScalingConfiguration{Client: client, Region: region},
+			Removable:    ScalingConfiguration{Creds: creds, Region: region},
 			Region:       region,
 			ResourceID:   configID,
 			ResourceName: configName,
 			ProductName:  "ScalingConfiguration",
+			Properties:   props,
+			Tags:         tags,
 		}
 		allResources = append(allResources, &res)
 	}
@@ -86,11 +118,16 @@ func CollectScalingConfigurations(creds *types.Credentials, region string) (type
 }
 
 // Remove deletes the Scaling Configuration
-func (s ScalingConfiguration) Remove(region string, resourceID string, resourceName string) error {
+func (s ScalingConfiguration) Remove(ctx context.Context, region string, resourceID string, resourceName string) error {
+	client, err := utils.GetESSClient(ctx, s.Creds, region)
+	if err != nil {
+		return err
+	}
+
 	request := &ess.DeleteScalingConfigurationRequest{
 		ScalingConfigurationId: tea.String(resourceID),
 	}
 
-	_, err := s.Client.DeleteScalingConfiguration(request)
+	_, err = client.DeleteScalingConfiguration(request)
 	return err
 }
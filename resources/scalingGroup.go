@@ -1,6 +1,8 @@
 package resources
 
 import (
+	"context"
+
 	ess "github.com/alibabacloud-go/ess-20220222/v2/client"
 	"github.com/alibabacloud-go/tea/tea"
 
@@ -10,18 +12,24 @@ import (
 )
 
 func init() {
-	infrastructure.RegisterCollector("scalingGroup", CollectScalingGroups)
+	infrastructure.Register(infrastructure.Descriptor{
+		Name:        "ScalingGroup",
+		Product:     "ess",
+		Description: "Auto Scaling group",
+		SideEffects: "Releases the instances created by the group",
+		Collect:     CollectScalingGroups,
+	})
 }
 
 // ScalingGroup represents an Alibaba Cloud Auto Scaling Group resource
 type ScalingGroup struct {
-	Client *ess.Client
+	Creds  *types.Credentials
 	Region string
 }
 
 // CollectScalingGroups discovers all Auto Scaling Groups in the specified region
-func CollectScalingGroups(creds *types.Credentials, region string) (types.Resources, error) {
-	client, err := utils.CreateESSClient(creds, region)
+func CollectScalingGroups(ctx context.Context, creds *types.Credentials, region string) (types.Resources, error) {
+	client, err := utils.GetESSClient(ctx, creds, region)
 	if err != nil {
 		return nil, err
 	}
@@ -31,6 +39,10 @@ func CollectScalingGroups(creds *types.Credentials, region string) (types.Resour
 	pageSize := int32(50)
 
 	for {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
 		request := &ess.DescribeScalingGroupsRequest{
 			RegionId:   tea.String(region),
 			PageNumber: tea.Int32(pageNumber),
@@ -72,12 +84,30 @@ func CollectScalingGroups(creds *types.Credentials, region string) (types.Resour
 			groupName = groupID
 		}
 
+		props := types.Properties{}
+		props.Set(types.PropertyStatus, group.LifecycleState)
+		props.Set(types.PropertyCreationTime, group.CreationTime)
+		props.Set(types.PropertyVpcID, group.VpcId)
+		props.Set(types.PropertyVSwitchID, group.VSwitchId)
+		props.Set(types.PropertyResourceGroupID, group.ResourceGroupId)
+		props.SetInt32("MinSize", group.MinSize)
+		props.SetInt32("MaxSize", group.MaxSize)
+		props.SetInt32("TotalCapacity", group.TotalCapacity)
+		props.SetBool("DeletionProtection", group.GroupDeletionProtection)
+
+		tags := types.Tags{}
+		for _, tag := range group.Tags {
+			tags.Set(tag.TagKey, tag.TagValue)
+		}
+
 		res := types.Resource{
-			Removable:    ScalingGroup{Client: client, Region: region},
+			Removable:    ScalingGroup{Creds: creds, Region: region},
 			Region:       region,
 			ResourceID:   groupID,
 			ResourceName: groupName,
 			ProductName:  "ScalingGroup",
+			Properties:   props,
+			Tags:         tags,
 		}
 		allResources = append(allResources, &res)
 	}
@@ -86,12 +116,17 @@ func CollectScalingGroups(creds *types.Credentials, region string) (types.Resour
 }
 
 // Remove deletes the Scaling Group
-func (s ScalingGroup) Remove(region string, resourceID string, resourceName string) error {
+func (s ScalingGroup) Remove(ctx context.Context, region string, resourceID string, resourceName string) error {
+	client, err := utils.GetESSClient(ctx, s.Creds, region)
+	if err != nil {
+		return err
+	}
+
 	// First disable the scaling group
 	disableReq := &ess.DisableScalingGroupRequest{
 		ScalingGroupId: tea.String(resourceID),
 	}
-	s.Client.DisableScalingGroup(disableReq)
+	client.DisableScalingGroup(disableReq)
 
 	// Delete the scaling group with force
 	request := &ess.DeleteScalingGroupRequest{
@@ -99,6 +134,6 @@ func (s ScalingGroup) Remove(region string, resourceID string, resourceName stri
 		ForceDelete:    tea.Bool(true), // Force delete instances in the group
 	}
 
-	_, err := s.Client.DeleteScalingGroup(request)
+	_, err = client.DeleteScalingGroup(request)
 	return err
 }
@@ -1,6 +1,8 @@
 package resources
 
 import (
+	"context"
+
 	ecs "github.com/alibabacloud-go/ecs-20140526/v7/client"
 	"github.com/alibabacloud-go/tea/tea"
 
@@ -10,18 +12,24 @@ import (
 )
 
 func init() {
-	infrastructure.RegisterCollector("securityGroup", CollectSecurityGroups)
+	infrastructure.Register(infrastructure.Descriptor{
+		Name:        "SecurityGroup",
+		Product:     "ecs",
+		Description: "ECS security group",
+		DependsOn:   []string{"ECSInstance", "NetworkInterface", "ScalingConfiguration", "ACKCluster"},
+		Collect:     CollectSecurityGroups,
+	})
 }
 
 // SecurityGroup represents an Alibaba Cloud Security Group resource
 type SecurityGroup struct {
-	Client *ecs.Client
+	Creds  *types.Credentials
 	Region string
 }
 
 // CollectSecurityGroups discovers all Security Groups in the specified region
-func CollectSecurityGroups(creds *types.Credentials, region string) (types.Resources, error) {
-	client, err := utils.CreateECSClient(creds, region)
+func CollectSecurityGroups(ctx context.Context, creds *types.Credentials, region string) (types.Resources, error) {
+	client, err := utils.GetECSClient(ctx, creds, region)
 	if err != nil {
 		return nil, err
 	}
@@ -32,6 +40,10 @@ func CollectSecurityGroups(creds *types.Credentials, region string) (types.Resou
 
 	// Paginate through all security groups
 	for {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
 		request := &ecs.DescribeSecurityGroupsRequest{
 			RegionId:   tea.String(region),
 			PageNumber: tea.Int32(pageNumber),
@@ -75,12 +87,29 @@ func CollectSecurityGroups(creds *types.Credentials, region string) (types.Resou
 			sgName = sgID
 		}
 
+		props := types.Properties{}
+		props.Set(types.PropertyCreationTime, sg.CreationTime)
+		props.Set(types.PropertyVpcID, sg.VpcId)
+		props.Set(types.PropertyResourceGroupID, sg.ResourceGroupId)
+		props.Set("SecurityGroupType", sg.SecurityGroupType)
+		props.SetInt32("EcsCount", sg.EcsCount)
+		props.SetBool("ServiceManaged", sg.ServiceManaged)
+
+		tags := types.Tags{}
+		if sg.Tags != nil {
+			for _, tag := range sg.Tags.Tag {
+				tags.Set(tag.TagKey, tag.TagValue)
+			}
+		}
+
 		res := types.Resource{
-			Removable:    SecurityGroup{Client: client, Region: region},
+			Removable:    SecurityGroup{Creds: creds, Region: region},
 			Region:       region,
 			ResourceID:   sgID,
 			ResourceName: sgName,
 			ProductName:  "SecurityGroup",
+			Properties:   props,
+			Tags:         tags,
 		}
 		allResources = append(allResources, &res)
 	}
@@ -89,12 +118,17 @@ func CollectSecurityGroups(creds *types.Credentials, region string) (types.Resou
 }
 
 // Remove deletes the Security Group
-func (sg SecurityGroup) Remove(region string, resourceID string, resourceName string) error {
+func (sg SecurityGroup) Remove(ctx context.Context, region string, resourceID string, resourceName string) error {
+	client, err := utils.GetECSClient(ctx, sg.Creds, region)
+	if err != nil {
+		return err
+	}
+
 	request := &ecs.DeleteSecurityGroupRequest{
 		SecurityGroupId: tea.String(resourceID),
 		RegionId:        tea.String(region),
 	}
 
-	_, err := sg.Client.DeleteSecurityGroup(request)
+	_, err = client.DeleteSecurityGroup(request)
 	return err
 }
@@ -1,6 +1,8 @@
 package resources
 
 import (
+	"context"
+
 	slb "github.com/alibabacloud-go/slb-20140515/v4/client"
 	"github.com/alibabacloud-go/tea/tea"
 
@@ -10,18 +12,26 @@ import (
 )
 
 func init() {
-	infrastructure.RegisterCollector("slb", CollectSLBInstances)
+	infrastructure.Register(infrastructure.Descriptor{
+		Name:        "SLB",
+		Product:     "slb",
+		Description: "Classic Load Balancer (SLB) instance",
+		Collect:     CollectSLBInstances,
+	})
+
+	// The API calls SLB instances load balancers
+	types.RegisterErrorCodes("SLB", types.ErrorNotFound, "InvalidLoadBalancerId.NotFound")
 }
 
 // SLB represents an Alibaba Cloud Classic Load Balancer (SLB) resource
 type SLB struct {
-	Client *slb.Client
+	Creds  *types.Credentials
 	Region string
 }
 
 // CollectSLBInstances discovers all SLB instances in the specified region
-func CollectSLBInstances(creds *types.Credentials, region string) (types.Resources, error) {
-	client, err := utils.CreateSLBClient(creds, region)
+func CollectSLBInstances(ctx context.Context, creds *types.Credentials, region string) (types.Resources, error) {
+	client, err := utils.GetSLBClient(ctx, creds, region)
 	if err != nil {
 		return nil, err
 	}
@@ -31,6 +41,10 @@ func CollectSLBInstances(creds *types.Credentials, region string) (types.Resourc
 	pageSize := int32(100)
 
 	for {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
 		request := &slb.DescribeLoadBalancersRequest{
 			RegionId:   tea.String(region),
 			PageNumber: tea.Int32(pageNumber),
@@ -72,12 +86,34 @@ func CollectSLBInstances(creds *types.Credentials, region string) (types.Resourc
 			lbName = lbID
 		}
 
+		props := types.Properties{}
+		props.Set(types.PropertyStatus, lb.LoadBalancerStatus)
+		props.Set(types.PropertyCreationTime, lb.CreateTime)
+		props.Set(types.PropertyChargeType, lb.PayType)
+		props.Set(types.PropertyVpcID, lb.VpcId)
+		props.Set(types.PropertyVSwitchID, lb.VSwitchId)
+		props.Set(types.PropertyZoneID, lb.MasterZoneId)
+		props.Set(types.PropertyResourceGroupID, lb.ResourceGroupId)
+		props.Set("AddressType", lb.AddressType)
+		props.Set("Address", lb.Address)
+		props.Set("LoadBalancerSpec", lb.LoadBalancerSpec)
+		props.Set("DeletionProtection", lb.DeleteProtection)
+
+		tags := types.Tags{}
+		if lb.Tags != nil {
+			for _, tag := range lb.Tags.Tag {
+				tags.Set(tag.TagKey, tag.TagValue)
+			}
+		}
+
 		res := types.Resource{
-			Removable:    SLB{Client: client, Region: region},
+			Removable:    SLB{Creds: creds, Region: region},
 			Region:       region,
 			ResourceID:   lbID,
 			ResourceName: lbName,
 			ProductName:  "SLB",
+			Properties:   props,
+			Tags:         tags,
 		}
 		allResources = append(allResources, &res)
 	}
@@ -86,12 +122,17 @@ func CollectSLBInstances(creds *types.Credentials, region string) (types.Resourc
 }
 
 // Remove deletes the SLB instance
-func (s SLB) Remove(region string, resourceID string, resourceName string) error {
+func (s SLB) Remove(ctx context.Context, region string, resourceID string, resourceName string) error {
+	client, err := utils.GetSLBClient(ctx, s.Creds, region)
+	if err != nil {
+		return err
+	}
+
 	request := &slb.DeleteLoadBalancerRequest{
 		LoadBalancerId: tea.String(resourceID),
 		RegionId:       tea.String(region),
 	}
 
-	_, err := s.Client.DeleteLoadBalancer(request)
+	_, err = client.DeleteLoadBalancer(request)
 	return err
 }
@@ -1,6 +1,8 @@
 package resources
 
 import (
+	"context"
+
 	ecs "github.com/alibabacloud-go/ecs-20140526/v7/client"
 	"github.com/alibabacloud-go/tea/tea"
 
@@ -10,18 +12,25 @@ import (
 )
 
 func init() {
-	infrastructure.RegisterCollector("snapshot", CollectSnapshots)
+	infrastructure.Register(infrastructure.Descriptor{
+		Name:        "Snapshot",
+		Product:     "ecs",
+		Description: "ECS disk snapshot",
+		DependsOn:   []string{"Image"},
+		SideEffects: "Also deletes snapshots used by custom images",
+		Collect:     CollectSnapshots,
+	})
 }
 
 // Snapshot represents an Alibaba Cloud ECS Snapshot resource
 type Snapshot struct {
-	Client *ecs.Client
+	Creds  *types.Credentials
 	Region string
 }
 
 // CollectSnapshots discovers all Snapshots in the specified region
-func CollectSnapshots(creds *types.Credentials, region string) (types.Resources, error) {
-	client, err := utils.CreateECSClient(creds, region)
+func CollectSnapshots(ctx context.Context, creds *types.Credentials, region string) (types.Resources, error) {
+	client, err := utils.GetECSClient(ctx, creds, region)
 	if err != nil {
 		return nil, err
 	}
@@ -31,6 +40,10 @@ func CollectSnapshots(creds *types.Credentials, region string) (types.Resources,
 	pageSize := int32(100)
 
 	for {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
 		request := &ecs.DescribeSnapshotsRequest{
 			RegionId:   tea.String(region),
 			PageNumber: tea.Int32(pageNumber),
@@ -72,12 +85,31 @@ func CollectSnapshots(creds *types.Credentials, region string) (types.Resources,
 			snapshotName = snapshotID
 		}
 
+		props := types.Properties{}
+		props.Set(types.PropertyStatus, snapshot.Status)
+		props.Set(types.PropertyCreationTime, snapshot.CreationTime)
+		props.Set(types.PropertyResourceGroupID, snapshot.ResourceGroupId)
+		props.Set("SourceDiskId", snapshot.SourceDiskId)
+		props.Set("SnapshotType", snapshot.SnapshotType)
+		props.Set("Usage", snapshot.Usage)
+		props.Set("Category", snapshot.Category)
+		props.SetInt32("RetentionDays", snapshot.RetentionDays)
+
+		tags := types.Tags{}
+		if snapshot.Tags != nil {
+			for _, tag := range snapshot.Tags.Tag {
+				tags.Set(tag.TagKey, tag.TagValue)
+			}
+		}
+
 		res := types.Resource{
-			Removable:    Snapshot{Client: client, Region: region},
+			Removable:    Snapshot{Creds: creds, Region: region},
 			Region:       region,
 			ResourceID:   snapshotID,
 			ResourceName: snapshotName,
 			ProductName:  "Snapshot",
+			Properties:   props,
+			Tags:         tags,
 		}
 		allResources = append(allResources, &res)
 	}
@@ -86,12 +118,17 @@ func CollectSnapshots(creds *types.Credentials, region string) (types.Resources,
 }
 
 // Remove deletes the Snapshot
-func (s Snapshot) Remove(region string, resourceID string, resourceName string) error {
+func (s Snapshot) Remove(ctx context.Context, region string, resourceID string, resourceName string) error {
+	client, err := utils.GetECSClient(ctx, s.Creds, region)
+	if err != nil {
+		return err
+	}
+
 	request := &ecs.DeleteSnapshotRequest{
 		SnapshotId: tea.String(resourceID),
 		Force:      tea.Bool(true), // Force delete even if used by custom images
 	}
 
-	_, err := s.Client.DeleteSnapshot(request)
+	_, err = client.DeleteSnapshot(request)
 	return err
 }
@@ -1,6 +1,8 @@
 package resources
 
 import (
+	"context"
+
 	"github.com/alibabacloud-go/tea/tea"
 	vpc "github.com/alibabacloud-go/vpc-20160428/v6/client"
 
@@ -10,19 +12,27 @@ import (
 )
 
 func init() {
-	infrastructure.RegisterCollector("snatEntry", CollectSnatEntries)
+	infrastructure.Register(infrastructure.Descriptor{
+		Name:        "SnatEntry",
+		Product:     "vpc",
+		Description: "SNAT entry of a NAT gateway",
+		Collect:     CollectSnatEntries,
+	})
+
+	// The entry was deleted together with its NAT gateway
+	types.RegisterErrorCodes("SnatEntry", types.ErrorNotFound, "InvalidSnatTableId.NotFound")
 }
 
 // SnatEntry represents an Alibaba Cloud SNAT Entry resource
 type SnatEntry struct {
-	Client      *vpc.Client
+	Creds       *types.Credentials
 	Region      string
 	SnatTableId string
 }
 
 // CollectSnatEntries discovers all SNAT Entries in the specified region
-func CollectSnatEntries(creds *types.Credentials, region string) (types.Resources, error) {
-	client, err := utils.CreateVPCClient(creds, region)
+func CollectSnatEntries(ctx context.Context, creds *types.Credentials, region string) (types.Resources, error) {
+	client, err := utils.GetVPCClient(ctx, creds, region)
 	if err != nil {
 		return nil, err
 	}
@@ -33,6 +43,10 @@ func CollectSnatEntries(creds *types.Credentials, region string) (types.Resource
 	pageSize := int32(50)
 
 	for {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
 		request := &vpc.DescribeNatGatewaysRequest{
 			RegionId:   tea.String(region),
 			PageNumber: tea.Int32(pageNumber),
@@ -98,12 +112,21 @@ func CollectSnatEntries(creds *types.Credentials, region string) (types.Resource
 						entryName = entryID
 					}
 
+					props := types.Properties{}
+					props.Set(types.PropertyStatus, entry.Status)
+					props.Set("NatGatewayId", entry.NatGatewayId)
+					props.Set("SnatTableId", entry.SnatTableId)
+					props.Set("SnatIp", entry.SnatIp)
+					props.Set("SourceCIDR", entry.SourceCIDR)
+					props.Set(types.PropertyVSwitchID, entry.SourceVSwitchId)
+
 					res := types.Resource{
-						Removable:    SnatEntry{Client: client, Region: region, SnatTableId: *snatTableId},
+						Removable:    SnatEntry{Creds: creds, Region: region, SnatTableId: *snatTableId},
 						Region:       region,
 						ResourceID:   entryID,
 						ResourceName: entryName,
 						ProductName:  "SnatEntry",
+						Properties:   props,
 					}
 					allResources = append(allResources, &res)
 				}
@@ -115,13 +138,18 @@ func CollectSnatEntries(creds *types.Credentials, region string) (types.Resource
 }
 
 // Remove deletes the SNAT Entry
-func (s SnatEntry) Remove(region string, resourceID string, resourceName string) error {
+func (s SnatEntry) Remove(ctx context.Context, region string, resourceID string, resourceName string) error {
+	client, err := utils.GetVPCClient(ctx, s.Creds, region)
+	if err != nil {
+		return err
+	}
+
 	request := &vpc.DeleteSnatEntryRequest{
 		SnatTableId: tea.String(s.SnatTableId),
 		SnatEntryId: tea.String(resourceID),
 		RegionId:    tea.String(region),
 	}
 
-	_, err := s.Client.DeleteSnatEntry(request)
+	_, err = client.DeleteSnatEntry(request)
 	return err
 }
@@ -1,6 +1,8 @@
 package resources
 
 import (
+	"context"
+
 	"github.com/alibabacloud-go/tea/tea"
 	vpc "github.com/alibabacloud-go/vpc-20160428/v6/client"
 
@@ -10,18 +12,23 @@ import (
 )
 
 func init() {
-	infrastructure.RegisterCollector("sslVpnClientCert", CollectSslVpnClientCerts)
+	infrastructure.Register(infrastructure.Descriptor{
+		Name:        "SslVpnClientCert",
+		Product:     "vpc",
+		Description: "SSL-VPN client certificate",
+		Collect:     CollectSslVpnClientCerts,
+	})
 }
 
 // SslVpnClientCert represents an Alibaba Cloud SSL VPN Client Certificate resource
 type SslVpnClientCert struct {
-	Client *vpc.Client
+	Creds  *types.Credentials
 	Region string
 }
 
 // CollectSslVpnClientCerts discovers all SSL VPN Client Certificates in the specified region
-func CollectSslVpnClientCerts(creds *types.Credentials, region string) (types.Resources, error) {
-	client, err := utils.CreateVPCClient(creds, region)
+func CollectSslVpnClientCerts(ctx context.Context, creds *types.Credentials, region string) (types.Resources, error) {
+	client, err := utils.GetVPCClient(ctx, creds, region)
 	if err != nil {
 		return nil, err
 	}
@@ -31,6 +38,10 @@ func CollectSslVpnClientCerts(creds *types.Credentials, region string) (types.Re
 	pageSize := int32(50)
 
 	for {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
 		request := &vpc.DescribeSslVpnClientCertsRequest{
 			RegionId:   tea.String(region),
 			PageNumber: tea.Int32(pageNumber),
@@ -72,12 +83,20 @@ func CollectSslVpnClientCerts(creds *types.Credentials, region string) (types.Re
 			certName = certID
 		}
 
+		props := types.Properties{}
+		props.Set(types.PropertyStatus, cert.Status)
+		props.SetUnixMilli(types.PropertyCreationTime, cert.CreateTime)
+		props.Set(types.PropertyResourceGroupID, cert.ResourceGroupId)
+		props.Set("SslVpnServerId", cert.SslVpnServerId)
+		props.SetUnixMilli("EndTime", cert.EndTime)
+
 		res := types.Resource{
-			Removable:    SslVpnClientCert{Client: client, Region: region},
+			Removable:    SslVpnClientCert{Creds: creds, Region: region},
 			Region:       region,
 			ResourceID:   certID,
 			ResourceName: certName,
 			ProductName:  "SslVpnClientCert",
+			Properties:   props,
 		}
 		allResources = append(allResources, &res)
 	}
@@ -86,12 +105,17 @@ func CollectSslVpnClientCerts(creds *types.Credentials, region string) (types.Re
 }
 
 // Remove deletes the SSL VPN Client Certificate
-func (s SslVpnClientCert) Remove(region string, resourceID string, resourceName string) error {
+func (s SslVpnClientCert) Remove(ctx context.Context, region string, resourceID string, resourceName string) error {
+	client, err := utils.GetVPCClient(ctx, s.Creds, region)
+	if err != nil {
+		return err
+	}
+
 	request := &vpc.DeleteSslVpnClientCertRequest{
 		SslVpnClientCertId: tea.String(resourceID),
 		RegionId:           tea.String(region),
 	}
 
-	_, err := s.Client.DeleteSslVpnClientCert(request)
+	_, err = client.DeleteSslVpnClientCert(request)
 	return err
 }
@@ -1,6 +1,8 @@
 package resources
 
 import (
+	"context"
+
 	"github.com/alibabacloud-go/tea/tea"
 	vpc "github.com/alibabacloud-go/vpc-20160428/v6/client"
 
@@ -10,18 +12,24 @@ import (
 )
 
 func init() {
-	infrastructure.RegisterCollector("sslVpnServer", CollectSslVpnServers)
+	infrastructure.Register(infrastructure.Descriptor{
+		Name:        "SslVpnServer",
+		Product:     "vpc",
+		Description: "SSL-VPN server",
+		DependsOn:   []string{"SslVpnClientCert"},
+		Collect:     CollectSslVpnServers,
+	})
 }
 
 // SslVpnServer represents an Alibaba Cloud SSL VPN Server resource
 type SslVpnServer struct {
-	Client *vpc.Client
+	Creds  *types.Credentials
 	Region string
 }
 
 // CollectSslVpnServers discovers all SSL VPN Servers in the specified region
-func CollectSslVpnServers(creds *types.Credentials, region string) (types.Resources, error) {
-	client, err := utils.CreateVPCClient(creds, region)
+func CollectSslVpnServers(ctx context.Context, creds *types.Credentials, region string) (types.Resources, error) {
+	client, err := utils.GetVPCClient(ctx, creds, region)
 	if err != nil {
 		return nil, err
 	}
@@ -31,6 +39,10 @@ func CollectSslVpnServers(creds *types.Credentials, region string) (types.Resour
 	pageSize := int32(50)
 
 	for {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
 		request := &vpc.DescribeSslVpnServersRequest{
 			RegionId:   tea.String(region),
 			PageNumber: tea.Int32(pageNumber),
@@ -72,12 +84,20 @@ func CollectSslVpnServers(creds *types.Credentials, region string) (types.Resour
 			serverName = serverID
 		}
 
+		props := types.Properties{}
+		props.SetUnixMilli(types.PropertyCreationTime, server.CreateTime)
+		props.Set(types.PropertyResourceGroupID, server.ResourceGroupId)
+		props.Set("VpnGatewayId", server.VpnGatewayId)
+		props.Set("ClientIpPool", server.ClientIpPool)
+		props.Set("LocalSubnet", server.LocalSubnet)
+
 		res := types.Resource{
-			Removable:    SslVpnServer{Client: client, Region: region},
+			Removable:    SslVpnServer{Creds: creds, Region: region},
 			Region:       region,
 			ResourceID:   serverID,
 			ResourceName: serverName,
 			ProductName:  "SslVpnServer",
+			Properties:   props,
 		}
 		allResources = append(allResources, &res)
 	}
@@ -86,12 +106,17 @@ func CollectSslVpnServers(creds *types.Credentials, region string) (types.Resour
 }
 
 // Remove deletes the SSL VPN Server
-func (s SslVpnServer) Remove(region string, resourceID string, resourceName string) error {
+func (s SslVpnServer) Remove(ctx context.Context, region string, resourceID string, resourceName string) error {
+	client, err := utils.GetVPCClient(ctx, s.Creds, region)
+	if err != nil {
+		return err
+	}
+
 	request := &vpc.DeleteSslVpnServerRequest{
 		SslVpnServerId: tea.String(resourceID),
 		RegionId:       tea.String(region),
 	}
 
-	_, err := s.Client.DeleteSslVpnServer(request)
+	_, err = client.DeleteSslVpnServer(request)
 	return err
 }
@@ -1,6 +1,8 @@
 package resources
 
 import (
+	"context"
+
 	cbn "github.com/alibabacloud-go/cbn-20170912/v2/client"
 	"github.com/alibabacloud-go/tea/tea"
 
@@ -10,24 +12,29 @@ import (
 )
 
 func init() {
-	infrastructure.RegisterCollector("transitRouter", CollectTransitRouters)
+	infrastructure.Register(infrastructure.Descriptor{
+		Name:        "TransitRouter",
+		Product:     "cbn",
+		Description: "CEN transit router",
+		Collect:     CollectTransitRouters,
+	})
 }
 
 // TransitRouter represents an Alibaba Cloud CEN Transit Router resource
 type TransitRouter struct {
-	Client *cbn.Client
+	Creds  *types.Credentials
 	Region string
 }
 
 // CollectTransitRouters discovers all Transit Routers in the specified region
-func CollectTransitRouters(creds *types.Credentials, region string) (types.Resources, error) {
-	client, err := utils.CreateCENClient(creds, region)
+func CollectTransitRouters(ctx context.Context, creds *types.Credentials, region string) (types.Resources, error) {
+	client, err := utils.GetCENClient(ctx, creds, region)
 	if err != nil {
 		return nil, err
 	}
 
 	// First, get all CEN instances to query their transit routers
-	cenIDs, err := listAllCENIDs(client)
+	cenIDs, err := listAllCENIDs(ctx, client)
 	if err != nil {
 		return nil, err
 	}
@@ -36,7 +43,7 @@ func CollectTransitRouters(creds *types.Credentials, region string) (types.Resou
 
 	// For each CEN, list transit routers in the specified region
 	for _, cenID := range cenIDs {
-		transitRouters, err := listTransitRoutersForCEN(client, cenID, region)
+		transitRouters, err := listTransitRoutersForCEN(ctx, client, cenID, region)
 		if err != nil {
 			// Skip if there's an error for this CEN (e.g., permission issues)
 			continue
@@ -56,12 +63,25 @@ func CollectTransitRouters(creds *types.Credentials, region string) (types.Resou
 				trName = trID
 			}
 
+			props := types.Properties{}
+			props.Set(types.PropertyStatus, tr.Status)
+			props.Set(types.PropertyCreationTime, tr.CreationTime)
+			props.Set("CenId", tr.CenId)
+			props.Set("Type", tr.Type)
+
+			tags := types.Tags{}
+			for _, tag := range tr.Tags {
+				tags.Set(tag.Key, tag.Value)
+			}
+
 			res := types.Resource{
-				Removable:    TransitRouter{Client: client, Region: region},
+				Removable:    TransitRouter{Creds: creds, Region: region},
 				Region:       region,
 				ResourceID:   trID,
 				ResourceName: trName,
 				ProductName:  "TransitRouter",
+				Properties:   props,
+				Tags:         tags,
 			}
 			allResources = append(allResources, &res)
 		}
@@ -71,12 +91,16 @@ func CollectTransitRouters(creds *types.Credentials, region string) (types.Resou
 }
 
 // listAllCENIDs returns all CEN instance IDs
-func listAllCENIDs(client *cbn.Client) ([]string, error) {
+func listAllCENIDs(ctx context.Context, client *cbn.Client) ([]string, error) {
 	var cenIDs []string
 	pageNumber := int32(1)
 	pageSize := int32(50)
 
 	for {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
 		request := &cbn.DescribeCensRequest{
 			PageNumber: tea.Int32(pageNumber),
 			PageSize:   tea.Int32(pageSize),
@@ -110,12 +134,16 @@ func listAllCENIDs(client *cbn.Client) ([]string, error) {
 }
 
 // listTransitRoutersForCEN returns all transit routers for a CEN in the specified region
-func listTransitRoutersForCEN(client *cbn.Client, cenID string, region string) ([]*cbn.ListTransitRoutersResponseBodyTransitRouters, error) {
+func listTransitRoutersForCEN(ctx context.Context, client *cbn.Client, cenID string, region string) ([]*cbn.ListTransitRoutersResponseBodyTransitRouters, error) {
 	var allTransitRouters []*cbn.ListTransitRoutersResponseBodyTransitRouters
 	pageNumber := int32(1)
 	pageSize := int32(50)
 
 	for {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
 		request := &cbn.ListTransitRoutersRequest{
 			CenId:      tea.String(cenID),
 			RegionId:   tea.String(region),
@@ -147,11 +175,16 @@ func listTransitRoutersForCEN(client *cbn.Client, cenID string, region string) (
 }
 
 // Remove deletes the Transit Router
-func (t TransitRouter) Remove(region string, resourceID string, resourceName string) error {
+func (t TransitRouter) Remove(ctx context.Context, region string, resourceID string, resourceName string) error {
+	client, err := utils.GetCENClient(ctx, t.Creds, region)
+	if err != nil {
+		return err
+	}
+
 	request := &cbn.DeleteTransitRouterRequest{
 		TransitRouterId: tea.String(resourceID),
 	}
 
-	_, err := t.Client.DeleteTransitRouter(request)
+	_, err = client.DeleteTransitRouter(request)
 	return err
 }
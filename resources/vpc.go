@@ -1,6 +1,8 @@
 package resources
 
 import (
+	"context"
+
 	"github.com/alibabacloud-go/tea/tea"
 	vpc "github.com/alibabacloud-go/vpc-20160428/v6/client"
 
@@ -10,18 +12,24 @@ import (
 )
 
 func init() {
-	infrastructure.RegisterCollector("vpc", CollectVPCs)
+	infrastructure.Register(infrastructure.Descriptor{
+		Name:        "VPC",
+		Product:     "vpc",
+		Description: "Virtual Private Cloud",
+		DependsOn:   []string{"VSwitch", "RouteTable", "SecurityGroup", "NatGateway", "VpnGateway", "RouterInterface", "HaVip"},
+		Collect:     CollectVPCs,
+	})
 }
 
 // VPC represents an Alibaba Cloud VPC resource
 type VPC struct {
-	Client *vpc.Client
+	Creds  *types.Credentials
 	Region string
 }
 
 // CollectVPCs discovers all VPCs in the specified region
-func CollectVPCs(creds *types.Credentials, region string) (types.Resources, error) {
-	client, err := utils.CreateVPCClient(creds, region)
+func CollectVPCs(ctx context.Context, creds *types.Credentials, region string) (types.Resources, error) {
+	client, err := utils.GetVPCClient(ctx, creds, region)
 	if err != nil {
 		return nil, err
 	}
@@ -32,6 +40,10 @@ func CollectVPCs(creds *types.Credentials, region string) (types.Resources, erro
 
 	// Paginate through all VPCs
 	for {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
 		request := &vpc.DescribeVpcsRequest{
 			RegionId:   tea.String(region),
 			PageNumber: tea.Int32(pageNumber),
@@ -75,12 +87,28 @@ func CollectVPCs(creds *types.Credentials, region string) (types.Resources, erro
 			vpcName = vpcID
 		}
 
+		props := types.Properties{}
+		props.Set(types.PropertyStatus, v.Status)
+		props.Set(types.PropertyCreationTime, v.CreationTime)
+		props.Set(types.PropertyResourceGroupID, v.ResourceGroupId)
+		props.Set("CidrBlock", v.CidrBlock)
+		props.SetBool("IsDefault", v.IsDefault)
+
+		tags := types.Tags{}
+		if v.Tags != nil {
+			for _, tag := range v.Tags.Tag {
+				tags.Set(tag.Key, tag.Value)
+			}
+		}
+
 		res := types.Resource{
-			Removable:    VPC{Client: client, Region: region},
+			Removable:    VPC{Creds: creds, Region: region},
 			Region:       region,
 			ResourceID:   vpcID,
 			ResourceName: vpcName,
 			ProductName:  "VPC",
+			Properties:   props,
+			Tags:         tags,
 		}
 		allResources = append(allResources, &res)
 	}
@@ -89,12 +117,17 @@ func CollectVPCs(creds *types.Credentials, region string) (types.Resources, erro
 }
 
 // Remove deletes the VPC
-func (v VPC) Remove(region string, resourceID string, resourceName string) error {
+func (v VPC) Remove(ctx context.Context, region string, resourceID string, resourceName string) error {
+	client, err := utils.GetVPCClient(ctx, v.Creds, region)
+	if err != nil {
+		return err
+	}
+
 	request := &vpc.DeleteVpcRequest{
 		VpcId:    tea.String(resourceID),
 		RegionId: tea.String(region),
 	}
 
-	_, err := v.Client.DeleteVpc(request)
+	_, err = client.DeleteVpc(request)
 	return err
 }
@@ -1,6 +1,8 @@
 package resources
 
 import (
+	"context"
+
 	"github.com/alibabacloud-go/tea/tea"
 	vpc "github.com/alibabacloud-go/vpc-20160428/v6/client"
 
@@ -10,18 +12,23 @@ import (
 )
 
 func init() {
-	infrastructure.RegisterCollector("vpnConnection", CollectVpnConnections)
+	infrastructure.Register(infrastructure.Descriptor{
+		Name:        "VpnConnection",
+		Product:     "vpc",
+		Description: "IPsec-VPN connection",
+		Collect:     CollectVpnConnections,
+	})
 }
 
 // VpnConnection represents an Alibaba Cloud VPN Connection (IPsec Connection) resource
 type VpnConnection struct {
-	Client *vpc.Client
+	Creds  *types.Credentials
 	Region string
 }
 
 // CollectVpnConnections discovers all VPN Connections in the specified region
-func CollectVpnConnections(creds *types.Credentials, region string) (types.Resources, error) {
-	client, err := utils.CreateVPCClient(creds, region)
+func CollectVpnConnections(ctx context.Context, creds *types.Credentials, region string) (types.Resources, error) {
+	client, err := utils.GetVPCClient(ctx, creds, region)
 	if err != nil {
 		return nil, err
 	}
@@ -31,6 +38,10 @@ func CollectVpnConnections(creds *types.Credentials, region string) (types.Resou
 	pageSize := int32(50)
 
 	for {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
 		request := &vpc.DescribeVpnConnectionsRequest{
 			RegionId:   tea.String(region),
 			PageNumber: tea.Int32(pageNumber),
@@ -72,12 +83,29 @@ func CollectVpnConnections(creds *types.Credentials, region string) (types.Resou
 			connName = connID
 		}
 
+		props := types.Properties{}
+		props.Set(types.PropertyStatus, conn.Status)
+		props.SetUnixMilli(types.PropertyCreationTime, conn.CreateTime)
+		props.Set(types.PropertyResourceGroupID, conn.ResourceGroupId)
+		props.Set("VpnGatewayId", conn.VpnGatewayId)
+		props.Set("CustomerGatewayId", conn.CustomerGatewayId)
+		props.Set("TransitRouterId", conn.TransitRouterId)
+
+		tags := types.Tags{}
+		if conn.Tag != nil {
+			for _, tag := range conn.Tag.Tag {
+				tags.Set(tag.Key, tag.Value)
+			}
+		}
+
 		res := types.Resource{
-			Removable:    VpnConnection{Client: client, Region: region},
+			Removable:    VpnConnection{Creds: creds, Region: region},
 			Region:       region,
 			ResourceID:   connID,
 			ResourceName: connName,
 			ProductName:  "VpnConnection",
+			Properties:   props,
+			Tags:         tags,
 		}
 		allResources = append(allResources, &res)
 	}
@@ -86,12 +114,17 @@ func CollectVpnConnections(creds *types.Credentials, region string) (types.Resou
 }
 
 // Remove deletes the VPN Connection
-func (v VpnConnection) Remove(region string, resourceID string, resourceName string) error {
+func (v VpnConnection) Remove(ctx context.Context, region string, resourceID string, resourceName string) error {
+	client, err := utils.GetVPCClient(ctx, v.Creds, region)
+	if err != nil {
+		return err
+	}
+
 	request := &vpc.DeleteVpnConnectionRequest{
 		VpnConnectionId: tea.String(resourceID),
 		RegionId:        tea.String(region),
 	}
 
-	_, err := v.Client.DeleteVpnConnection(request)
+	_, err = client.DeleteVpnConnection(request)
 	return err
 }
@@ -1,6 +1,8 @@
 package resources
 
 import (
+	"context"
+
 	"github.com/alibabacloud-go/tea/tea"
 	vpc "github.com/alibabacloud-go/vpc-20160428/v6/client"
 
@@ -10,18 +12,24 @@ import (
 )
 
 func init() {
-	infrastructure.RegisterCollector("vpnGateway", CollectVpnGateways)
+	infrastructure.Register(infrastructure.Descriptor{
+		Name:        "VpnGateway",
+		Product:     "vpc",
+		Description: "VPN gateway",
+		DependsOn:   []string{"VpnConnection", "SslVpnServer"},
+		Collect:     CollectVpnGateways,
+	})
 }
 
 // VpnGateway represents an Alibaba Cloud VPN Gateway resource
 type VpnGateway struct {
-	Client *vpc.Client
+	Creds  *types.Credentials
 	Region string
 }
 
 // CollectVpnGateways discovers all VPN Gateways in the specified region
-func CollectVpnGateways(creds *types.Credentials, region string) (types.Resources, error) {
-	client, err := utils.CreateVPCClient(creds, region)
+func CollectVpnGateways(ctx context.Context, creds *types.Credentials, region string) (types.Resources, error) {
+	client, err := utils.GetVPCClient(ctx, creds, region)
 	if err != nil {
 		return nil, err
 	}
@@ -31,6 +39,10 @@ func CollectVpnGateways(creds *types.Credentials, region string) (types.Resource
 	pageSize := int32(50)
 
 	for {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
 		request := &vpc.DescribeVpnGatewaysRequest{
 			RegionId:   tea.String(region),
 			PageNumber: tea.Int32(pageNumber),
@@ -72,12 +84,31 @@ func CollectVpnGateways(creds *types.Credentials, region string) (types.Resource
 			vpnName = vpnID
 		}
 
+		props := types.Properties{}
+		props.Set(types.PropertyStatus, vpn.Status)
+		props.SetUnixMilli(types.PropertyCreationTime, vpn.CreateTime)
+		props.Set(types.PropertyChargeType, vpn.ChargeType)
+		props.Set(types.PropertyVpcID, vpn.VpcId)
+		props.Set(types.PropertyVSwitchID, vpn.VSwitchId)
+		props.Set(types.PropertyResourceGroupID, vpn.ResourceGroupId)
+		props.Set("Spec", vpn.Spec)
+		props.Set("InternetIp", vpn.InternetIp)
+
+		tags := types.Tags{}
+		if vpn.Tags != nil {
+			for _, tag := range vpn.Tags.Tag {
+				tags.Set(tag.Key, tag.Value)
+			}
+		}
+
 		res := types.Resource{
-			Removable:    VpnGateway{Client: client, Region: region},
+			Removable:    VpnGateway{Creds: creds, Region: region},
 			Region:       region,
 			ResourceID:   vpnID,
 			ResourceName: vpnName,
 			ProductName:  "VpnGateway",
+			Properties:   props,
+			Tags:         tags,
 		}
 		allResources = append(allResources, &res)
 	}
@@ -86,12 +117,17 @@ func CollectVpnGateways(creds *types.Credentials, region string) (types.Resource
 }
 
 // Remove deletes the VPN Gateway
-func (v VpnGateway) Remove(region string, resourceID string, resourceName string) error {
+func (v VpnGateway) Remove(ctx context.Context, region string, resourceID string, resourceName string) error {
+	client, err := utils.GetVPCClient(ctx, v.Creds, region)
+	if err != nil {
+		return err
+	}
+
 	request := &vpc.DeleteVpnGatewayRequest{
 		VpnGatewayId: tea.String(resourceID),
 		RegionId:     tea.String(region),
 	}
 
-	_, err := v.Client.DeleteVpnGateway(request)
+	_, err = client.DeleteVpnGateway(request)
 	return err
 }
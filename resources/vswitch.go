@@ -1,6 +1,8 @@
 package resources
 
 import (
+	"context"
+
 	"github.com/alibabacloud-go/tea/tea"
 	vpc "github.com/alibabacloud-go/vpc-20160428/v6/client"
 
@@ -10,18 +12,28 @@ import (
 )
 
 func init() {
-	infrastructure.RegisterCollector("vswitch", CollectVSwitches)
+	infrastructure.Register(infrastructure.Descriptor{
+		Name:        "VSwitch",
+		Product:     "vpc",
+		Description: "VPC vSwitch",
+		DependsOn: []string{
+			"ECSInstance", "NetworkInterface", "NatGateway", "HaVip", "SLB", "ALB", "NLB",
+			"RDSInstance", "RedisInstance", "MongoDBInstance", "PolarDBCluster",
+			"NASMountTarget", "ScalingGroup", "ACKCluster", "VpnGateway",
+		},
+		Collect: CollectVSwitches,
+	})
 }
 
 // VSwitch represents an Alibaba Cloud VSwitch resource
 type VSwitch struct {
-	Client *vpc.Client
+	Creds  *types.Credentials
 	Region string
 }
 
 // CollectVSwitches discovers all VSwitches in the specified region
-func CollectVSwitches(creds *types.Credentials, region string) (types.Resources, error) {
-	client, err := utils.CreateVPCClient(creds, region)
+func CollectVSwitches(ctx context.Context, creds *types.Credentials, region string) (types.Resources, error) {
+	client, err := utils.GetVPCClient(ctx, creds, region)
 	if err != nil {
 		return nil, err
 	}
@@ -32,6 +44,10 @@ func CollectVSwitches(creds *types.Credentials, region string) (types.Resources,
 
 	// Paginate through all VSwitches
 	for {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
 		request := &vpc.DescribeVSwitchesRequest{
 			RegionId:   tea.String(region),
 			PageNumber: tea.Int32(pageNumber),
@@ -75,12 +91,30 @@ func CollectVSwitches(creds *types.Credentials, region string) (types.Resources,
 			vswitchName = vswitchID
 		}
 
+		props := types.Properties{}
+		props.Set(types.PropertyStatus, vs.Status)
+		props.Set(types.PropertyCreationTime, vs.CreationTime)
+		props.Set(types.PropertyVpcID, vs.VpcId)
+		props.Set(types.PropertyZoneID, vs.ZoneId)
+		props.Set(types.PropertyResourceGroupID, vs.ResourceGroupId)
+		props.Set("CidrBlock", vs.CidrBlock)
+		props.SetBool("IsDefault", vs.IsDefault)
+
+		tags := types.Tags{}
+		if vs.Tags != nil {
+			for _, tag := range vs.Tags.Tag {
+				tags.Set(tag.Key, tag.Value)
+			}
+		}
+
 		res := types.Resource{
-			Removable:    VSwitch{Client: client, Region: region},
+			Removable:    VSwitch{Creds: creds, Region: region},
 			Region:       region,
 			ResourceID:   vswitchID,
 			ResourceName: vswitchName,
 			ProductName:  "VSwitch",
+			Properties:   props,
+			Tags:         tags,
 		}
 		allResources = append(allResources, &res)
 	}
@@ -89,12 +123,17 @@ func CollectVSwitches(creds *types.Credentials, region string) (types.Resources,
 }
 
 // Remove deletes the VSwitch
-func (vs VSwitch) Remove(region string, resourceID string, resourceName string) error {
+func (vs VSwitch) Remove(ctx context.Context, region string, resourceID string, resourceName string) error {
+	client, err := utils.GetVPCClient(ctx, vs.Creds, region)
+	if err != nil {
+		return err
+	}
+
 	request := &vpc.DeleteVSwitchRequest{
 		VSwitchId: tea.String(resourceID),
 		RegionId:  tea.String(region),
 	}
 
-	_, err := vs.Client.DeleteVSwitch(request)
+	_, err = client.DeleteVSwitch(request)
 	return err
 }
@@ -1,7 +1,10 @@
 package types
 
+import "github.com/aliyun/credentials-go/credentials/providers"
+
 // Credentials holds Alibaba Cloud authentication information
 type Credentials struct {
-	AccessKeyID     string
-	AccessKeySecret string
+	Provider  providers.CredentialsProvider // Resolves (and refreshes) access key, secret and STS token
+	Source    string                        // Where the credentials come from, e.g. "profile default"
+	AccountID string                        // Account the credentials belong to, set once resolved
 }
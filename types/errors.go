@@ -0,0 +1,276 @@
+package types
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net"
+	"strings"
+	"sync"
+	"syscall"
+
+	"github.com/alibabacloud-go/tea/tea"
+	"github.com/aliyun/alibabacloud-oss-go-sdk-v2/oss"
+)
+
+// ErrorCategory classifies an API error by how it should be handled
+type ErrorCategory int
+
+const (
+	ErrorTransient   ErrorCategory = iota // Network hiccups and server errors, retry with backoff
+	ErrorPermanent                        // Retrying will not help (permissions, invalid parameters, ...)
+	ErrorDependency                       // Another resource or an ongoing operation blocks the request
+	ErrorThrottled                        // Rate limit exceeded
+	ErrorUnavailable                      // Service or API is not available in the region
+	ErrorNotFound                         // Resource does not exist (any more)
+)
+
+func (c ErrorCategory) String() string {
+	switch c {
+	case ErrorTransient:
+		return "transient"
+	case ErrorPermanent:
+		return "permanent"
+	case ErrorDependency:
+		return "dependency"
+	case ErrorThrottled:
+		return "throttled"
+	case ErrorUnavailable:
+		return "unavailable"
+	case ErrorNotFound:
+		return "not-found"
+	default:
+		return "unknown"
+	}
+}
+
+// APIError holds the details of an error returned by an Alibaba Cloud API
+type APIError struct {
+	Code       string
+	Message    string
+	StatusCode int
+	RequestID  string
+}
+
+// responseError is implemented by the error types of the darabonba OpenAPI client
+// (ClientError, ServerError and ThrottlingError)
+type responseError interface {
+	GetCode() *string
+	GetMessage() *string
+	GetStatusCode() *int
+	GetRequestId() *string
+}
+
+// AsAPIError unwraps err to the error returned by the API, if any
+func AsAPIError(err error) (*APIError, bool) {
+	var sdkErr *tea.SDKError
+	if errors.As(err, &sdkErr) {
+		return &APIError{
+			Code:       tea.StringValue(sdkErr.Code),
+			Message:    tea.StringValue(sdkErr.Message),
+			StatusCode: tea.IntValue(sdkErr.StatusCode),
+			RequestID:  requestIDFromData(tea.StringValue(sdkErr.Data)),
+		}, true
+	}
+
+	var respErr responseError
+	if errors.As(err, &respErr) {
+		return &APIError{
+			Code:       tea.StringValue(respErr.GetCode()),
+			Message:    tea.StringValue(respErr.GetMessage()),
+			StatusCode: tea.IntValue(respErr.GetStatusCode()),
+			RequestID:  tea.StringValue(respErr.GetRequestId()),
+		}, true
+	}
+
+	var ossErr *oss.ServiceError
+	if errors.As(err, &ossErr) {
+		return &APIError{
+			Code:       ossErr.Code,
+			Message:    ossErr.Message,
+			StatusCode: ossErr.StatusCode,
+			RequestID:  ossErr.RequestID,
+		}, true
+	}
+
+	return nil, false
+}
+
+// requestIDFromData extracts the request ID from the response body attached to a tea.SDKError
+func requestIDFromData(data string) string {
+	var body struct {
+		RequestID      string `json:"RequestId"`
+		RequestIDLower string `json:"requestId"`
+	}
+	if json.Unmarshal([]byte(data), &body) != nil {
+		return ""
+	}
+	if body.RequestID != "" {
+		return body.RequestID
+	}
+	return body.RequestIDLower
+}
+
+// codeCategories maps well-known error codes to their category. A code also matches
+// error codes that contain it as a dot-separated part, so "DependencyViolation" matches
+// "DependencyViolation.NetworkInterface". Entries are checked in order.
+var codeCategories = []struct {
+	category ErrorCategory
+	codes    []string
+}{
+	{ErrorPermanent, []string{
+		"InvalidAccessKeyId", "InvalidAccessKeySecret", "SignatureDoesNotMatch", "IncompleteSignature",
+		"InvalidSecurityToken", "NoPermission", "Forbidden", "AccessDenied",
+	}},
+	{ErrorUnavailable, []string{
+		"InvalidRegionId", "UnauthorizedRegion", "InvalidApi.NotFound",
+	}},
+	{ErrorThrottled, []string{
+		"Throttling", "ServiceUnavailable.Throttling", "RequestLimitExceeded",
+	}},
+	{ErrorDependency, []string{
+		"DependencyViolation", "OperationConflict", "TaskConflict", "LastTokenProcessing",
+	}},
+	{ErrorNotFound, []string{
+		"NoSuchBucket",
+	}},
+	{ErrorTransient, []string{
+		"ServiceUnavailable", "InternalError", "UnknownError", "ServiceBusy",
+	}},
+}
+
+var (
+	registeredCodesMu sync.RWMutex
+	registeredCodes   = make(map[string]map[string]ErrorCategory)
+)
+
+// RegisterErrorCodes declares error codes with a resource-type specific meaning, e.g. a
+// code signalling that a file system still has mount targets. They take precedence over
+// the built-in classification. Resource types are compared case-insensitively.
+func RegisterErrorCodes(resourceType string, category ErrorCategory, codes ...string) {
+	registeredCodesMu.Lock()
+	defer registeredCodesMu.Unlock()
+
+	key := strings.ToLower(resourceType)
+	if registeredCodes[key] == nil {
+		registeredCodes[key] = make(map[string]ErrorCategory)
+	}
+	for _, code := range codes {
+		registeredCodes[key][code] = category
+	}
+}
+
+// ClassifyError returns the category of an error returned while collecting or deleting
+// resources of the given type. Unknown errors are considered transient.
+func ClassifyError(resourceType string, err error) ErrorCategory {
+	if err == nil {
+		return ErrorTransient
+	}
+	if errors.Is(err, context.Canceled) {
+		return ErrorPermanent
+	}
+
+	if apiErr, ok := AsAPIError(err); ok {
+		return classifyAPIError(resourceType, apiErr)
+	}
+
+	// Service endpoints that do not exist or cannot be reached in a region
+	var dnsErr *net.DNSError
+	if errors.As(err, &dnsErr) && dnsErr.IsNotFound {
+		return ErrorUnavailable
+	}
+	if errors.Is(err, syscall.ENETUNREACH) {
+		return ErrorUnavailable
+	}
+
+	return ErrorTransient
+}
+
+// classifyAPIError classifies an error by its code and HTTP status
+func classifyAPIError(resourceType string, apiErr *APIError) ErrorCategory {
+	registeredCodesMu.RLock()
+	registered := registeredCodes[strings.ToLower(resourceType)]
+	for code, category := range registered {
+		if codeMatches(apiErr.Code, code) {
+			registeredCodesMu.RUnlock()
+			return category
+		}
+	}
+	registeredCodesMu.RUnlock()
+
+	for _, entry := range codeCategories {
+		for _, code := range entry.codes {
+			if codeMatches(apiErr.Code, code) {
+				return entry.category
+			}
+		}
+	}
+
+	// Naming conventions shared by most products: "InvalidVpcId.NotFound",
+	// "ErrorClusterNotFound", "EntityNotExist.Role", "IncorrectInstanceStatus"
+	for _, part := range strings.Split(apiErr.Code, ".") {
+		switch {
+		case strings.HasSuffix(part, "NotFound"), strings.Contains(part, "NotExist"):
+			// A delete call also fails like this if a resource it refers to is missing, e.g.
+			// the security group of a rule. That is no proof the resource itself is gone.
+			if namesResourceType(apiErr.Code, resourceType) {
+				return ErrorNotFound
+			}
+		case strings.HasPrefix(part, "Incorrect") && (strings.HasSuffix(part, "Status") || strings.HasSuffix(part, "State")):
+			return ErrorDependency
+		}
+	}
+
+	switch {
+	case apiErr.StatusCode == 429:
+		return ErrorThrottled
+	case apiErr.StatusCode >= 500:
+		return ErrorTransient
+	case apiErr.StatusCode >= 400:
+		return ErrorPermanent
+	}
+	return ErrorTransient
+}
+
+// notFoundReplacer removes the not-found markers from an error code, leaving its subject
+var notFoundReplacer = strings.NewReplacer("NotFound", "", "NotExists", "", "NotExist", "")
+
+// namesResourceType reports whether a not-found error code is about a resource of the given
+// type: its subject, e.g. "Vpc" in "InvalidVpcId.NotFound" or "Cluster" in
+// "ErrorClusterNotFound", is part of the type name. Codes naming no type in particular, like
+// "InvalidResourceId.NotFound", are taken to be about the resource addressed. Codes using
+// another name for a type are declared with RegisterErrorCodes.
+func namesResourceType(code, resourceType string) bool {
+	if resourceType == "" {
+		return true
+	}
+	typeName := strings.ToLower(resourceType)
+	for _, part := range strings.Split(notFoundReplacer.Replace(code), ".") {
+		subject := strings.ToLower(part)
+		for _, prefix := range []string{"invalid", "error"} {
+			subject = strings.TrimPrefix(subject, prefix)
+		}
+		for _, suffix := range []string{"ids", "id", "name"} {
+			subject = strings.TrimSuffix(subject, suffix)
+		}
+		switch subject {
+		case "", "resource", "entity":
+			continue
+		}
+		if !strings.Contains(typeName, subject) {
+			return false
+		}
+	}
+	return true
+}
+
+// codeMatches reports whether code equals pattern or contains it as dot-separated parts
+func codeMatches(code, pattern string) bool {
+	if code == "" {
+		return false
+	}
+	return code == pattern ||
+		strings.HasPrefix(code, pattern+".") ||
+		strings.HasSuffix(code, "."+pattern) ||
+		strings.Contains(code, "."+pattern+".")
+}
@@ -0,0 +1,120 @@
+package types
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"strings"
+	"syscall"
+	"testing"
+
+	"github.com/alibabacloud-go/tea/tea"
+	"github.com/aliyun/alibabacloud-oss-go-sdk-v2/oss"
+)
+
+// apiError returns an error as returned by the OpenAPI SDK
+func apiError(code string, status int) error {
+	return &tea.SDKError{Code: tea.String(code), StatusCode: tea.Int(status)}
+}
+
+// registerErrorCodes registers codes of a resource type for the duration of the test
+func registerErrorCodes(t *testing.T, resourceType string, category ErrorCategory, codes ...string) {
+	t.Helper()
+	RegisterErrorCodes(resourceType, category, codes...)
+	t.Cleanup(func() {
+		registeredCodesMu.Lock()
+		defer registeredCodesMu.Unlock()
+		delete(registeredCodes, strings.ToLower(resourceType))
+	})
+}
+
+func TestClassifyError(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want ErrorCategory
+	}{
+		{"permanent code", apiError("InvalidAccessKeyId.NotFound", 404), ErrorPermanent},
+		{"throttled code", apiError("Throttling.User", 400), ErrorThrottled},
+		{"throttled status", apiError("TooManyRequests", 429), ErrorThrottled},
+		{"dependency code", apiError("DependencyViolation.NetworkInterface", 400), ErrorDependency},
+		{"dependency suffix", apiError("Operation.DependencyViolation", 400), ErrorDependency},
+		{"incorrect status", apiError("IncorrectInstanceStatus", 403), ErrorDependency},
+		{"unavailable", apiError("InvalidRegionId", 400), ErrorUnavailable},
+		{"unknown API", apiError("InvalidApi.NotFound", 404), ErrorUnavailable},
+		{"transient code", apiError("ServiceUnavailable", 503), ErrorTransient},
+		{"server error", apiError("SomethingBroke", 500), ErrorTransient},
+		{"client error", apiError("InvalidParameter", 400), ErrorPermanent},
+		{"OSS not found", &oss.ServiceError{Code: "NoSuchBucket", StatusCode: 404}, ErrorNotFound},
+		{"wrapped", fmt.Errorf("deleting: %w", apiError("Throttling", 400)), ErrorThrottled},
+		{"cancelled", fmt.Errorf("waiting: %w", context.Canceled), ErrorPermanent},
+		{"no endpoint", &net.DNSError{Err: "no such host", Name: "cs.xx.aliyuncs.com", IsNotFound: true}, ErrorUnavailable},
+		{"unreachable", &net.OpError{Op: "dial", Err: os.NewSyscallError("connect", syscall.ENETUNREACH)}, ErrorUnavailable},
+		{"network error", errors.New("connection reset by peer"), ErrorTransient},
+	}
+	for _, tt := range tests {
+		if got := ClassifyError("VPC", tt.err); got != tt.want {
+			t.Errorf("%s: category %s, want %s", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestClassifyErrorRegisteredCodes(t *testing.T) {
+	err := apiError("HasMountTarget", 400)
+	if got := ClassifyError("TestFileSystem", err); got != ErrorPermanent {
+		t.Fatalf("category %s before registration, want %s", got, ErrorPermanent)
+	}
+	registerErrorCodes(t, "TestFileSystem", ErrorDependency, "HasMountTarget")
+	if got := ClassifyError("testfilesystem", err); got != ErrorDependency {
+		t.Errorf("category %s after registration, want %s", got, ErrorDependency)
+	}
+	if got := ClassifyError("VPC", err); got != ErrorPermanent {
+		t.Errorf("category %s for another type, want %s", got, ErrorPermanent)
+	}
+}
+
+func TestClassifyErrorForbidden(t *testing.T) {
+	for _, code := range []string{"Forbidden", "Forbidden.RAM", "Forbidden.NotSupportedRAM", "Forbidden.SubUser"} {
+		if got := ClassifyError("VPC", apiError(code, 403)); got != ErrorPermanent {
+			t.Errorf("%s: category %s, want %s", code, got, ErrorPermanent)
+		}
+	}
+}
+
+func TestClassifyErrorNotFound(t *testing.T) {
+	tests := []struct {
+		resourceType string
+		code         string
+		want         ErrorCategory
+	}{
+		{"VPC", "InvalidVpcId.NotFound", ErrorNotFound},
+		{"ACKCluster", "ErrorClusterNotFound", ErrorNotFound},
+		{"ECSInstance", "InvalidInstanceId.NotFound", ErrorNotFound},
+		{"KeyPair", "InvalidKeyPairName.NotFound", ErrorNotFound},
+		{"VSwitch", "InvalidResourceId.NotFound", ErrorNotFound},
+		{"", "InvalidSecurityGroupId.NotFound", ErrorNotFound},
+
+		// A missing resource the call refers to is not the resource being deleted
+		{"NetworkInterface", "InvalidSecurityGroupId.NotFound", ErrorPermanent},
+		{"VSwitch", "InvalidRouteTableId.NotFound", ErrorPermanent},
+		{"RAMRole", "EntityNotExist.User", ErrorPermanent},
+	}
+	for _, tt := range tests {
+		if got := ClassifyError(tt.resourceType, apiError(tt.code, 404)); got != tt.want {
+			t.Errorf("%s %s: category %s, want %s", tt.resourceType, tt.code, got, tt.want)
+		}
+	}
+}
+
+func TestClassifyErrorRegisteredNotFound(t *testing.T) {
+	err := apiError("InvalidAllocationId.NotFound", 404)
+	if got := ClassifyError("TestAddress", err); got != ErrorPermanent {
+		t.Fatalf("category %s before registration, want %s", got, ErrorPermanent)
+	}
+	registerErrorCodes(t, "TestAddress", ErrorNotFound, "InvalidAllocationId.NotFound")
+	if got := ClassifyError("TestAddress", err); got != ErrorNotFound {
+		t.Errorf("category %s after registration, want %s", got, ErrorNotFound)
+	}
+}
@@ -0,0 +1,75 @@
+package types
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ResourceError describes why the deletion of a resource did not succeed
+type ResourceError struct {
+	Code      string        `json:"code,omitempty" yaml:"code,omitempty"`
+	Message   string        `json:"message" yaml:"message"`
+	RequestID string        `json:"requestId,omitempty" yaml:"requestId,omitempty"`
+	Category  ErrorCategory `json:"-" yaml:"-"`
+	Attempts  int           `json:"attempts" yaml:"attempts"` // Delete calls made across all waves
+	Wave      int           `json:"wave" yaml:"wave"`         // Deletion wave of the last attempt
+}
+
+// NewResourceError captures the details of an error returned while deleting a resource
+func NewResourceError(resourceType string, err error, attempts, wave int) *ResourceError {
+	resourceErr := &ResourceError{
+		Message:  err.Error(),
+		Category: ClassifyError(resourceType, err),
+		Attempts: attempts,
+		Wave:     wave,
+	}
+	if apiErr, ok := AsAPIError(err); ok {
+		resourceErr.Code = apiErr.Code
+		resourceErr.Message = apiErr.Message
+		resourceErr.RequestID = apiErr.RequestID
+	}
+	return resourceErr
+}
+
+// Summary returns the error code and message on a single line
+func (e *ResourceError) Summary() string {
+	message := strings.Join(strings.Fields(e.Message), " ")
+	if e.Code == "" {
+		return message
+	}
+	return e.Code + ": " + message
+}
+
+// String returns all details of the error
+func (e *ResourceError) String() string {
+	details := fmt.Sprintf("%s (attempts: %d, wave: %d", e.Summary(), e.Attempts, e.Wave)
+	if e.RequestID != "" {
+		details += ", request ID: " + e.RequestID
+	}
+	return details + ")"
+}
+
+// LastError returns the error of the last failed delete or verification, nil if there was none
+func (r *Resource) LastError() *ResourceError {
+	return r.lastError.Load()
+}
+
+// SetLastError stores the error of a failed delete or verification
+func (r *Resource) SetLastError(err *ResourceError) {
+	r.lastError.Store(err)
+}
+
+// Fail marks the resource as Failed for a reason outside of an API call, e.g. an exhausted
+// time budget. The reason is only recorded if no error is known yet, since that error is
+// usually the better explanation.
+func (r *Resource) Fail(reason string) {
+	if r.LastError() == nil {
+		r.SetLastError(&ResourceError{
+			Message:  reason,
+			Category: ErrorPermanent,
+			Attempts: int(r.attempts.Load()),
+			Wave:     int(r.wave.Load()),
+		})
+	}
+	r.SetState(Failed)
+}
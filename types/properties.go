@@ -0,0 +1,126 @@
+package types
+
+import (
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Well-known property keys. Collectors use these keys whenever the API exposes the
+// corresponding attribute, so that filters and reports can rely on a common name.
+const (
+	PropertyStatus          = "Status"
+	PropertyCreationTime    = "CreationTime"
+	PropertyVpcID           = "VpcId"
+	PropertyVSwitchID       = "VSwitchId"
+	PropertyZoneID          = "ZoneId"
+	PropertyChargeType      = "ChargeType"
+	PropertyResourceGroupID = "ResourceGroupId"
+)
+
+// Properties holds additional attributes of a resource as returned by the Describe/List APIs
+type Properties map[string]string
+
+// Set stores the value under key, ignoring nil and empty values
+func (p Properties) Set(key string, value *string) {
+	if value == nil || *value == "" {
+		return
+	}
+	p[key] = *value
+}
+
+// SetBool stores a boolean value under key, ignoring nil values
+func (p Properties) SetBool(key string, value *bool) {
+	if value == nil {
+		return
+	}
+	p[key] = strconv.FormatBool(*value)
+}
+
+// SetInt32 stores an integer value under key, ignoring nil values
+func (p Properties) SetInt32(key string, value *int32) {
+	if value == nil {
+		return
+	}
+	p[key] = strconv.FormatInt(int64(*value), 10)
+}
+
+// SetInt64 stores an integer value under key, ignoring nil values
+func (p Properties) SetInt64(key string, value *int64) {
+	if value == nil {
+		return
+	}
+	p[key] = strconv.FormatInt(*value, 10)
+}
+
+// SetUnixMilli stores a millisecond timestamp under key in RFC 3339 format, ignoring nil and zero values
+func (p Properties) SetUnixMilli(key string, value *int64) {
+	if value == nil || *value == 0 {
+		return
+	}
+	p[key] = time.UnixMilli(*value).UTC().Format(time.RFC3339)
+}
+
+// SetTime stores a timestamp under key in RFC 3339 format, ignoring nil values
+func (p Properties) SetTime(key string, value *time.Time) {
+	if value == nil || value.IsZero() {
+		return
+	}
+	p[key] = value.UTC().Format(time.RFC3339)
+}
+
+// Tags holds the tags of a resource. It is nil for resource types whose API does not expose tags.
+type Tags map[string]string
+
+// Set stores a tag, ignoring tags without a key
+func (t Tags) Set(key *string, value *string) {
+	if key == nil || *key == "" {
+		return
+	}
+	v := ""
+	if value != nil {
+		v = *value
+	}
+	t[*key] = v
+}
+
+// Property returns a named attribute of the resource. Besides the keys of Properties it
+// understands "id", "name", "account", "region", "type" and "tag:<key>". Names are matched ignoring
+// case, dashes and underscores, so "vpc-id" finds "VpcId".
+func (r *Resource) Property(name string) (string, bool) {
+	if key, ok := strings.CutPrefix(name, "tag:"); ok {
+		value, ok := r.Tags[key]
+		return value, ok
+	}
+
+	normalized := normalizePropertyName(name)
+	switch normalized {
+	case "id", "resourceid":
+		return r.ResourceID, true
+	case "name", "resourcename":
+		return r.ResourceName, true
+	case "account", "accountid":
+		return r.AccountID, true
+	case "region":
+		return r.Region, true
+	case "type", "resourcetype":
+		return r.ProductName, true
+	}
+
+	if value, ok := r.Properties[name]; ok {
+		return value, true
+	}
+	for key, value := range r.Properties {
+		if normalizePropertyName(key) == normalized {
+			return value, true
+		}
+	}
+	return "", false
+}
+
+// normalizePropertyName lower-cases name and strips dashes and underscores
+func normalizePropertyName(name string) string {
+	name = strings.ReplaceAll(name, "-", "")
+	name = strings.ReplaceAll(name, "_", "")
+	return strings.ToLower(name)
+}
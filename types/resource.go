@@ -2,7 +2,7 @@ package types
 
 import (
 	"context"
-	"strings"
+	"slices"
 	"sync/atomic"
 	"time"
 
@@ -11,20 +11,49 @@ import (
 
 type Removable interface {
 	// Remove deletes the resource. Region is passed for resources that need regional context.
-	Remove(region string, resourceID string, resourceName string) error
+	Remove(ctx context.Context, region string, resourceID string, resourceName string) error
+}
+
+// DeletionStatus is the result of probing a resource whose delete call has returned
+type DeletionStatus int
+
+const (
+	DeletionPending  DeletionStatus = iota // Resource still exists, deletion in progress
+	DeletionComplete                       // Resource is gone
+	DeletionFailed                         // Resource reached a terminal failed status
+)
+
+// Verifiable is implemented by removers of resource types that are deleted asynchronously.
+// After a successful delete call the processor polls CheckDeletion until the resource is gone.
+type Verifiable interface {
+	CheckDeletion(ctx context.Context, region string, resourceID string) (DeletionStatus, error)
+}
+
+// CallLimiter paces API requests. Wait blocks before each request, Observe reports whether the request was throttled.
+type CallLimiter interface {
+	Wait(ctx context.Context) error
+	Observe(throttled bool)
 }
 
 type Resource struct {
 	Removable
+	AccountID    string // Alibaba Cloud account the resource belongs to
 	Region       string // Alibaba Cloud region ID (e.g., "cn-hangzhou")
 	ResourceID   string
 	ResourceName string
 	ProductName  string
+	Properties   Properties   // Additional attributes (VPC, status, charge type, creation time, ...)
+	Tags         Tags         // Resource tags; nil if the API of the resource type does not expose tags
+	TagsUnknown  bool         // The API exposes tags, but they could not be read for this resource
+	FilterReason string       // Why the resource was filtered, e.g. "region excluded"
 	state        atomic.Int32 // use State() and SetState() for thread-safe access
+	attempts     atomic.Int32 // Delete calls made across all waves
+	wave         atomic.Int32 // Wave of the last delete call
+	lastError    atomic.Pointer[ResourceError]
 }
 
 // ResourceCollector is a function that collects resources of a specific type in a given region
-type ResourceCollector func(creds *Credentials, region string) (Resources, error)
+type ResourceCollector func(ctx context.Context, creds *Credentials, region string) (Resources, error)
 
 type Resources []*Resource
 
@@ -40,6 +69,7 @@ const (
 	Filtered
 	Hidden
 	PendingRetry // Failed with retriable error, will be retried in next wave
+	Verifying    // Delete call succeeded, waiting for the resource to be gone
 )
 
 // State returns the current state of the resource (thread-safe)
@@ -47,52 +77,45 @@ func (r *Resource) State() ResourceState {
 	return ResourceState(r.state.Load())
 }
 
-// SetState sets the state of the resource (thread-safe)
+// SetState sets the state of the resource (thread-safe) and notifies the state observer
 func (r *Resource) SetState(s ResourceState) {
-	r.state.Store(int32(s))
+	from := ResourceState(r.state.Swap(int32(s)))
+	if observer := stateObserver.Load(); observer != nil && from != s {
+		(*observer)(r, from, s)
+	}
 }
 
-// isPermanentError returns true for errors that should not be retried
-func isPermanentError(errStr string) bool {
-	permanentErrors := []string{
-		"403",
-		"Forbidden",
-		"InvalidAccessKeyId",
-		"InvalidResourceId.NotFound",
-		"InvalidInstanceId.NotFound",
-		"InvalidVpcId.NotFound",
-		"InvalidVSwitchId.NotFound",
-	}
-	for _, e := range permanentErrors {
-		if strings.Contains(errStr, e) {
-			return true
-		}
+// StateObserver is called after every state transition of a resource
+type StateObserver func(r *Resource, from, to ResourceState)
+
+var stateObserver atomic.Pointer[StateObserver]
+
+// SetStateObserver installs the observer notified of state transitions, nil removes it
+func SetStateObserver(observer StateObserver) {
+	if observer == nil {
+		stateObserver.Store(nil)
+		return
 	}
-	return false
+	stateObserver.Store(&observer)
 }
 
-// isRetriableError returns true for errors that can succeed after dependencies are resolved
-func isRetriableError(errStr string) bool {
-	retriableErrors := []string{
-		"DependencyViolation",
-		"IncorrectInstanceStatus",
-		"OperationConflict",
-		"ServiceUnavailable",
-		"Throttling",
-		"HasMountTarget", // NAS file system has mount targets
-	}
-	for _, e := range retriableErrors {
-		if strings.Contains(errStr, e) {
-			return true
+// ParseResourceState returns the state with the given name
+func ParseResourceState(name string) (ResourceState, bool) {
+	for s := Ready; s <= Verifying; s++ {
+		if s.String() == name {
+			return s, true
 		}
 	}
-	return false
+	return Ready, false
 }
 
 // Remove attempts to delete the resource with retries for transient errors.
-// Sets state to Deleted on success, Failed on permanent error, PendingRetry on retriable error.
-func (r *Resource) Remove(ctx context.Context) error {
+// Sets state to Deleted on success (Verifying for Verifiable resources), Failed on permanent
+// error, PendingRetry on retriable error.
+// The error of the last attempt is kept together with the wave it occurred in, see LastError.
+func (r *Resource) Remove(ctx context.Context, wave int) error {
 	r.SetState(Removing)
+	r.wave.Store(int32(wave))
 
 	// Configure backoff for quick retries within a wave (handles transient network issues)
 	expBackoff := backoff.NewExponentialBackOff()
@@ -101,22 +124,16 @@ func (r *Resource) Remove(ctx context.Context) error {
 
 	var lastErr error
 	operation := func() (struct{}, error) {
-		err := r.Removable.Remove(r.Region, r.ResourceID, r.ResourceName)
+		r.attempts.Add(1)
+		err := r.Removable.Remove(ctx, r.Region, r.ResourceID, r.ResourceName)
 		if err != nil {
 			lastErr = err
-			errStr := err.Error()
-
-			// Permanent errors - stop retrying immediately
-			if isPermanentError(errStr) {
-				return struct{}{}, backoff.Permanent(err)
-			}
 
-			// Retriable errors - mark for wave-level retry, stop per-resource retry
-			if isRetriableError(errStr) {
+			// Only transient errors are retried with backoff. Dependency and throttling
+			// errors are retried in the next wave, everything else fails permanently.
+			if ClassifyError(r.ProductName, err) != ErrorTransient {
 				return struct{}{}, backoff.Permanent(err)
 			}
-
-			// Other errors - retry with backoff
 			return struct{}{}, err
 		}
 		return struct{}{}, nil
@@ -129,21 +146,65 @@ func (r *Resource) Remove(ctx context.Context) error {
 		if lastErr != nil {
 			errToCheck = lastErr
 		}
-		errStr := errToCheck.Error()
 
-		// Determine final state based on error type
-		if isRetriableError(errStr) {
+		// Determine final state based on error category
+		category := ClassifyError(r.ProductName, errToCheck)
+		if category == ErrorNotFound {
+			// Already gone, e.g. deleted together with its parent
+			r.SetLastError(nil)
+			r.SetState(Deleted)
+			return nil
+		}
+
+		// Record the error before the state changes, so that observers of the state see it
+		r.SetLastError(NewResourceError(r.ProductName, errToCheck, int(r.attempts.Load()), wave))
+		switch category {
+		case ErrorDependency, ErrorThrottled:
 			r.SetState(PendingRetry)
-		} else {
+		default:
 			r.SetState(Failed)
 		}
 		return errToCheck
 	}
+	r.SetLastError(nil)
 
+	if _, ok := r.Removable.(Verifiable); ok {
+		r.SetState(Verifying)
+		return nil
+	}
 	r.SetState(Deleted)
 	return nil
 }
 
+// Verify probes a resource in Verifying state and sets it to Deleted or Failed once its
+// deletion has finished. Probe errors other than not-found are ignored, the next probe decides.
+func (r *Resource) Verify(ctx context.Context) {
+	verifiable, ok := r.Removable.(Verifiable)
+	if !ok {
+		r.SetState(Deleted)
+		return
+	}
+
+	status, err := verifiable.CheckDeletion(ctx, r.Region, r.ResourceID)
+	if err != nil {
+		if ClassifyError(r.ProductName, err) == ErrorNotFound {
+			r.SetState(Deleted)
+		}
+		return
+	}
+	switch status {
+	case DeletionComplete:
+		r.SetState(Deleted)
+	case DeletionFailed:
+		r.SetLastError(&ResourceError{
+			Message:  "the resource reached a failed status while being deleted",
+			Attempts: int(r.attempts.Load()),
+			Wave:     int(r.wave.Load()),
+		})
+		r.SetState(Failed)
+	}
+}
+
 func (r Resources) NumOf(state ResourceState) int {
 	count := 0
 	for _, resource := range r {
@@ -157,3 +218,25 @@ func (r Resources) NumOf(state ResourceState) int {
 func (r Resources) VisibleCount() int {
 	return len(r) - r.NumOf(Hidden)
 }
+
+// ByAccount returns the resources of an account
+func (r Resources) ByAccount(accountID string) Resources {
+	var result Resources
+	for _, resource := range r {
+		if resource.AccountID == accountID {
+			result = append(result, resource)
+		}
+	}
+	return result
+}
+
+// Accounts returns the distinct account IDs of the resources in order of appearance
+func (r Resources) Accounts() []string {
+	var accounts []string
+	for _, resource := range r {
+		if !slices.Contains(accounts, resource.AccountID) {
+			accounts = append(accounts, resource.AccountID)
+		}
+	}
+	return accounts
+}
@@ -15,11 +15,12 @@ func _() {
 	_ = x[Filtered-4]
 	_ = x[Hidden-5]
 	_ = x[PendingRetry-6]
+	_ = x[Verifying-7]
 }
 
-const _ResourceState_name = "ReadyRemovingDeletedFailedFilteredHiddenPendingRetry"
+const _ResourceState_name = "ReadyRemovingDeletedFailedFilteredHiddenPendingRetryVerifying"
 
-var _ResourceState_index = [...]uint8{0, 5, 13, 20, 26, 34, 40, 52}
+var _ResourceState_index = [...]uint8{0, 5, 13, 20, 26, 34, 40, 52, 61}
 
 func (i ResourceState) String() string {
 	if i < 0 || i >= ResourceState(len(_ResourceState_index)-1) {
@@ -0,0 +1,171 @@
+package utils
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+
+	openapi "github.com/alibabacloud-go/darabonba-openapi/v2/client"
+	"github.com/alibabacloud-go/tea/dara"
+	"github.com/alibabacloud-go/tea/tea"
+	"github.com/aliyun/credentials-go/credentials/providers"
+
+	"github.com/arafato/ali-nuke/types"
+)
+
+// AccountIdentity describes the account and principal the credentials belong to
+type AccountIdentity struct {
+	AccountID    string
+	Alias        string // Empty if the alias could not be read
+	Arn          string
+	IdentityType string // Account, RamUser or AssumedRoleUser
+}
+
+// String returns the account ID followed by the alias, if known
+func (a *AccountIdentity) String() string {
+	if a.Alias == "" {
+		return a.AccountID
+	}
+	return fmt.Sprintf("%s (%s)", a.AccountID, a.Alias)
+}
+
+// GetCallerIdentity resolves the account of the credentials via STS GetCallerIdentity and
+// reads its alias via RAM GetAccountAlias. STS is called in the given region, usually the
+// global region of the settings. A missing permission for the alias is not an error, the
+// alias is left empty instead.
+func GetCallerIdentity(ctx context.Context, creds *types.Credentials, region string) (*AccountIdentity, error) {
+	client, err := GetSTSClient(ctx, creds, region)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create STS client: %w", err)
+	}
+
+	response, err := client.GetCallerIdentity()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get caller identity: %w", err)
+	}
+	if response.Body == nil || tea.StringValue(response.Body.AccountId) == "" {
+		return nil, fmt.Errorf("no account ID returned from GetCallerIdentity API")
+	}
+
+	identity := &AccountIdentity{
+		AccountID:    tea.StringValue(response.Body.AccountId),
+		Arn:          tea.StringValue(response.Body.Arn),
+		IdentityType: tea.StringValue(response.Body.IdentityType),
+	}
+	identity.Alias, _ = getAccountAlias(ctx, creds)
+
+	return identity, nil
+}
+
+// getAccountAlias returns the alias of the account the credentials belong to
+func getAccountAlias(ctx context.Context, creds *types.Credentials) (string, error) {
+	client, err := GetRAMClient(ctx, creds)
+	if err != nil {
+		return "", err
+	}
+
+	body, err := callRPC(client, "GetAccountAlias", "2015-05-01", nil)
+	if err != nil {
+		return "", err
+	}
+	alias, _ := body["AccountAlias"].(string)
+	return alias, nil
+}
+
+// callRPC calls an RPC-style API with a generic client and returns the decoded response body
+func callRPC(client *openapi.Client, action, version string, query map[string]*string) (map[string]any, error) {
+	params := &openapi.Params{
+		Action:      tea.String(action),
+		Version:     tea.String(version),
+		Protocol:    tea.String("HTTPS"),
+		Pathname:    tea.String("/"),
+		Method:      tea.String("POST"),
+		AuthType:    tea.String("AK"),
+		Style:       tea.String("RPC"),
+		ReqBodyType: tea.String("formData"),
+		BodyType:    tea.String("json"),
+	}
+	request := &openapi.OpenApiRequest{Query: query}
+
+	result, err := client.CallApi(params, request, &dara.RuntimeOptions{})
+	if err != nil {
+		return nil, err
+	}
+	body, ok := result["body"].(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("unexpected response of %s", action)
+	}
+	return body, nil
+}
+
+// AssumeAccountRole returns credentials for another account, obtained by assuming roleArn with
+// the base credentials
+func AssumeAccountRole(base *types.Credentials, accountID, roleArn, sessionName string) (*types.Credentials, error) {
+	if sessionName == "" {
+		sessionName = "ali-nuke"
+	}
+	provider, err := providers.NewRAMRoleARNCredentialsProviderBuilder().
+		WithCredentialsProvider(base.Provider).
+		WithRoleArn(roleArn).
+		WithRoleSessionName(sessionName).
+		Build()
+	if err != nil {
+		return nil, fmt.Errorf("failed to set up role %s: %w", roleArn, err)
+	}
+	if _, err := provider.GetCredentials(); err != nil {
+		return nil, fmt.Errorf("failed to assume role %s: %w", roleArn, err)
+	}
+
+	return &types.Credentials{
+		Provider:  provider,
+		Source:    fmt.Sprintf("role %s assumed with %s", roleArn, base.Source),
+		AccountID: accountID,
+	}, nil
+}
+
+// ListFolderAccounts returns the IDs of the member accounts in a Resource Directory folder.
+// Accounts in subfolders are not included.
+func ListFolderAccounts(ctx context.Context, creds *types.Credentials, folderID string) ([]string, error) {
+	client, err := GetResourceManagerClient(ctx, creds)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Resource Manager client: %w", err)
+	}
+
+	var accounts []string
+	pageNumber := 1
+	pageSize := 100
+
+	// Paginate through all accounts of the folder
+	for {
+		body, err := callRPC(client, "ListAccountsForParent", "2020-03-31", map[string]*string{
+			"ParentFolderId": tea.String(folderID),
+			"PageNumber":     tea.String(strconv.Itoa(pageNumber)),
+			"PageSize":       tea.String(strconv.Itoa(pageSize)),
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to list accounts of folder %s: %w", folderID, err)
+		}
+
+		page := 0
+		if list, ok := body["Accounts"].(map[string]any); ok {
+			items, _ := list["Account"].([]any)
+			for _, item := range items {
+				account, _ := item.(map[string]any)
+				if id, ok := account["AccountId"].(string); ok && id != "" {
+					accounts = append(accounts, id)
+				}
+			}
+			page = len(items)
+		}
+
+		totalCount, _ := body["TotalCount"].(json.Number)
+		total, _ := totalCount.Int64()
+		if page == 0 || int64(len(accounts)) >= total {
+			break
+		}
+		pageNumber++
+	}
+
+	return accounts, nil
+}
@@ -1,6 +1,16 @@
 package utils
 
 import (
+	"bytes"
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"reflect"
+	"regexp"
+	"sync"
+	"time"
+
 	alb "github.com/alibabacloud-go/alb-20200616/v2/client"
 	cbn "github.com/alibabacloud-go/cbn-20170912/v2/client"
 	cr "github.com/alibabacloud-go/cr-20181201/v2/client"
@@ -15,166 +25,350 @@ import (
 	r_kvstore "github.com/alibabacloud-go/r-kvstore-20150101/v4/client"
 	rds "github.com/alibabacloud-go/rds-20140815/v4/client"
 	slb "github.com/alibabacloud-go/slb-20140515/v4/client"
+	sts "github.com/alibabacloud-go/sts-20150401/v2/client"
 	"github.com/alibabacloud-go/tea/tea"
 	vpc "github.com/alibabacloud-go/vpc-20160428/v6/client"
 	"github.com/aliyun/alibabacloud-oss-go-sdk-v2/oss"
-	"github.com/aliyun/alibabacloud-oss-go-sdk-v2/oss/credentials"
+	credential "github.com/aliyun/credentials-go/credentials"
 
 	"github.com/arafato/ali-nuke/types"
 )
 
-// CreateECSClient creates an ECS client for a specific region
-func CreateECSClient(creds *types.Credentials, region string) (*ecs.Client, error) {
-	config := &openapi.Config{
-		AccessKeyId:     tea.String(creds.AccessKeyID),
-		AccessKeySecret: tea.String(creds.AccessKeySecret),
-		RegionId:        tea.String(region),
-	}
+// sharedTransport is the transport of sharedHTTPClient
+var sharedTransport = &http.Transport{
+	Proxy:               http.ProxyFromEnvironment,
+	MaxIdleConns:        200,
+	MaxIdleConnsPerHost: 20,
+	IdleConnTimeout:     90 * time.Second,
+	TLSHandshakeTimeout: 10 * time.Second,
+}
 
-	return ecs.NewClient(config)
+// sharedHTTPClient holds the timeout and transport of the HTTP clients of all SDK clients, so
+// that connections are pooled and reused across products and regions
+var sharedHTTPClient = &http.Client{
+	Timeout:   60 * time.Second,
+	Transport: sharedTransport,
 }
 
-// CreateVPCClient creates a VPC client for a specific region
-func CreateVPCClient(creds *types.Credentials, region string) (*vpc.Client, error) {
-	config := &openapi.Config{
-		AccessKeyId:     tea.String(creds.AccessKeyID),
-		AccessKeySecret: tea.String(creds.AccessKeySecret),
-		RegionId:        tea.String(region),
+// redirectAddr is the address all requests are sent to, see RedirectEndpoints
+var redirectAddr string
+
+// RedirectEndpoints sends the requests of all products to a single address over plain HTTP,
+// e.g. to a mockcloud server in tests. The endpoints become <product>.<region>.aliyuncs.com,
+// so that the server can tell products and regions apart. It must be called before the first
+// client is created.
+func RedirectEndpoints(addr string) {
+	redirectAddr = addr
+	// Cached configurations still hold the previous endpoints
+	openAPIConfigs.Clear()
+	// Pooled connections still lead to the previous address
+	sharedTransport.CloseIdleConnections()
+	sharedTransport.Proxy = nil
+	sharedTransport.DialContext = func(ctx context.Context, network, _ string) (net.Conn, error) {
+		var dialer net.Dialer
+		return dialer.DialContext(ctx, network, addr)
 	}
+}
 
-	return vpc.NewClient(config)
+// CloseIdleConnections closes the pooled keep-alive connections of all SDK clients, e.g. in
+// tests once the server the endpoints were redirected to is closed
+func CloseIdleConnections() {
+	sharedTransport.CloseIdleConnections()
 }
 
-// CreateNASClient creates a NAS client for a specific region
-func CreateNASClient(creds *types.Credentials, region string) (*nas.Client, error) {
-	// NAS requires a region-specific endpoint
-	endpoint := "nas." + region + ".aliyuncs.com"
-	config := &openapi.Config{
-		AccessKeyId:     tea.String(creds.AccessKeyID),
-		AccessKeySecret: tea.String(creds.AccessKeySecret),
-		RegionId:        tea.String(region),
-		Endpoint:        tea.String(endpoint),
-	}
+// redirectedEndpoint returns the endpoint of a product in a region while endpoints are redirected
+func redirectedEndpoint(product, region string) string {
+	return product + "." + region + ".aliyuncs.com"
+}
+
+// RequestLimiter returns the limiter pacing the requests of an account to a product in a region
+type RequestLimiter func(accountID, product, region string) types.CallLimiter
 
-	return nas.NewClient(config)
+// requestLimiter paces every request sent by the SDK clients, nil if requests are not paced
+var requestLimiter RequestLimiter
+
+// SetRequestLimiter makes every API request wait for the limiter of its account, product and
+// region, and report to it whether the request was throttled
+func SetRequestLimiter(limiter RequestLimiter) {
+	requestLimiter = limiter
 }
 
-// CreateESSClient creates an Auto Scaling (ESS) client for a specific region
-func CreateESSClient(creds *types.Credentials, region string) (*ess.Client, error) {
-	config := &openapi.Config{
-		AccessKeyId:     tea.String(creds.AccessKeyID),
-		AccessKeySecret: tea.String(creds.AccessKeySecret),
-		RegionId:        tea.String(region),
-	}
-	return ess.NewClient(config)
+// limitedTransport sends the requests of a product in a region through the transport of
+// sharedHTTPClient, paced by the request limiter. The OpenAPI SDK sends its requests without a
+// context, so each request is bound to the context the client was created with as well:
+// cancelling it, or reaching its deadline, aborts both the wait for the limiter and the request.
+type limitedTransport struct {
+	ctx     context.Context
+	creds   *types.Credentials
+	product string
+	region  string
 }
 
-// CreateCRClient creates a Container Registry client for a specific region
-func CreateCRClient(creds *types.Credentials, region string) (*cr.Client, error) {
-	config := &openapi.Config{
-		AccessKeyId:     tea.String(creds.AccessKeyID),
-		AccessKeySecret: tea.String(creds.AccessKeySecret),
-		RegionId:        tea.String(region),
+func (t limitedTransport) RoundTrip(request *http.Request) (*http.Response, error) {
+	// Done when either the client context or the request context is done, e.g. by the
+	// timeout of the HTTP client
+	ctx, cancel := context.WithCancel(t.ctx)
+	stop := context.AfterFunc(request.Context(), cancel)
+	release := func() {
+		stop()
+		cancel()
 	}
-	return cr.NewClient(config)
-}
+	request = request.WithContext(ctx)
 
-// CreateSLBClient creates a Classic Load Balancer (SLB) client for a specific region
-func CreateSLBClient(creds *types.Credentials, region string) (*slb.Client, error) {
-	config := &openapi.Config{
-		AccessKeyId:     tea.String(creds.AccessKeyID),
-		AccessKeySecret: tea.String(creds.AccessKeySecret),
-		RegionId:        tea.String(region),
+	var limiter types.CallLimiter
+	if requestLimiter != nil {
+		limiter = requestLimiter(t.creds.AccountID, t.product, t.region)
+		if err := limiter.Wait(ctx); err != nil {
+			release()
+			return nil, err
+		}
+	}
+	response, err := sharedHTTPClient.Transport.RoundTrip(request)
+	if limiter != nil {
+		limiter.Observe(err == nil && isThrottled(response))
+	}
+	if err != nil {
+		release()
+		return nil, err
 	}
-	return slb.NewClient(config)
+	// The body is read after RoundTrip returns, the context must live until it is closed
+	response.Body = releasingBody{ReadCloser: response.Body, release: release}
+	return response, nil
 }
 
-// CreateALBClient creates an Application Load Balancer (ALB) client for a specific region
-func CreateALBClient(creds *types.Credentials, region string) (*alb.Client, error) {
-	config := &openapi.Config{
-		AccessKeyId:     tea.String(creds.AccessKeyID),
-		AccessKeySecret: tea.String(creds.AccessKeySecret),
-		RegionId:        tea.String(region),
-	}
-	return alb.NewClient(config)
+// releasingBody is a response body that releases the context of its request when closed
+type releasingBody struct {
+	io.ReadCloser
+	release func()
 }
 
-// CreateNLBClient creates a Network Load Balancer (NLB) client for a specific region
-func CreateNLBClient(creds *types.Credentials, region string) (*nlb.Client, error) {
-	config := &openapi.Config{
-		AccessKeyId:     tea.String(creds.AccessKeyID),
-		AccessKeySecret: tea.String(creds.AccessKeySecret),
-		RegionId:        tea.String(region),
+func (b releasingBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.release()
+	return err
+}
+
+// newHTTPClient returns the HTTP client of a product in a region, whose requests are bound to
+// ctx. All clients share the connections of sharedHTTPClient.
+func newHTTPClient(ctx context.Context, creds *types.Credentials, product, region string) *http.Client {
+	return &http.Client{
+		Timeout:   sharedHTTPClient.Timeout,
+		Transport: limitedTransport{ctx: ctx, creds: creds, product: product, region: region},
 	}
-	return nlb.NewClient(config)
 }
 
-// CreateRDSClient creates an RDS client for a specific region
-func CreateRDSClient(creds *types.Credentials, region string) (*rds.Client, error) {
-	config := &openapi.Config{
-		AccessKeyId:     tea.String(creds.AccessKeyID),
-		AccessKeySecret: tea.String(creds.AccessKeySecret),
-		RegionId:        tea.String(region),
+// darabonbaHTTPClient adapts an HTTP client to the HTTP client interface of the OpenAPI SDK
+type darabonbaHTTPClient struct {
+	client *http.Client
+}
+
+func (c darabonbaHTTPClient) Call(request *http.Request, _ *http.Transport) (*http.Response, error) {
+	return c.client.Do(request)
+}
+
+// errorCodeRegexp finds the error code in a JSON (OpenAPI) or XML (OSS) error response
+var errorCodeRegexp = regexp.MustCompile(`"[Cc]ode"\s*:\s*"([^"]*)"|<Code>([^<]*)</Code>`)
+
+// isThrottled reports whether a response is a throttling error. The body of error responses
+// is read to find the error code and replaced for the SDK to parse.
+func isThrottled(response *http.Response) bool {
+	if response.StatusCode == http.StatusTooManyRequests {
+		return true
 	}
-	return rds.NewClient(config)
+	if response.StatusCode < http.StatusBadRequest {
+		return false
+	}
+
+	body, err := io.ReadAll(response.Body)
+	response.Body.Close()
+	response.Body = io.NopCloser(bytes.NewReader(body))
+	if err != nil {
+		return false
+	}
+	m := errorCodeRegexp.FindSubmatch(body)
+	if m == nil {
+		return false
+	}
+	code := string(m[1]) + string(m[2])
+	err = &tea.SDKError{Code: tea.String(code), StatusCode: tea.Int(response.StatusCode)}
+	return types.ClassifyError("", err) == types.ErrorThrottled
 }
 
-// CreateRedisClient creates a Redis (KVStore) client for a specific region
-func CreateRedisClient(creds *types.Credentials, region string) (*r_kvstore.Client, error) {
-	config := &openapi.Config{
-		AccessKeyId:     tea.String(creds.AccessKeyID),
-		AccessKeySecret: tea.String(creds.AccessKeySecret),
-		RegionId:        tea.String(region),
+// endpointTemplates holds products whose endpoint cannot be resolved from the region by the SDK
+var endpointTemplates = map[string]func(region string) string{
+	"nas":             func(region string) string { return "nas." + region + ".aliyuncs.com" },
+	"ram":             func(string) string { return "ram.aliyuncs.com" },
+	"resourcemanager": func(string) string { return "resourcemanager.aliyuncs.com" },
+}
+
+// openAPIConfigKey identifies the configuration of the clients of a product in a region
+type openAPIConfigKey struct {
+	product string
+	region  string
+	creds   *types.Credentials
+}
+
+// openAPIConfigs caches the configuration of the OpenAPI clients by openAPIConfigKey. The
+// configurations hold no HTTP client, it is bound to the context of each caller.
+var openAPIConfigs sync.Map
+
+// newOpenAPIClient returns a new client of an OpenAPI product whose requests are bound to ctx,
+// see limitedTransport.
+//
+// The SDK clients are not shared: every call sets the Headers and Spi fields of the client for
+// its request, so concurrent calls on one client would mix them up. What does not change
+// between clients is cached instead, the configuration with the credential and the endpoint
+// the first client resolved from the region. All clients share the connections of
+// sharedHTTPClient.
+func newOpenAPIClient[T any](ctx context.Context, product string, creds *types.Credentials, region string, newClient func(*openapi.Config) (T, error)) (T, error) {
+	key := openAPIConfigKey{product: product, region: region, creds: creds}
+	cached, resolved := openAPIConfigs.Load(key)
+	if !resolved {
+		cached = newOpenAPIConfig(product, creds, region)
+	}
+
+	// The client keeps the configuration it was created with, each one gets a copy
+	config := *cached.(*openapi.Config)
+	config.HttpClient = darabonbaHTTPClient{newHTTPClient(ctx, creds, product, region)}
+	client, err := newClient(&config)
+	if err == nil && !resolved {
+		template := *cached.(*openapi.Config)
+		template.Endpoint = resolvedEndpoint(client)
+		openAPIConfigs.Store(key, &template)
 	}
-	return r_kvstore.NewClient(config)
+	return client, err
 }
 
-// CreateMongoDBClient creates a MongoDB (DDS) client for a specific region
-func CreateMongoDBClient(creds *types.Credentials, region string) (*dds.Client, error) {
-	config := &openapi.Config{
-		AccessKeyId:     tea.String(creds.AccessKeyID),
-		AccessKeySecret: tea.String(creds.AccessKeySecret),
-		RegionId:        tea.String(region),
+// resolvedEndpoint returns the endpoint an SDK client resolved from its region when it was
+// created. The clients of all products are or embed an openapi.Client, which does not expose
+// the endpoint by a method.
+func resolvedEndpoint(client any) *string {
+	v := reflect.ValueOf(client)
+	if v.Kind() != reflect.Pointer || v.IsNil() || v.Elem().Kind() != reflect.Struct {
+		return nil
+	}
+	field := v.Elem().FieldByName("Endpoint")
+	if !field.IsValid() {
+		return nil
 	}
-	return dds.NewClient(config)
+	endpoint, _ := field.Interface().(*string)
+	return endpoint
 }
 
-// CreatePolarDBClient creates a PolarDB client for a specific region
-func CreatePolarDBClient(creds *types.Credentials, region string) (*polardb.Client, error) {
+// newOpenAPIConfig builds the SDK configuration for a product in a region, without an HTTP client
+func newOpenAPIConfig(product string, creds *types.Credentials, region string) *openapi.Config {
 	config := &openapi.Config{
-		AccessKeyId:     tea.String(creds.AccessKeyID),
-		AccessKeySecret: tea.String(creds.AccessKeySecret),
-		RegionId:        tea.String(region),
+		Credential: credential.FromCredentialsProvider("ali-nuke", creds.Provider),
+		RegionId:   tea.String(region),
+	}
+	if endpoint, ok := endpointTemplates[product]; ok {
+		config.Endpoint = tea.String(endpoint(region))
 	}
-	return polardb.NewClient(config)
+	if redirectAddr != "" {
+		config.Endpoint = tea.String(redirectedEndpoint(product, region))
+		config.Protocol = tea.String("http")
+	}
+	return config
 }
 
-// CreateOSSClient creates an OSS client for a specific region
-func CreateOSSClient(creds *types.Credentials, region string) (*oss.Client, error) {
-	provider := credentials.NewStaticCredentialsProvider(creds.AccessKeyID, creds.AccessKeySecret)
-	cfg := oss.LoadDefaultConfig().
-		WithCredentialsProvider(provider).
-		WithRegion(region)
+// GetECSClient returns a new ECS client for a specific region
+func GetECSClient(ctx context.Context, creds *types.Credentials, region string) (*ecs.Client, error) {
+	return newOpenAPIClient(ctx, "ecs", creds, region, ecs.NewClient)
+}
 
-	return oss.NewClient(cfg), nil
+// GetVPCClient returns a new VPC client for a specific region
+func GetVPCClient(ctx context.Context, creds *types.Credentials, region string) (*vpc.Client, error) {
+	return newOpenAPIClient(ctx, "vpc", creds, region, vpc.NewClient)
 }
 
-// CreateCSClient creates a Container Service (ACK) client for a specific region
-func CreateCSClient(creds *types.Credentials, region string) (*cs.Client, error) {
-	config := &openapi.Config{
-		AccessKeyId:     tea.String(creds.AccessKeyID),
-		AccessKeySecret: tea.String(creds.AccessKeySecret),
-		RegionId:        tea.String(region),
-	}
-	return cs.NewClient(config)
+// GetNASClient returns a new NAS client for a specific region
+func GetNASClient(ctx context.Context, creds *types.Credentials, region string) (*nas.Client, error) {
+	return newOpenAPIClient(ctx, "nas", creds, region, nas.NewClient)
 }
 
-// CreateCENClient creates a Cloud Enterprise Network (CEN) client for a specific region
-func CreateCENClient(creds *types.Credentials, region string) (*cbn.Client, error) {
-	config := &openapi.Config{
-		AccessKeyId:     tea.String(creds.AccessKeyID),
-		AccessKeySecret: tea.String(creds.AccessKeySecret),
-		RegionId:        tea.String(region),
+// GetESSClient returns a new Auto Scaling (ESS) client for a specific region
+func GetESSClient(ctx context.Context, creds *types.Credentials, region string) (*ess.Client, error) {
+	return newOpenAPIClient(ctx, "ess", creds, region, ess.NewClient)
+}
+
+// GetCRClient returns a new Container Registry client for a specific region
+func GetCRClient(ctx context.Context, creds *types.Credentials, region string) (*cr.Client, error) {
+	return newOpenAPIClient(ctx, "cr", creds, region, cr.NewClient)
+}
+
+// GetSLBClient returns a new Classic Load Balancer (SLB) client for a specific region
+func GetSLBClient(ctx context.Context, creds *types.Credentials, region string) (*slb.Client, error) {
+	return newOpenAPIClient(ctx, "slb", creds, region, slb.NewClient)
+}
+
+// GetALBClient returns a new Application Load Balancer (ALB) client for a specific region
+func GetALBClient(ctx context.Context, creds *types.Credentials, region string) (*alb.Client, error) {
+	return newOpenAPIClient(ctx, "alb", creds, region, alb.NewClient)
+}
+
+// GetNLBClient returns a new Network Load Balancer (NLB) client for a specific region
+func GetNLBClient(ctx context.Context, creds *types.Credentials, region string) (*nlb.Client, error) {
+	return newOpenAPIClient(ctx, "nlb", creds, region, nlb.NewClient)
+}
+
+// GetRDSClient returns a new RDS client for a specific region
+func GetRDSClient(ctx context.Context, creds *types.Credentials, region string) (*rds.Client, error) {
+	return newOpenAPIClient(ctx, "rds", creds, region, rds.NewClient)
+}
+
+// GetRedisClient returns a new Redis (KVStore) client for a specific region
+func GetRedisClient(ctx context.Context, creds *types.Credentials, region string) (*r_kvstore.Client, error) {
+	return newOpenAPIClient(ctx, "r-kvstore", creds, region, r_kvstore.NewClient)
+}
+
+// GetMongoDBClient returns a new MongoDB (DDS) client for a specific region
+func GetMongoDBClient(ctx context.Context, creds *types.Credentials, region string) (*dds.Client, error) {
+	return newOpenAPIClient(ctx, "dds", creds, region, dds.NewClient)
+}
+
+// GetPolarDBClient returns a new PolarDB client for a specific region
+func GetPolarDBClient(ctx context.Context, creds *types.Credentials, region string) (*polardb.Client, error) {
+	return newOpenAPIClient(ctx, "polardb", creds, region, polardb.NewClient)
+}
+
+// GetCSClient returns a new Container Service (ACK) client for a specific region
+func GetCSClient(ctx context.Context, creds *types.Credentials, region string) (*cs.Client, error) {
+	return newOpenAPIClient(ctx, "cs", creds, region, cs.NewClient)
+}
+
+// GetCENClient returns a new Cloud Enterprise Network (CEN) client for a specific region
+func GetCENClient(ctx context.Context, creds *types.Credentials, region string) (*cbn.Client, error) {
+	return newOpenAPIClient(ctx, "cbn", creds, region, cbn.NewClient)
+}
+
+// GetSTSClient returns a new STS client for a specific region
+func GetSTSClient(ctx context.Context, creds *types.Credentials, region string) (*sts.Client, error) {
+	return newOpenAPIClient(ctx, "sts", creds, region, sts.NewClient)
+}
+
+// GetRAMClient returns a new generic client for the global RAM API, which has no
+// dedicated SDK dependency here. Use it with callRPC.
+func GetRAMClient(ctx context.Context, creds *types.Credentials) (*openapi.Client, error) {
+	return newOpenAPIClient(ctx, "ram", creds, "global", openapi.NewClient)
+}
+
+// GetResourceManagerClient returns a new generic client for the global Resource
+// Manager API (Resource Directory). Use it with callRPC.
+func GetResourceManagerClient(ctx context.Context, creds *types.Credentials) (*openapi.Client, error) {
+	return newOpenAPIClient(ctx, "resourcemanager", creds, "global", openapi.NewClient)
+}
+
+// GetOSSClient returns a new OSS client for a specific region
+func GetOSSClient(ctx context.Context, creds *types.Credentials, region string) (*oss.Client, error) {
+	cfg := oss.LoadDefaultConfig().
+		WithCredentialsProvider(ossCredentialsProvider(creds)).
+		WithRegion(region).
+		WithHttpClient(newHTTPClient(ctx, creds, "oss", region))
+	if redirectAddr != "" {
+		cfg = cfg.WithEndpoint(redirectedEndpoint("oss", region)).
+			WithDisableSSL(true).
+			WithUsePathStyle(true)
 	}
-	return cbn.NewClient(config)
+
+	return oss.NewClient(cfg), nil
 }
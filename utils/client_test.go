@@ -0,0 +1,120 @@
+package utils
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	openapi "github.com/alibabacloud-go/darabonba-openapi/v2/client"
+	"github.com/alibabacloud-go/tea/tea"
+	"github.com/aliyun/credentials-go/credentials/providers"
+
+	"github.com/arafato/ali-nuke/types"
+)
+
+// blockingLimiter never lets a request through before the context is done
+type blockingLimiter struct{}
+
+func (blockingLimiter) Wait(ctx context.Context) error {
+	<-ctx.Done()
+	return ctx.Err()
+}
+
+func (blockingLimiter) Observe(bool) {}
+
+// testCredentials returns static credentials for requests to a test server
+func testCredentials(t *testing.T) *types.Credentials {
+	t.Helper()
+	provider, err := providers.NewStaticAKCredentialsProviderBuilder().
+		WithAccessKeyId("id").
+		WithAccessKeySecret("secret").
+		Build()
+	if err != nil {
+		t.Fatalf("credentials: %v", err)
+	}
+	return &types.Credentials{Provider: provider, AccountID: "1234567890123456"}
+}
+
+func TestClientRequestsBoundToContext(t *testing.T) {
+	// The server answers only once the test is over
+	done := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-done:
+		case <-r.Context().Done():
+		}
+	}))
+	t.Cleanup(srv.Close)
+	t.Cleanup(func() { close(done) })
+	RedirectEndpoints(strings.TrimPrefix(srv.URL, "http://"))
+
+	tests := []struct {
+		name    string
+		limiter RequestLimiter
+	}{
+		{"in-flight request", nil},
+		{"limiter wait", func(string, string, string) types.CallLimiter { return blockingLimiter{} }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			SetRequestLimiter(tt.limiter)
+			t.Cleanup(func() { SetRequestLimiter(nil) })
+
+			ctx, cancel := context.WithTimeout(t.Context(), 100*time.Millisecond)
+			defer cancel()
+			start := time.Now()
+			_, err := FetchAllRegions(ctx, testCredentials(t), "cn-hangzhou")
+			if err == nil {
+				t.Fatal("FetchAllRegions succeeded, want the deadline to abort it")
+			}
+			if !errors.Is(err, context.DeadlineExceeded) && !strings.Contains(err.Error(), context.DeadlineExceeded.Error()) {
+				t.Errorf("error = %v, want deadline exceeded", err)
+			}
+			if elapsed := time.Since(start); elapsed > 10*time.Second {
+				t.Errorf("call returned after %s, want it aborted at the deadline", elapsed)
+			}
+		})
+	}
+}
+
+func TestOpenAPIConfigCached(t *testing.T) {
+	// The endpoint is resolved by the SDK, no request is sent
+	old := redirectAddr
+	redirectAddr = ""
+	openAPIConfigs.Clear()
+	t.Cleanup(func() {
+		redirectAddr = old
+		openAPIConfigs.Clear()
+	})
+	creds := testCredentials(t)
+
+	first, err := GetVPCClient(t.Context(), creds, "cn-shanghai")
+	if err != nil {
+		t.Fatal(err)
+	}
+	cached, ok := openAPIConfigs.Load(openAPIConfigKey{product: "vpc", region: "cn-shanghai", creds: creds})
+	if !ok {
+		t.Fatal("configuration of vpc in cn-shanghai not cached")
+	}
+	config := cached.(*openapi.Config)
+	if tea.StringValue(config.Endpoint) != "vpc.cn-shanghai.aliyuncs.com" || config.HttpClient != nil {
+		t.Errorf("cached endpoint %q, HTTP client %v; want the resolved endpoint and no HTTP client",
+			tea.StringValue(config.Endpoint), config.HttpClient)
+	}
+
+	second, err := GetVPCClient(t.Context(), creds, "cn-shanghai")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if first == second || tea.StringValue(second.Endpoint) != tea.StringValue(first.Endpoint) {
+		t.Errorf("second client %p with endpoint %q, want a new client with endpoint %q",
+			second, tea.StringValue(second.Endpoint), tea.StringValue(first.Endpoint))
+	}
+	if _, ok := openAPIConfigs.Load(openAPIConfigKey{product: "vpc", region: "cn-shanghai", creds: testCredentials(t)}); ok {
+		t.Error("configuration shared between credentials")
+	}
+}
@@ -0,0 +1,108 @@
+package utils
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aliyun/alibabacloud-oss-go-sdk-v2/oss/credentials"
+	"github.com/aliyun/credentials-go/credentials/providers"
+
+	"github.com/arafato/ali-nuke/types"
+)
+
+// CredentialOptions selects how credentials are resolved
+type CredentialOptions struct {
+	AccessKeyID     string
+	AccessKeySecret string
+	SecurityToken   string // STS token belonging to AccessKeyID
+	Profile         string // Profile in ~/.aliyun/config.json
+	ECSRAMRole      string // RAM role attached to the ECS instance the tool runs on
+	RoleArn         string // Role to assume with the resolved credentials
+	RoleSessionName string
+}
+
+// ResolveCredentials builds the credential provider. Explicit access keys (optionally with an
+// STS token) take precedence, then a named CLI profile, then the ECS instance RAM role.
+// Without any of them the default chain is used: ALIBABA_CLOUD_* environment variables,
+// OIDC, the current profile of ~/.aliyun/config.json and the ECS instance RAM role.
+// If RoleArn is set, the resolved credentials are used to assume that role.
+func ResolveCredentials(opts CredentialOptions) (*types.Credentials, error) {
+	var provider providers.CredentialsProvider
+	var source string
+	var err error
+
+	switch {
+	case opts.AccessKeyID != "" || opts.AccessKeySecret != "":
+		if opts.AccessKeyID == "" || opts.AccessKeySecret == "" {
+			return nil, fmt.Errorf("access key ID and secret must be set together")
+		}
+		if opts.SecurityToken != "" {
+			provider, err = providers.NewStaticSTSCredentialsProviderBuilder().
+				WithAccessKeyId(opts.AccessKeyID).
+				WithAccessKeySecret(opts.AccessKeySecret).
+				WithSecurityToken(opts.SecurityToken).
+				Build()
+			source = "STS token"
+		} else {
+			provider, err = providers.NewStaticAKCredentialsProviderBuilder().
+				WithAccessKeyId(opts.AccessKeyID).
+				WithAccessKeySecret(opts.AccessKeySecret).
+				Build()
+			source = "access key"
+		}
+	case opts.Profile != "":
+		provider, err = providers.NewCLIProfileCredentialsProviderBuilder().
+			WithProfileName(opts.Profile).
+			Build()
+		source = "profile " + opts.Profile
+	case opts.ECSRAMRole != "":
+		provider, err = providers.NewECSRAMRoleCredentialsProviderBuilder().
+			WithRoleName(opts.ECSRAMRole).
+			Build()
+		source = "ECS RAM role " + opts.ECSRAMRole
+	default:
+		provider = providers.NewDefaultCredentialsProvider()
+		source = "default credential chain"
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to set up credentials from %s: %w", source, err)
+	}
+
+	if opts.RoleArn != "" {
+		sessionName := opts.RoleSessionName
+		if sessionName == "" {
+			sessionName = "ali-nuke"
+		}
+		provider, err = providers.NewRAMRoleARNCredentialsProviderBuilder().
+			WithCredentialsProvider(provider).
+			WithRoleArn(opts.RoleArn).
+			WithRoleSessionName(sessionName).
+			Build()
+		if err != nil {
+			return nil, fmt.Errorf("failed to set up role %s: %w", opts.RoleArn, err)
+		}
+		source = fmt.Sprintf("role %s assumed with %s", opts.RoleArn, source)
+	}
+
+	// Resolve once so that missing or invalid credentials fail before scanning
+	if _, err := provider.GetCredentials(); err != nil {
+		return nil, fmt.Errorf("failed to get credentials from %s: %w", source, err)
+	}
+
+	return &types.Credentials{Provider: provider, Source: source}, nil
+}
+
+// ossCredentialsProvider adapts a credential provider to the OSS SDK
+func ossCredentialsProvider(creds *types.Credentials) credentials.CredentialsProvider {
+	return credentials.CredentialsProviderFunc(func(ctx context.Context) (credentials.Credentials, error) {
+		cc, err := creds.Provider.GetCredentials()
+		if err != nil {
+			return credentials.Credentials{}, err
+		}
+		return credentials.Credentials{
+			AccessKeyID:     cc.AccessKeyId,
+			AccessKeySecret: cc.AccessKeySecret,
+			SecurityToken:   cc.SecurityToken,
+		}, nil
+	})
+}
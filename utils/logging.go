@@ -0,0 +1,132 @@
+package utils
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"log/slog"
+	"os"
+	"sync/atomic"
+
+	"github.com/arafato/ali-nuke/types"
+)
+
+// Log formats
+const (
+	LogFormatText = "text"
+	LogFormatJSON = "json"
+)
+
+// Attribute keys shared by all log records
+const (
+	LogKeyAccount    = "account"
+	LogKeyRegion     = "region"
+	LogKeyProduct    = "product"
+	LogKeyResourceID = "resource_id"
+	LogKeyRequestID  = "request_id"
+	LogKeyErrorCode  = "error_code"
+	LogKeyError      = "error"
+)
+
+// LogOptions configures the structured logging
+type LogOptions struct {
+	Level  string // debug, info, warn or error
+	Format string // text or json
+	File   string // Log file; stderr if empty
+}
+
+// problemCount counts the warnings and errors logged since SetupLogging
+var problemCount atomic.Int64
+
+// SetupLogging installs the default slog logger. The returned function closes the log file.
+func SetupLogging(opts LogOptions) (func() error, error) {
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(opts.Level)); err != nil {
+		return nil, fmt.Errorf("invalid log level %q, use debug, info, warn or error", opts.Level)
+	}
+
+	var w io.Writer = os.Stderr
+	closeFn := func() error { return nil }
+	if opts.File != "" {
+		file, err := os.OpenFile(opts.File, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open log file: %w", err)
+		}
+		w = file
+		closeFn = file.Close
+	}
+
+	handlerOpts := &slog.HandlerOptions{Level: level}
+	var handler slog.Handler
+	switch opts.Format {
+	case LogFormatText:
+		handler = slog.NewTextHandler(w, handlerOpts)
+	case LogFormatJSON:
+		handler = slog.NewJSONHandler(w, handlerOpts)
+	default:
+		return nil, fmt.Errorf("invalid log format %q, use text or json", opts.Format)
+	}
+
+	slog.SetDefault(slog.New(countingHandler{handler}))
+	// SetDefault routes the log package through the handler at info level, which would hide
+	// messages of libraries using it below the default warn level
+	log.SetOutput(os.Stderr)
+	return closeFn, nil
+}
+
+// LoggedProblems returns the number of warnings and errors logged so far
+func LoggedProblems() int64 {
+	return problemCount.Load()
+}
+
+// countingHandler counts the warnings and errors passed to the wrapped handler
+type countingHandler struct {
+	slog.Handler
+}
+
+func (h countingHandler) Handle(ctx context.Context, record slog.Record) error {
+	if record.Level >= slog.LevelWarn {
+		problemCount.Add(1)
+	}
+	return h.Handler.Handle(ctx, record)
+}
+
+func (h countingHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return countingHandler{h.Handler.WithAttrs(attrs)}
+}
+
+func (h countingHandler) WithGroup(name string) slog.Handler {
+	return countingHandler{h.Handler.WithGroup(name)}
+}
+
+// ResourceLogAttrs returns the log attributes identifying a resource
+func ResourceLogAttrs(r *types.Resource) []any {
+	return []any{
+		LogKeyAccount, r.AccountID,
+		LogKeyRegion, r.Region,
+		LogKeyProduct, r.ProductName,
+		LogKeyResourceID, r.ResourceID,
+	}
+}
+
+// ErrorLogAttrs returns the log attributes of an error, including the error code and
+// request ID of API errors
+func ErrorLogAttrs(err error) []any {
+	attrs := []any{LogKeyError, err.Error()}
+	if apiErr, ok := types.AsAPIError(err); ok {
+		attrs = append(attrs, LogKeyErrorCode, apiErr.Code, LogKeyRequestID, apiErr.RequestID)
+	}
+	return attrs
+}
+
+// ResourceErrorLogAttrs returns the log attributes of the last error of a resource
+func ResourceErrorLogAttrs(err *types.ResourceError) []any {
+	return []any{
+		LogKeyError, err.Message,
+		LogKeyErrorCode, err.Code,
+		LogKeyRequestID, err.RequestID,
+		"attempts", err.Attempts,
+		"wave", err.Wave,
+	}
+}
@@ -0,0 +1,120 @@
+package utils
+
+import (
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/arafato/ali-nuke/types"
+)
+
+// metricsRegistry holds the metrics of a run. Metrics are always recorded, they are only
+// exported if a metrics endpoint, pushgateway or textfile is configured.
+var metricsRegistry = prometheus.NewRegistry()
+
+var (
+	collectorDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "ali_nuke_collector_duration_seconds",
+		Help:    "Duration of a collector call in a region, including retries.",
+		Buckets: prometheus.ExponentialBuckets(0.1, 2, 10),
+	}, []string{LogKeyProduct, LogKeyRegion})
+
+	apiCalls = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "ali_nuke_api_calls_total",
+		Help: "HTTP requests sent to Alibaba Cloud APIs by service and status code.",
+	}, []string{"service", "status"})
+
+	apiErrors = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "ali_nuke_api_errors_total",
+		Help: "Errors returned while collecting or deleting resources by product and error code.",
+	}, []string{LogKeyProduct, "code"})
+
+	resourceCount = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "ali_nuke_resources",
+		Help: "Resources found by product, region and state.",
+	}, []string{LogKeyProduct, LogKeyRegion, "state"})
+
+	deletionWaves = prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "ali_nuke_deletion_waves_total",
+		Help: "Deletion waves run.",
+	})
+)
+
+func init() {
+	metricsRegistry.MustRegister(collectorDuration, apiCalls, apiErrors, resourceCount, deletionWaves)
+	sharedHTTPClient.Transport = meteredTransport{sharedHTTPClient.Transport}
+}
+
+// ObserveCollector records the duration of a collector call
+func ObserveCollector(product, region string, d time.Duration) {
+	collectorDuration.WithLabelValues(product, region).Observe(d.Seconds())
+}
+
+// RecordAPIError counts an error returned for a resource type. Errors without an API error
+// code are counted by their category.
+func RecordAPIError(product string, err error) {
+	code := types.ClassifyError(product, err).String()
+	if apiErr, ok := types.AsAPIError(err); ok && apiErr.Code != "" {
+		code = apiErr.Code
+	}
+	RecordErrorCode(product, code)
+}
+
+// RecordErrorCode counts an error code returned for a resource type
+func RecordErrorCode(product, code string) {
+	apiErrors.WithLabelValues(product, code).Inc()
+}
+
+// RecordWave counts a deletion wave
+func RecordWave() {
+	deletionWaves.Inc()
+}
+
+// RecordResources sets the resource gauge to the current state of the resources.
+// Hidden resources are not counted.
+func RecordResources(resources types.Resources) {
+	resourceCount.Reset()
+	for _, r := range resources {
+		if r.State() == types.Hidden {
+			continue
+		}
+		resourceCount.WithLabelValues(r.ProductName, r.Region, strings.ToLower(r.State().String())).Inc()
+	}
+}
+
+// meteredTransport counts the requests sent through the shared HTTP client
+type meteredTransport struct {
+	base http.RoundTripper
+}
+
+func (t meteredTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := t.base.RoundTrip(req)
+	status := "error"
+	if err == nil {
+		status = strconv.Itoa(resp.StatusCode)
+	}
+	apiCalls.WithLabelValues(serviceOf(req.URL.Host), status).Inc()
+	return resp, err
+}
+
+// serviceOf derives the service from an endpoint host: "ecs" for "ecs.cn-hangzhou.aliyuncs.com"
+// and "oss" for virtual-hosted bucket endpoints like "bucket.oss-cn-hangzhou.aliyuncs.com"
+func serviceOf(host string) string {
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	if net.ParseIP(host) != nil {
+		return host
+	}
+	labels := strings.Split(host, ".")
+	for _, label := range labels {
+		if strings.HasPrefix(label, "oss-") {
+			return "oss"
+		}
+	}
+	return labels[0]
+}
@@ -0,0 +1,137 @@
+package utils
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"slices"
+	"time"
+
+	"github.com/arafato/ali-nuke/types"
+)
+
+// Plan is a reviewed set of resources to delete, written by nuke --plan-out and executed by apply
+type Plan struct {
+	Version    string         `json:"version"` // ali-nuke version that created the plan
+	CreatedAt  time.Time      `json:"createdAt"`
+	ConfigFile string         `json:"configFile,omitempty"` // Path of the configuration file
+	Config     string         `json:"config"`               // Content of the configuration file
+	Resources  []PlanResource `json:"resources"`
+	Hash       string         `json:"hash"` // SHA-256 of the plan without the hash
+}
+
+// PlanResource identifies a resource of a plan
+type PlanResource struct {
+	Account string `json:"account,omitempty"`
+	Region  string `json:"region"`
+	Product string `json:"product"`
+	ID      string `json:"id"`
+	Name    string `json:"name"`
+}
+
+// NewPlan creates a plan of the resources in state Ready
+func NewPlan(resources types.Resources, version, configFile string, configData []byte) *Plan {
+	plan := &Plan{
+		Version:    version,
+		CreatedAt:  time.Now().UTC().Truncate(time.Second),
+		ConfigFile: configFile,
+		Config:     string(configData),
+		Resources:  []PlanResource{},
+	}
+	for _, resource := range resources {
+		if resource.State() != types.Ready {
+			continue
+		}
+		plan.Resources = append(plan.Resources, planResourceOf(resource))
+	}
+	plan.Hash = plan.computeHash()
+	return plan
+}
+
+func planResourceOf(resource *types.Resource) PlanResource {
+	return PlanResource{
+		Account: resource.AccountID,
+		Region:  resource.Region,
+		Product: resource.ProductName,
+		ID:      resource.ResourceID,
+		Name:    resource.ResourceName,
+	}
+}
+
+// computeHash returns the SHA-256 of the plan with an empty hash field
+func (p *Plan) computeHash() string {
+	unhashed := *p
+	unhashed.Hash = ""
+	data, _ := json.Marshal(unhashed)
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// Write stores the plan as JSON
+func (p *Plan) Write(path string) error {
+	data, err := json.MarshalIndent(p, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0o600)
+}
+
+// LoadPlan reads a plan and verifies that it has not been modified since it was written
+func LoadPlan(path string) (*Plan, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading plan: %w", err)
+	}
+
+	var plan Plan
+	if err := json.Unmarshal(data, &plan); err != nil {
+		return nil, fmt.Errorf("error parsing plan: %w", err)
+	}
+	if plan.Hash == "" || plan.Hash != plan.computeHash() {
+		return nil, fmt.Errorf("plan %s has been modified since it was created (hash mismatch)", path)
+	}
+	return &plan, nil
+}
+
+// Contains reports whether the resource is part of the plan
+func (p *Plan) Contains(resource *types.Resource) bool {
+	key := planResourceOf(resource)
+	return slices.ContainsFunc(p.Resources, func(r PlanResource) bool {
+		return r.Account == key.Account && r.Region == key.Region && r.Product == key.Product && r.ID == key.ID
+	})
+}
+
+// Accounts returns the distinct accounts of the planned resources
+func (p *Plan) Accounts() []string {
+	var accounts []string
+	for _, r := range p.Resources {
+		if !slices.Contains(accounts, r.Account) {
+			accounts = append(accounts, r.Account)
+		}
+	}
+	return accounts
+}
+
+// ResourceTypes returns the distinct resource types planned in an account
+func (p *Plan) ResourceTypes(account string) []string {
+	var resourceTypes []string
+	for _, r := range p.Resources {
+		if r.Account == account && !slices.Contains(resourceTypes, r.Product) {
+			resourceTypes = append(resourceTypes, r.Product)
+		}
+	}
+	return resourceTypes
+}
+
+// Regions returns the distinct regions planned in an account
+func (p *Plan) Regions(account string) []string {
+	var regions []string
+	for _, r := range p.Resources {
+		if r.Account == account && !slices.Contains(regions, r.Region) {
+			regions = append(regions, r.Region)
+		}
+	}
+	return regions
+}
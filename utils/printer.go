@@ -3,7 +3,7 @@ package utils
 import (
 	"context"
 	"fmt"
-	"os"
+	"io"
 	"sync"
 	"time"
 
@@ -20,7 +20,8 @@ var (
 	colorYellow = color.New(color.FgYellow).SprintFunc()
 )
 
-func PrintStatusWithContext(wg *sync.WaitGroup, ctx context.Context, resources types.Resources) {
+// PrintStatusWithContext writes the status of the resources to w every few seconds until ctx is done
+func PrintStatusWithContext(wg *sync.WaitGroup, ctx context.Context, w io.Writer, resources types.Resources) {
 	defer wg.Done()
 	ticker := time.NewTicker(5 * time.Second)
 	defer ticker.Stop()
@@ -28,53 +29,109 @@ func PrintStatusWithContext(wg *sync.WaitGroup, ctx context.Context, resources t
 	for {
 		select {
 		case <-ticker.C:
-			PrettyPrintStatus(resources)
+			PrettyPrintStatus(w, resources)
 		case <-ctx.Done():
-			PrettyPrintStatus(resources)
+			PrettyPrintStatus(w, resources)
 			return
 		}
 	}
 }
 
 // colorizeStatus returns a colored status string based on the resource state
-func colorizeStatus(state types.ResourceState) string {
+func colorizeStatus(state types.ResourceState, colored bool) string {
+	if !colored {
+		return statusLabel(state)
+	}
 	switch state {
 	case types.Deleted:
-		return colorGreen("Removed")
+		return colorGreen(statusLabel(state))
 	case types.Filtered:
-		return colorBlue("Filtered")
-	case types.Removing, types.PendingRetry:
-		return colorYellow("In-Progress")
+		return colorBlue(statusLabel(state))
+	case types.Removing, types.PendingRetry, types.Verifying:
+		return colorYellow(statusLabel(state))
 	case types.Failed:
-		return colorRed("Failed")
+		return colorRed(statusLabel(state))
+	default:
+		return statusLabel(state)
+	}
+}
+
+// statusLabel returns the label of a resource state shown in tables
+func statusLabel(state types.ResourceState) string {
+	switch state {
+	case types.Deleted:
+		return "Removed"
+	case types.Removing, types.PendingRetry:
+		return "In-Progress"
 	default:
 		return state.String()
 	}
 }
 
-func PrettyPrintStatus(resources types.Resources) {
-	data := [][]string{{"Region", "Product", "ID/Name", "Status"}}
+// PrettyPrintStatus writes the resources table and the status line to w
+func PrettyPrintStatus(w io.Writer, resources types.Resources) {
+	RenderTable(w, resources, true)
+	fmt.Fprintln(w, "\n"+statusLine(resources, true))
+}
+
+// RenderTable writes the visible resources as a table
+func RenderTable(w io.Writer, resources types.Resources, colored bool) {
+	// The account column is only shown when resources of several accounts are listed
+	withAccount := len(resources.Accounts()) > 1
+
+	header := []string{"Region", "Product", "ID/Name", "Status", "Reason"}
+	if withAccount {
+		header = append([]string{"Account"}, header...)
+	}
+	data := [][]string{header}
 	for _, resource := range resources {
 		if resource.State() == types.Hidden {
 			continue
 		}
 
-		status := colorizeStatus(resource.State())
-		data = append(data, []string{resource.Region, resource.ProductName, resource.ResourceName, status})
+		status := colorizeStatus(resource.State(), colored)
+		row := []string{resource.Region, resource.ProductName, resource.ResourceName, status, reasonOf(resource)}
+		if withAccount {
+			row = append([]string{resource.AccountID}, row...)
+		}
+		data = append(data, row)
 	}
 
-	table := tablewriter.NewWriter(os.Stdout)
+	table := tablewriter.NewWriter(w)
 	table.Header(data[0])
 	table.Bulk(data[1:])
 	table.Render()
+}
+
+// maxReasonWidth is the number of characters of an error shown in the reason column
+const maxReasonWidth = 60
 
-	visibleCount := resources.VisibleCount()
+// reasonOf returns the filter reason of a resource or, if its deletion failed or is retried,
+// the last error truncated to fit the table
+func reasonOf(resource *types.Resource) string {
+	state := resource.State()
+	if err := resource.LastError(); err != nil && (state == types.Failed || state == types.PendingRetry) {
+		reason := []rune(err.Summary())
+		if len(reason) > maxReasonWidth {
+			return string(reason[:maxReasonWidth-3]) + "..."
+		}
+		return string(reason)
+	}
+	return resource.FilterReason
+}
+
+// statusLine returns the summary counts of the visible resources
+func statusLine(resources types.Resources, colored bool) string {
+	label := func(state types.ResourceState) string {
+		return colorizeStatus(state, colored)
+	}
 	// Count PendingRetry as "In-Progress" for display
 	inProgress := resources.NumOf(types.Removing) + resources.NumOf(types.PendingRetry)
-	fmt.Printf("\nStatus: %d resources in total. %s %d, %s %d, %s %d, %s %d\n",
-		visibleCount,
-		colorGreen("Removed"), resources.NumOf(types.Deleted),
-		colorYellow("In-Progress"), inProgress,
-		colorBlue("Filtered"), resources.NumOf(types.Filtered),
-		colorRed("Failed"), resources.NumOf(types.Failed))
+	return fmt.Sprintf("Status: %d resources in total. %s %d, %s %d, %s %d, %s %d, %s %d",
+		resources.VisibleCount(),
+		label(types.Deleted), resources.NumOf(types.Deleted),
+		label(types.Removing), inProgress,
+		label(types.Verifying), resources.NumOf(types.Verifying),
+		label(types.Filtered), resources.NumOf(types.Filtered),
+		label(types.Failed), resources.NumOf(types.Failed))
 }
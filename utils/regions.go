@@ -1,9 +1,10 @@
 package utils
 
 import (
+	"context"
 	"fmt"
+	"slices"
 
-	openapi "github.com/alibabacloud-go/darabonba-openapi/v2/client"
 	ecs "github.com/alibabacloud-go/ecs-20140526/v7/client"
 	"github.com/alibabacloud-go/tea/tea"
 
@@ -11,16 +12,10 @@ import (
 )
 
 // FetchAllRegions retrieves all available Alibaba Cloud regions using the ECS DescribeRegions API.
-// We use cn-hangzhou as the bootstrap region to query the list of all available regions.
-func FetchAllRegions(creds *types.Credentials) ([]string, error) {
-	// Create a client pointing to a known region to fetch the region list
-	config := &openapi.Config{
-		AccessKeyId:     tea.String(creds.AccessKeyID),
-		AccessKeySecret: tea.String(creds.AccessKeySecret),
-		RegionId:        tea.String("cn-hangzhou"), // Bootstrap region
-	}
-
-	client, err := ecs.NewClient(config)
+// The list is queried from the endpoint of the bootstrap region, usually the global region of
+// the settings.
+func FetchAllRegions(ctx context.Context, creds *types.Credentials, bootstrapRegion string) ([]string, error) {
+	client, err := GetECSClient(ctx, creds, bootstrapRegion)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create ECS client for region discovery: %w", err)
 	}
@@ -50,13 +45,25 @@ func FetchAllRegions(creds *types.Credentials) ([]string, error) {
 	return regions, nil
 }
 
-// GetActiveRegions fetches all available regions and removes the excluded ones
-func GetActiveRegions(creds *types.Credentials, excludes []string) ([]string, error) {
-	allRegions, err := FetchAllRegions(creds)
+// GetActiveRegions fetches all available regions from the bootstrap region, keeps the included
+// ones (all if includes is empty) and then removes the excluded ones
+func GetActiveRegions(ctx context.Context, creds *types.Credentials, bootstrapRegion string, includes []string, excludes []string) ([]string, error) {
+	allRegions, err := FetchAllRegions(ctx, creds, bootstrapRegion)
 	if err != nil {
 		return nil, err
 	}
 
+	if len(includes) > 0 {
+		for _, include := range includes {
+			if !slices.Contains(allRegions, include) {
+				return nil, fmt.Errorf("included region %s is not available", include)
+			}
+		}
+		allRegions = slices.DeleteFunc(allRegions, func(region string) bool {
+			return !slices.Contains(includes, region)
+		})
+	}
+
 	excludeSet := make(map[string]struct{})
 	for _, e := range excludes {
 		excludeSet[e] = struct{}{}
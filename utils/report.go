@@ -0,0 +1,166 @@
+package utils
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/mattn/go-isatty"
+	"gopkg.in/yaml.v2"
+
+	"github.com/arafato/ali-nuke/types"
+)
+
+// Output formats of the resource report
+const (
+	OutputTable = "table"
+	OutputJSON  = "json"
+	OutputYAML  = "yaml"
+	OutputCSV   = "csv"
+)
+
+// OutputFormats lists the supported output formats
+var OutputFormats = []string{OutputTable, OutputJSON, OutputYAML, OutputCSV}
+
+// ResourceRecord is the machine-readable representation of a resource
+type ResourceRecord struct {
+	Account      string               `json:"account,omitempty" yaml:"account,omitempty"`
+	Region       string               `json:"region" yaml:"region"`
+	Product      string               `json:"product" yaml:"product"`
+	ID           string               `json:"id" yaml:"id"`
+	Name         string               `json:"name" yaml:"name"`
+	State        string               `json:"state" yaml:"state"`
+	FilterReason string               `json:"filterReason,omitempty" yaml:"filterReason,omitempty"`
+	Properties   map[string]string    `json:"properties,omitempty" yaml:"properties,omitempty"`
+	Tags         map[string]string    `json:"tags,omitempty" yaml:"tags,omitempty"`
+	Error        *types.ResourceError `json:"error,omitempty" yaml:"error,omitempty"`
+}
+
+// ReportSummary holds the number of visible resources per state
+type ReportSummary struct {
+	Total        int `json:"total" yaml:"total"`
+	Ready        int `json:"ready" yaml:"ready"`
+	Filtered     int `json:"filtered" yaml:"filtered"`
+	Removing     int `json:"removing" yaml:"removing"`
+	PendingRetry int `json:"pendingRetry" yaml:"pendingRetry"`
+	Verifying    int `json:"verifying" yaml:"verifying"`
+	Deleted      int `json:"deleted" yaml:"deleted"`
+	Failed       int `json:"failed" yaml:"failed"`
+}
+
+// Report is the machine-readable result of a scan or a deletion
+type Report struct {
+	Resources []ResourceRecord `json:"resources" yaml:"resources"`
+	Summary   ReportSummary    `json:"summary" yaml:"summary"`
+}
+
+// NewReport builds the report of the visible resources
+func NewReport(resources types.Resources) Report {
+	report := Report{
+		Resources: []ResourceRecord{},
+		Summary: ReportSummary{
+			Total:        resources.VisibleCount(),
+			Ready:        resources.NumOf(types.Ready),
+			Filtered:     resources.NumOf(types.Filtered),
+			Removing:     resources.NumOf(types.Removing),
+			PendingRetry: resources.NumOf(types.PendingRetry),
+			Verifying:    resources.NumOf(types.Verifying),
+			Deleted:      resources.NumOf(types.Deleted),
+			Failed:       resources.NumOf(types.Failed),
+		},
+	}
+	for _, resource := range resources {
+		if resource.State() == types.Hidden {
+			continue
+		}
+		report.Resources = append(report.Resources, ResourceRecord{
+			Account:      resource.AccountID,
+			Region:       resource.Region,
+			Product:      resource.ProductName,
+			ID:           resource.ResourceID,
+			Name:         resource.ResourceName,
+			State:        resource.State().String(),
+			FilterReason: resource.FilterReason,
+			Properties:   resource.Properties,
+			Tags:         resource.Tags,
+			Error:        resource.LastError(),
+		})
+	}
+	return report
+}
+
+// WriteReport writes the visible resources and the summary counts in the given format.
+// The table format is rendered without colours. CSV has no room for the summary, it
+// contains one row per resource with properties and tags as JSON objects.
+func WriteReport(w io.Writer, format string, resources types.Resources) error {
+	switch format {
+	case OutputTable:
+		RenderTable(w, resources, false)
+		_, err := fmt.Fprintln(w, statusLine(resources, false))
+		return err
+	case OutputJSON:
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(NewReport(resources))
+	case OutputYAML:
+		data, err := yaml.Marshal(NewReport(resources))
+		if err != nil {
+			return err
+		}
+		_, err = w.Write(data)
+		return err
+	case OutputCSV:
+		return writeCSV(w, NewReport(resources).Resources)
+	default:
+		return fmt.Errorf("unknown output format %q, use one of %s", format, strings.Join(OutputFormats, ", "))
+	}
+}
+
+// writeCSV writes one row per resource
+func writeCSV(w io.Writer, records []ResourceRecord) error {
+	writer := csv.NewWriter(w)
+	writer.Write([]string{"account", "region", "product", "id", "name", "state", "filter_reason", "properties", "tags", "error"})
+	for _, record := range records {
+		properties, err := jsonCell(record.Properties)
+		if err != nil {
+			return err
+		}
+		tags, err := jsonCell(record.Tags)
+		if err != nil {
+			return err
+		}
+		errorDetails := ""
+		if record.Error != nil {
+			errorDetails = record.Error.String()
+		}
+		writer.Write([]string{
+			record.Account, record.Region, record.Product, record.ID, record.Name,
+			record.State, record.FilterReason, properties, tags, errorDetails,
+		})
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+// jsonCell encodes a map as JSON object, or returns an empty string for an empty map
+func jsonCell(values map[string]string) (string, error) {
+	if len(values) == 0 {
+		return "", nil
+	}
+	data, err := json.Marshal(values)
+	return string(data), err
+}
+
+// ValidOutputFormat reports whether format is supported
+func ValidOutputFormat(format string) bool {
+	return slices.Contains(OutputFormats, format)
+}
+
+// IsTerminal reports whether f is connected to a terminal
+func IsTerminal(f *os.File) bool {
+	return isatty.IsTerminal(f.Fd()) || isatty.IsCygwinTerminal(f.Fd())
+}
@@ -0,0 +1,111 @@
+package utils
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/prometheus/client_golang/prometheus/push"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	sdkresource "go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// tracerName identifies the spans of ali-nuke
+const tracerName = "github.com/arafato/ali-nuke"
+
+// pushJobName is the job label of metrics pushed to a pushgateway
+const pushJobName = "ali-nuke"
+
+// TelemetryOptions selects the metrics and tracing exports. All exports are off by default.
+type TelemetryOptions struct {
+	MetricsListen   string // Address serving /metrics while the run lasts, e.g. ":9090"
+	MetricsPushURL  string // Pushgateway the metrics are pushed to at the end of the run
+	MetricsTextfile string // File the metrics are written to at the end of the run
+	TraceEndpoint   string // OTLP/HTTP endpoint receiving the spans, e.g. "http://localhost:4318"
+	Version         string // Version of ali-nuke reported with the spans
+}
+
+// Telemetry holds the exports started by StartTelemetry
+type Telemetry struct {
+	opts           TelemetryOptions
+	server         *http.Server
+	tracerProvider *sdktrace.TracerProvider
+}
+
+// Tracer returns the tracer of ali-nuke. Spans are dropped unless tracing was started.
+func Tracer() trace.Tracer {
+	return otel.Tracer(tracerName)
+}
+
+// StartTelemetry starts the metrics endpoint and the trace exporter, if configured
+func StartTelemetry(ctx context.Context, opts TelemetryOptions) (*Telemetry, error) {
+	t := &Telemetry{opts: opts}
+
+	if opts.MetricsListen != "" {
+		listener, err := net.Listen("tcp", opts.MetricsListen)
+		if err != nil {
+			return nil, fmt.Errorf("failed to start metrics endpoint: %w", err)
+		}
+		mux := http.NewServeMux()
+		mux.Handle("/metrics", promhttp.HandlerFor(metricsRegistry, promhttp.HandlerOpts{}))
+		t.server = &http.Server{Handler: mux}
+		go func() {
+			if err := t.server.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				slog.Error("metrics endpoint failed", ErrorLogAttrs(err)...)
+			}
+		}()
+	}
+
+	if opts.TraceEndpoint != "" {
+		exporter, err := otlptracehttp.New(ctx, otlptracehttp.WithEndpointURL(opts.TraceEndpoint))
+		if err != nil {
+			return nil, fmt.Errorf("failed to create trace exporter: %w", err)
+		}
+		t.tracerProvider = sdktrace.NewTracerProvider(
+			sdktrace.WithBatcher(exporter),
+			sdktrace.WithResource(sdkresource.NewSchemaless(
+				attribute.String("service.name", "ali-nuke"),
+				attribute.String("service.version", opts.Version),
+			)),
+		)
+		otel.SetTracerProvider(t.tracerProvider)
+	}
+
+	return t, nil
+}
+
+// Shutdown pushes or writes the metrics, flushes the pending spans and stops the metrics
+// endpoint. All exports are attempted, the errors are joined.
+func (t *Telemetry) Shutdown(ctx context.Context) error {
+	var errs []error
+	if t.opts.MetricsPushURL != "" {
+		if err := push.New(t.opts.MetricsPushURL, pushJobName).Gatherer(metricsRegistry).Push(); err != nil {
+			errs = append(errs, fmt.Errorf("failed to push metrics: %w", err))
+		}
+	}
+	if t.opts.MetricsTextfile != "" {
+		if err := prometheus.WriteToTextfile(t.opts.MetricsTextfile, metricsRegistry); err != nil {
+			errs = append(errs, fmt.Errorf("failed to write metrics: %w", err))
+		}
+	}
+	if t.tracerProvider != nil {
+		if err := t.tracerProvider.Shutdown(ctx); err != nil {
+			errs = append(errs, fmt.Errorf("failed to export spans: %w", err))
+		}
+	}
+	if t.server != nil {
+		if err := t.server.Shutdown(ctx); err != nil {
+			errs = append(errs, fmt.Errorf("failed to stop metrics endpoint: %w", err))
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,211 @@
+package utils
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	dto "github.com/prometheus/client_model/go"
+	"github.com/prometheus/common/expfmt"
+	"github.com/prometheus/common/model"
+	"go.opentelemetry.io/otel/attribute"
+	coltracepb "go.opentelemetry.io/proto/otlp/collector/trace/v1"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/arafato/ali-nuke/types"
+)
+
+// recordRun records the metrics of a small run
+func recordRun() {
+	ObserveCollector("ECSInstance", "cn-hangzhou", 2*time.Second)
+	RecordErrorCode("ECSInstance", "Throttling")
+	RecordWave()
+
+	deleted := &types.Resource{ProductName: "ECSInstance", Region: "cn-hangzhou", ResourceID: "i-1"}
+	deleted.SetState(types.Deleted)
+	filtered := &types.Resource{ProductName: "Disk", Region: "cn-shanghai", ResourceID: "d-1"}
+	filtered.SetState(types.Filtered)
+	RecordResources(types.Resources{deleted, filtered})
+}
+
+// findMetric returns the metric of the family with the given labels, or nil
+func findMetric(families map[string]*dto.MetricFamily, name string, labels map[string]string) *dto.Metric {
+	family, ok := families[name]
+	if !ok {
+		return nil
+	}
+	for _, m := range family.GetMetric() {
+		matched := 0
+		for _, l := range m.GetLabel() {
+			if value, ok := labels[l.GetName()]; ok && value == l.GetValue() {
+				matched++
+			}
+		}
+		if matched == len(labels) {
+			return m
+		}
+	}
+	return nil
+}
+
+// checkRunMetrics checks that the metrics recorded by recordRun are part of families
+func checkRunMetrics(t *testing.T, families map[string]*dto.MetricFamily) {
+	t.Helper()
+	if m := findMetric(families, "ali_nuke_collector_duration_seconds", map[string]string{LogKeyProduct: "ECSInstance", LogKeyRegion: "cn-hangzhou"}); m == nil || m.GetHistogram().GetSampleCount() == 0 {
+		t.Errorf("collector duration of ECSInstance in cn-hangzhou missing: %v", m)
+	}
+	if m := findMetric(families, "ali_nuke_api_errors_total", map[string]string{LogKeyProduct: "ECSInstance", "code": "Throttling"}); m == nil || m.GetCounter().GetValue() < 1 {
+		t.Errorf("Throttling errors of ECSInstance missing: %v", m)
+	}
+	if m := findMetric(families, "ali_nuke_deletion_waves_total", nil); m == nil || m.GetCounter().GetValue() < 1 {
+		t.Errorf("deletion waves missing: %v", m)
+	}
+	if m := findMetric(families, "ali_nuke_resources", map[string]string{LogKeyProduct: "ECSInstance", LogKeyRegion: "cn-hangzhou", "state": "deleted"}); m == nil || m.GetGauge().GetValue() != 1 {
+		t.Errorf("deleted ECSInstance gauge = %v, want 1", m)
+	}
+	if m := findMetric(families, "ali_nuke_resources", map[string]string{LogKeyProduct: "Disk", LogKeyRegion: "cn-shanghai", "state": "filtered"}); m == nil || m.GetGauge().GetValue() != 1 {
+		t.Errorf("filtered Disk gauge = %v, want 1", m)
+	}
+}
+
+func TestTelemetryTextfile(t *testing.T) {
+	recordRun()
+
+	path := filepath.Join(t.TempDir(), "ali-nuke.prom")
+	telemetry, err := StartTelemetry(context.Background(), TelemetryOptions{MetricsTextfile: path})
+	if err != nil {
+		t.Fatalf("StartTelemetry: %v", err)
+	}
+	if err := telemetry.Shutdown(context.Background()); err != nil {
+		t.Fatalf("Shutdown: %v", err)
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("metrics textfile not written: %v", err)
+	}
+	defer f.Close()
+	parser := expfmt.NewTextParser(model.UTF8Validation)
+	families, err := parser.TextToMetricFamilies(f)
+	if err != nil {
+		t.Fatalf("metrics textfile is not in the text format: %v", err)
+	}
+	checkRunMetrics(t, families)
+}
+
+func TestTelemetryPushgateway(t *testing.T) {
+	recordRun()
+
+	var (
+		mu       sync.Mutex
+		requests []string
+		families = map[string]*dto.MetricFamily{}
+	)
+	gateway := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		requests = append(requests, r.Method+" "+r.URL.Path)
+		decoder := expfmt.NewDecoder(r.Body, expfmt.ResponseFormat(r.Header))
+		for {
+			var family dto.MetricFamily
+			if err := decoder.Decode(&family); err != nil {
+				if err != io.EOF {
+					t.Errorf("decoding pushed metrics: %v", err)
+				}
+				break
+			}
+			families[family.GetName()] = &family
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer gateway.Close()
+
+	telemetry, err := StartTelemetry(context.Background(), TelemetryOptions{MetricsPushURL: gateway.URL})
+	if err != nil {
+		t.Fatalf("StartTelemetry: %v", err)
+	}
+	if err := telemetry.Shutdown(context.Background()); err != nil {
+		t.Fatalf("Shutdown: %v", err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if want := "PUT /metrics/job/" + pushJobName; len(requests) != 1 || requests[0] != want {
+		t.Fatalf("pushgateway requests = %v, want [%s]", requests, want)
+	}
+	checkRunMetrics(t, families)
+}
+
+func TestTelemetryTraces(t *testing.T) {
+	var (
+		mu    sync.Mutex
+		paths []string
+		spans = map[string]map[string]string{}
+		attrs = map[string]string{}
+	)
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			t.Errorf("reading spans: %v", err)
+		}
+		var request coltracepb.ExportTraceServiceRequest
+		if err := proto.Unmarshal(body, &request); err != nil {
+			t.Errorf("decoding spans: %v", err)
+		}
+
+		mu.Lock()
+		defer mu.Unlock()
+		paths = append(paths, r.URL.Path)
+		for _, rs := range request.GetResourceSpans() {
+			for _, kv := range rs.GetResource().GetAttributes() {
+				attrs[kv.GetKey()] = kv.GetValue().GetStringValue()
+			}
+			for _, ss := range rs.GetScopeSpans() {
+				for _, span := range ss.GetSpans() {
+					spanAttrs := map[string]string{}
+					for _, kv := range span.GetAttributes() {
+						spanAttrs[kv.GetKey()] = kv.GetValue().GetStringValue()
+					}
+					spans[span.GetName()] = spanAttrs
+				}
+			}
+		}
+		w.Header().Set("Content-Type", "application/x-protobuf")
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer receiver.Close()
+
+	telemetry, err := StartTelemetry(context.Background(), TelemetryOptions{TraceEndpoint: receiver.URL, Version: "v1.2.3"})
+	if err != nil {
+		t.Fatalf("StartTelemetry: %v", err)
+	}
+	_, span := Tracer().Start(context.Background(), "remove ECSInstance")
+	span.SetAttributes(attribute.String(LogKeyResourceID, "i-1"))
+	span.End()
+	if err := telemetry.Shutdown(context.Background()); err != nil {
+		t.Fatalf("Shutdown: %v", err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(paths) == 0 || !strings.HasSuffix(paths[0], "/v1/traces") {
+		t.Fatalf("span requests = %v, want /v1/traces", paths)
+	}
+	if attrs["service.name"] != "ali-nuke" || attrs["service.version"] != "v1.2.3" {
+		t.Errorf("resource attributes = %v, want service ali-nuke v1.2.3", attrs)
+	}
+	spanAttrs, ok := spans["remove ECSInstance"]
+	if !ok {
+		t.Fatalf("spans = %v, want remove ECSInstance", spans)
+	}
+	if spanAttrs[LogKeyResourceID] != "i-1" {
+		t.Errorf("span attributes = %v, want %s i-1", spanAttrs, LogKeyResourceID)
+	}
+}